@@ -227,7 +227,7 @@ document.addEventListener("DOMContentLoaded", function () {
 
       // Use the URL directly without parsing
       const uploadResponse = await fetch(uploadUrl, {
-        method: "POST",
+        method: uploadData.uploadMethod || "POST",
         body: file,
         headers: {
           "Content-Type": file.type || "text/csv",
@@ -317,7 +317,7 @@ document.addEventListener("DOMContentLoaded", function () {
   // Pagination state for details
   const detailState = {
     taskId: null,
-    type: null, // 'bank' or 'transaction'
+    type: null, // 'bank', 'transaction' or 'matched'
     limit: 10,
     offset: 0,
     totalCount: 0,
@@ -443,6 +443,9 @@ document.addEventListener("DOMContentLoaded", function () {
             <button class="btn btn-sm btn-outline-info action-btn view-bank-statements" data-task-id="${summary.taskId}">
               <i class="bi bi-bank"></i> Bank
             </button>
+            <button class="btn btn-sm btn-outline-success action-btn view-matched" data-task-id="${summary.taskId}">
+              <i class="bi bi-check2-all"></i> Matched
+            </button>
           </div>
         </td>
       `;
@@ -464,6 +467,13 @@ document.addEventListener("DOMContentLoaded", function () {
         viewDetails(taskId, "bank");
       });
     });
+
+    document.querySelectorAll(".view-matched").forEach((button) => {
+      button.addEventListener("click", function () {
+        const taskId = this.getAttribute("data-task-id");
+        viewDetails(taskId, "matched");
+      });
+    });
   }
 
   // Function to view transaction or bank statement details
@@ -476,6 +486,8 @@ document.addEventListener("DOMContentLoaded", function () {
     // Set modal title based on type
     if (type === "transaction") {
       detailsModalTitle.textContent = `Unmatched Transactions (Task ID: ${taskId})`;
+    } else if (type === "matched") {
+      detailsModalTitle.textContent = `Matched Pairs (Task ID: ${taskId})`;
     } else {
       detailsModalTitle.textContent = `Unmatched Bank Statements (Task ID: ${taskId})`;
     }
@@ -498,6 +510,8 @@ document.addEventListener("DOMContentLoaded", function () {
     let url;
     if (detailState.type === "transaction") {
       url = `/api/reconciliation/summary/${detailState.taskId}/transaction?limit=${detailState.limit}&offset=${detailState.offset}`;
+    } else if (detailState.type === "matched") {
+      url = `/api/reconciliation/summary/${detailState.taskId}/matched?limit=${detailState.limit}&offset=${detailState.offset}`;
     } else {
       url = `/api/reconciliation/summary/${detailState.taskId}/bank?limit=${detailState.limit}&offset=${detailState.offset}`;
     }
@@ -516,6 +530,8 @@ document.addEventListener("DOMContentLoaded", function () {
         // Update table based on type
         if (detailState.type === "transaction") {
           displayTransactions(items);
+        } else if (detailState.type === "matched") {
+          displayMatchedPairs(items);
         } else {
           displayBankStatements(items);
         }
@@ -545,7 +561,7 @@ document.addEventListener("DOMContentLoaded", function () {
         console.error(`Error fetching ${detailState.type} details:`, error);
         hideElement(detailsLoading);
         showElement(noDetails);
-        noDetails.textContent = `Error loading unmatched ${detailState.type} details. Please try again.`;
+        noDetails.textContent = `Error loading ${detailState.type} details. Please try again.`;
       });
   }
 
@@ -611,6 +627,42 @@ document.addEventListener("DOMContentLoaded", function () {
     });
   }
 
+  // Function to display matched pairs in the details table
+  function displayMatchedPairs(pairs) {
+    // Set up headers
+    detailsTableHead.innerHTML = `
+      <tr>
+        <th>Transaction ID</th>
+        <th>Transaction Amount</th>
+        <th>Transaction Time</th>
+        <th>Bank Statement ID</th>
+        <th>Bank Amount</th>
+        <th>Bank Date</th>
+        <th>Rule</th>
+      </tr>
+    `;
+
+    // Clear and populate table body
+    detailsTableBody.innerHTML = "";
+    pairs.forEach((pair) => {
+      const row = document.createElement("tr");
+      const txTime = new Date(pair.transactionTime).toLocaleString();
+      const bankDate = new Date(pair.bankDate).toLocaleDateString();
+
+      row.innerHTML = `
+        <td>${pair.transactionId}</td>
+        <td class="currency">$${pair.transactionAmount.toFixed(2)}</td>
+        <td class="date-format">${txTime}</td>
+        <td>${pair.bankStatementId}</td>
+        <td class="currency">$${pair.bankAmount.toFixed(2)}</td>
+        <td class="date-format">${bankDate}</td>
+        <td>${pair.rule}</td>
+      `;
+
+      detailsTableBody.appendChild(row);
+    });
+  }
+
   // Function to update pagination information and buttons
   function updatePaginationInfo(
     state,
@@ -3,13 +3,12 @@ package main
 import (
 	"context"
 	"log"
-	"os"
 	"os/signal"
 	"syscall"
-	"time"
 
 	"github.com/aferryc/yars/cmd/initialize"
 	"github.com/aferryc/yars/internal/config"
+	"github.com/aferryc/yars/repository/kafka"
 	"github.com/aferryc/yars/repository/postgres"
 	"github.com/aferryc/yars/transport"
 	"github.com/aferryc/yars/usecase"
@@ -26,36 +25,50 @@ func main() {
 	bankRepo := postgres.NewDBBankStatementRepository(pgConn)
 	transactionRepo := postgres.NewDBInternalTransactionRepository(pgConn)
 	reconRepo := postgres.NewDBReconResultRepository(pgConn)
+	fxRepo := postgres.NewDBFXRateRepository(pgConn)
+	taskRepo := postgres.NewDBReconTaskRepository(pgConn)
+	transactor := postgres.NewDBTransactor(pgConn)
+	outboxRepo := postgres.NewDBOutboxRepository(pgConn)
 
-	uc := usecase.NewReconciliationUsecase(transactionRepo, bankRepo, reconRepo)
-	consumer, err := transport.NewConsumer(&cfg.Kafka, cfg.Kafka.Topic.CompilerTopic, uc)
+	matchers, err := usecase.BuildMatcherChain(cfg.App.Reconciliation)
 	if err != nil {
-		log.Fatalf("Failed to create consumer: %v", err)
+		log.Fatalf("Failed to build matcher chain: %v", err)
 	}
-	defer consumer.Close()
 
-	// Setup signal handling for graceful shutdown
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
+	kafkaConn, err := initialize.NewKafkaProducer(cfg.Kafka.BrokerList, cfg.Kafka.ClientID)
+	if err != nil {
+		log.Fatalf("Failed to create Kafka producer: %v", err)
+	}
+	kafkaRepo := kafka.NewKafkaRepository(kafkaConn)
+	relay := usecase.NewOutboxRelay(cfg.App.Outbox, transactor, outboxRepo, kafkaRepo)
+
+	uc := usecase.NewReconciliationUsecase(cfg, matchers, transactionRepo, bankRepo, reconRepo, fxRepo, taskRepo, transactor, outboxRepo)
+	consumer, err := transport.NewConsumer(&cfg.Kafka, cfg.Kafka.Topic.ReconTopic, uc)
+	if err != nil {
+		log.Fatalf("Failed to create consumer: %v", err)
+	}
 
-	// Handle termination signals
-	sigChan := make(chan os.Signal, 1)
-	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP)
+	// Stop polling on a termination signal. Start returns once the record in
+	// flight is handled and committed, or its drain timeout passed.
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP)
+	defer stop()
 
-	// Start the consumer in a goroutine
+	// The relay publishes the events the use case stores in the outbox
+	relayDone := make(chan struct{})
 	go func() {
-		if err := consumer.Start(ctx); err != nil {
-			log.Printf("Consumer error: %v", err)
-			cancel() // Cancel context to signal shutdown
-		}
+		defer close(relayDone)
+		relay.Run(ctx)
 	}()
 
-	// Wait for termination signal
-	sig := <-sigChan
-	log.Printf("Received signal: %v, shutting down...", sig)
-	cancel() // Cancel context to signal shutdown
-
-	// Allow some time for cleanup
-	shutdownTimeout := 10 * time.Second
-	time.Sleep(shutdownTimeout)
+	// A record the consumer could not handle is fetched again rather than
+	// stopping it, so Start only fails when the client is closed
+	err = consumer.Start(ctx)
+	consumer.Close()
+	stop()
+	<-relayDone
+	kafkaRepo.Close()
+	if err != nil {
+		log.Fatalf("Consumer error: %v", err)
+	}
+	log.Println("Consumer stopped")
 }
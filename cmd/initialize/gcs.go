@@ -22,9 +22,13 @@ func ConnectGCS(cfg *config.Config) (*storage.Client, error) {
 	var client *storage.Client
 	var err error
 
-	clientOpts := []option.ClientOption{
-		option.WithEndpoint(cfg.Bucket.URL),
-		option.WithoutAuthentication(),
+	var clientOpts []option.ClientOption
+	if cfg.Bucket.GCS.EmulatorHost != "" {
+		// The emulator serves the JSON API without authentication
+		clientOpts = append(clientOpts,
+			option.WithEndpoint(cfg.Bucket.GCS.EmulatorHost+"/storage/v1/"),
+			option.WithoutAuthentication(),
+		)
 	}
 
 	for i := 0; i < maxGCSRetries; i++ {
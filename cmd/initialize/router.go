@@ -3,6 +3,7 @@ package initialize
 import (
 	"github.com/gin-gonic/gin"
 
+	"github.com/aferryc/yars/repository/local"
 	"github.com/aferryc/yars/transport"
 )
 
@@ -18,9 +19,28 @@ func SetupRouter(handler transport.Handler) *gin.Engine {
 	{
 		api.GET("/reconciliation/upload", handler.HandleReconManagerUpload)
 		api.POST("/reconciliation", handler.HandleReconManagerInitCompilation)
+		api.GET("/reconciliation/:task_id/status", handler.HandleGetTaskStatus)
 		api.GET("/reconciliation/summary/list", handler.HandleListReconSummary)
 		api.GET("/reconciliation/summary/:task_id/bank", handler.HandleListUnmatchedBank)
 		api.GET("/reconciliation/summary/:task_id/transaction", handler.HandleListUnmatchedTransactions)
+		api.GET("/reconciliation/summary/:task_id/matched", handler.HandleListMatchedPairs)
+		api.GET("/reconciliation/summary/:task_id/aggregate", handler.HandleListAggregateMatches)
+		api.POST("/fx-rates", handler.HandleImportFXRates)
+		api.GET("/fx-rates", handler.HandleGetFXRate)
+		api.GET("/ingestion-profiles", handler.HandleListIngestionProfiles)
+		api.PUT("/ingestion-profiles/:bank_name", handler.HandleSaveIngestionProfile)
+		api.GET("/ingestion-profiles/:bank_name", handler.HandleGetIngestionProfile)
+		api.DELETE("/ingestion-profiles/:bank_name", handler.HandleDeleteIngestionProfile)
 	}
 	return router
 }
+
+// RegisterStorageRoutes serves the signed URLs of the local storage backend
+func RegisterStorageRoutes(router *gin.Engine, handler *transport.StorageHandler) {
+	storage := router.Group(local.RoutePrefix)
+	{
+		storage.PUT("/*object", handler.HandleUpload)
+		storage.POST("/*object", handler.HandleUpload)
+		storage.GET("/*object", handler.HandleDownload)
+	}
+}
@@ -0,0 +1,42 @@
+package initialize
+
+import (
+	"crypto/rand"
+	"fmt"
+	"log"
+
+	"github.com/aferryc/yars/internal/config"
+	"github.com/aferryc/yars/repository"
+	"github.com/aferryc/yars/repository/gcs"
+	"github.com/aferryc/yars/repository/local"
+	"github.com/aferryc/yars/repository/s3"
+)
+
+const generatedSigningKeySize = 32
+
+// NewStorageRepository creates the storage backend selected by the config
+func NewStorageRepository(cfg *config.Config) (repository.StorageRepository, error) {
+	switch cfg.Bucket.Backend {
+	case config.StorageBackendGCS:
+		client, err := ConnectGCS(cfg)
+		if err != nil {
+			return nil, err
+		}
+		return gcs.NewGCSRepository(cfg.Bucket.Name, cfg.Bucket.GCS, client)
+	case config.StorageBackendS3:
+		return s3.NewS3Repository(cfg.Bucket.Name, cfg.Bucket.S3)
+	case config.StorageBackendLocal:
+		signingKey := []byte(cfg.Bucket.Local.SigningKey)
+		if len(signingKey) == 0 {
+			log.Println("STORAGE_SIGNING_KEY is not set, signing storage URLs with a generated key valid until restart")
+			signingKey = make([]byte, generatedSigningKeySize)
+			if _, err := rand.Read(signingKey); err != nil {
+				return nil, fmt.Errorf("error generating signing key: %w", err)
+			}
+		}
+		log.Printf("Storing files under %s", cfg.Bucket.Local.Dir)
+		return local.NewLocalStorage(cfg.Bucket.Local.Dir, cfg.Bucket.Local.PublicURL, signingKey)
+	default:
+		return nil, fmt.Errorf("unknown storage backend %q", cfg.Bucket.Backend)
+	}
+}
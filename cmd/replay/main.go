@@ -0,0 +1,56 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/aferryc/yars/internal/config"
+	"github.com/aferryc/yars/transport"
+	"github.com/twmb/franz-go/pkg/kgo"
+)
+
+// Replays the records of a dead-letter topic back onto their source topic,
+// e.g. once the cause of the failures is fixed:
+//
+//	yars-replay -topic compiler-events
+func main() {
+	topic := flag.String("topic", "", "source topic whose dead-letter topic is replayed")
+	idleTimeout := flag.Duration("idle-timeout", 10*time.Second, "stop once no record arrived for this long")
+	flag.Parse()
+
+	if *topic == "" {
+		log.Fatal("-topic is required")
+	}
+
+	cfg := config.LoadConfig()
+	deadLetterTopic := transport.DeadLetterTopic(*topic)
+
+	client, err := kgo.NewClient(
+		kgo.SeedBrokers(cfg.Kafka.BrokerList...),
+		kgo.ClientID(cfg.Kafka.ClientID),
+		kgo.ConsumerGroup(cfg.Kafka.GroupID+"-dlq-replay"),
+		kgo.ConsumeTopics(deadLetterTopic),
+		kgo.ConsumeResetOffset(kgo.NewOffset().AtStart()),
+		kgo.DisableAutoCommit(),
+		kgo.RequiredAcks(kgo.AllISRAcks()),
+	)
+	if err != nil {
+		log.Fatalf("Failed to create Kafka client: %v", err)
+	}
+	defer client.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	log.Printf("Replaying %s onto %s", deadLetterTopic, *topic)
+	replayed, err := transport.ReplayDeadLetters(ctx, client, *idleTimeout)
+	if err != nil {
+		log.Fatalf("Replay stopped after %d records: %v", replayed, err)
+	}
+	log.Printf("Replayed %d records from %s", replayed, deadLetterTopic)
+}
@@ -8,8 +8,8 @@ import (
 
 	"github.com/aferryc/yars/cmd/initialize"
 	"github.com/aferryc/yars/internal/config"
-	"github.com/aferryc/yars/repository/gcs"
 	"github.com/aferryc/yars/repository/kafka"
+	"github.com/aferryc/yars/repository/local"
 	"github.com/aferryc/yars/repository/postgres"
 	"github.com/aferryc/yars/transport"
 	"github.com/aferryc/yars/usecase"
@@ -24,8 +24,8 @@ func main() {
 	log.Println("Environment variables:")
 	log.Printf("  PORT=%s", os.Getenv("PORT"))
 	log.Printf("  DATABASE_URL=%s", maskPassword(os.Getenv("DATABASE_URL")))
+	log.Printf("  STORAGE_BACKEND=%s", os.Getenv("STORAGE_BACKEND"))
 	log.Printf("  BUCKET_NAME=%s", os.Getenv("BUCKET_NAME"))
-	log.Printf("  BUCKET_URL=%s", os.Getenv("BUCKET_URL"))
 	log.Printf("  KAFKA_BROKERS=%s", os.Getenv("KAFKA_BROKERS"))
 	log.Printf("  STORAGE_EMULATOR_HOST=%s", os.Getenv("STORAGE_EMULATOR_HOST"))
 
@@ -35,21 +35,13 @@ func main() {
 	log.Printf("Configuration loaded: Port=%s, BucketName=%s, KafkaClientID=%s",
 		cfg.Port, cfg.Bucket.Name, cfg.Kafka.ClientID)
 
-	// Connect to GCS
-	log.Println("Connecting to GCS storage...")
-	gcsClient, err := initialize.ConnectGCS(cfg)
+	// Initialize storage repository
+	log.Printf("Initializing %s storage repository...", cfg.Bucket.Backend)
+	storageRepo, err := initialize.NewStorageRepository(cfg)
 	if err != nil {
-		log.Fatalf("Failed to connect to GCS: %v", err)
+		log.Fatalf("Failed to create storage repository: %v", err)
 	}
-	log.Println("Successfully connected to GCS")
-
-	// Initialize GCS repository
-	log.Printf("Initializing GCS repository with bucket: %s", cfg.Bucket.Name)
-	gcsRepo, err := gcs.NewGCSRepository(cfg.Bucket.Name, gcsClient)
-	if err != nil {
-		log.Fatalf("Failed to create GCS repository: %v", err)
-	}
-	log.Println("GCS repository initialized")
+	log.Println("Storage repository initialized")
 
 	// Connect to Kafka
 	log.Printf("Connecting to Kafka at %s with client ID %s...", cfg.Kafka.BrokerList, cfg.Kafka.ClientID)
@@ -80,17 +72,24 @@ func main() {
 	// Initialize repository and use cases
 	log.Println("Initializing repositories and use cases...")
 	listRepo := postgres.NewDBReconResultRepository(dbConn)
-	reconUC := usecase.NewReconManager(gcsRepo, kafkaRepo, cfg)
+	taskRepo := postgres.NewDBReconTaskRepository(dbConn)
+	reconUC := usecase.NewReconManager(storageRepo, kafkaRepo, taskRepo, cfg)
 	listUC := usecase.NewListUsecase(listRepo)
+	fxRateUC := usecase.NewFXRateUsecase(postgres.NewDBFXRateRepository(dbConn))
+	profileUC := usecase.NewIngestionProfileUsecase(postgres.NewDBIngestionProfileRepository(dbConn))
 
 	// Set up the router
 	log.Println("Setting up HTTP router...")
-	handler := transport.NewHandler(reconUC, listUC)
+	handler := transport.NewHandler(reconUC, listUC, fxRateUC, profileUC)
 	router := initialize.SetupRouter(*handler)
+	if localStorage, ok := storageRepo.(*local.LocalStorage); ok {
+		initialize.RegisterStorageRoutes(router, transport.NewStorageHandler(localStorage))
+	}
 	log.Println("Router setup complete")
 
 	// Start the HTTP server
 	log.Printf("Starting server on port %s...", cfg.Port)
+	// The storage routes lift the read and write timeouts for their transfers
 	server := &http.Server{
 		Addr:         ":" + cfg.Port,
 		Handler:      router,
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/aferryc/yars/cmd/initialize"
+	"github.com/aferryc/yars/internal/config"
+	"github.com/aferryc/yars/repository/kafka"
+	"github.com/aferryc/yars/repository/local"
+	"github.com/aferryc/yars/repository/memory"
+	"github.com/aferryc/yars/repository/postgres"
+	"github.com/aferryc/yars/transport"
+	"github.com/aferryc/yars/usecase"
+)
+
+const shutdownTimeout = 10 * time.Second
+
+// main runs the HTTP server, the compiler and the reconciler in one process,
+// passing events through an in-memory broker instead of Kafka
+func main() {
+	log.SetFlags(log.LstdFlags | log.Lshortfile)
+	cfg := config.LoadConfig()
+
+	storageRepo, err := initialize.NewStorageRepository(cfg)
+	if err != nil {
+		log.Fatalf("Failed to create storage repository: %v", err)
+	}
+
+	pgConn := initialize.ConnectDB(cfg.DatabaseURL)
+	if pgConn == nil {
+		log.Fatal("Failed to connect to PostgreSQL")
+	}
+
+	bankRepo := postgres.NewDBBankStatementRepository(pgConn)
+	transactionRepo := postgres.NewDBInternalTransactionRepository(pgConn)
+	reconRepo := postgres.NewDBReconResultRepository(pgConn)
+	fxRepo := postgres.NewDBFXRateRepository(pgConn)
+	taskRepo := postgres.NewDBReconTaskRepository(pgConn)
+	transactor := postgres.NewDBTransactor(pgConn)
+	outboxRepo := postgres.NewDBOutboxRepository(pgConn)
+	profileRepo := postgres.NewDBIngestionProfileRepository(pgConn)
+	statementRepo := postgres.NewDBStatementRepository(pgConn)
+
+	// Events on the result and dead-letter topics are kept in memory, nothing
+	// consumes them in this process
+	broker := memory.NewBroker()
+	kafkaRepo := kafka.NewKafkaRepository(broker)
+	relay := usecase.NewOutboxRelay(cfg.App.Outbox, transactor, outboxRepo, kafkaRepo)
+
+	matchers, err := usecase.BuildMatcherChain(cfg.App.Reconciliation)
+	if err != nil {
+		log.Fatalf("Failed to build matcher chain: %v", err)
+	}
+
+	compilerUC := usecase.NewFileCompiler(cfg, storageRepo, bankRepo, transactionRepo, transactor, outboxRepo, taskRepo, profileRepo, statementRepo)
+	reconciliationUC := usecase.NewReconciliationUsecase(cfg, matchers, transactionRepo, bankRepo, reconRepo, fxRepo, taskRepo, transactor, outboxRepo)
+	compilerConsumer := transport.NewMemoryConsumer(&cfg.Kafka, broker, cfg.Kafka.Topic.CompilerTopic, compilerUC)
+	reconciliationConsumer := transport.NewMemoryConsumer(&cfg.Kafka, broker, cfg.Kafka.Topic.ReconTopic, reconciliationUC)
+
+	reconUC := usecase.NewReconManager(storageRepo, kafkaRepo, taskRepo, cfg)
+	listUC := usecase.NewListUsecase(reconRepo)
+	fxRateUC := usecase.NewFXRateUsecase(fxRepo)
+	profileUC := usecase.NewIngestionProfileUsecase(profileRepo)
+	handler := transport.NewHandler(reconUC, listUC, fxRateUC, profileUC)
+	router := initialize.SetupRouter(*handler)
+	if localStorage, ok := storageRepo.(*local.LocalStorage); ok {
+		initialize.RegisterStorageRoutes(router, transport.NewStorageHandler(localStorage))
+	}
+	server := &http.Server{
+		Addr:         ":" + cfg.Port,
+		Handler:      router,
+		ReadTimeout:  10 * time.Second,
+		WriteTimeout: 10 * time.Second,
+		IdleTimeout:  120 * time.Second,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP)
+	defer stop()
+
+	// Every component runs until ctx is cancelled, one stopping on its own
+	// stops the others
+	var wg sync.WaitGroup
+	errs := make(chan error, 4)
+	run := func(name string, fn func() error) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer stop()
+			if err := fn(); err != nil {
+				errs <- err
+				log.Printf("%s stopped: %v", name, err)
+			}
+		}()
+	}
+
+	run("Compiler consumer", func() error { return compilerConsumer.Start(ctx) })
+	run("Reconciliation consumer", func() error { return reconciliationConsumer.Start(ctx) })
+	run("Outbox relay", func() error {
+		relay.Run(ctx)
+		return nil
+	})
+	run("Server", func() error {
+		log.Printf("Server ready to accept connections on port %s", cfg.Port)
+		if err := server.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+			return err
+		}
+		return nil
+	})
+
+	<-ctx.Done()
+	log.Println("Shutting down...")
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		log.Printf("Error shutting down server: %v", err)
+	}
+
+	wg.Wait()
+	kafkaRepo.Close()
+	close(errs)
+	if err := <-errs; err != nil {
+		log.Fatalf("Standalone error: %v", err)
+	}
+	log.Println("Standalone stopped")
+}
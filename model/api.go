@@ -3,10 +3,12 @@ package model
 import "time"
 
 type UploadURLResponse struct {
-	TransactionURL   string    `json:"transactionUrl"`
-	BankStatementURL string    `json:"bankStatementUrl"`
-	TaskID           string    `json:"taskID"`
-	ExpiresAt        time.Time `json:"expiresAt"`
+	TransactionURL   string `json:"transactionUrl"`
+	BankStatementURL string `json:"bankStatementUrl"`
+	// UploadMethod is the HTTP method to upload the files with
+	UploadMethod string    `json:"uploadMethod"`
+	TaskID       string    `json:"taskID"`
+	ExpiresAt    time.Time `json:"expiresAt"`
 }
 
 type CompilerRequest struct {
@@ -19,7 +21,9 @@ type CompilerRequest struct {
 type ReconSummaryResponse struct {
 	TaskID                 string    `json:"taskId"`
 	TotalMatched           int       `json:"totalMatched"`
-	TotalDiscrepancy       float64   `json:"totalDiscrepancy"`
+	TotalDiscrepancy       Money     `json:"totalDiscrepancy"`
+	Currency               string    `json:"currency"`
+	MissingRates           []string  `json:"missingRates,omitempty"`
 	TotalTransaction       int       `json:"totalTransaction"`
 	TotalUnmatchedBank     int       `json:"totalUnmatchedBank"`
 	TotalUnmatchedInternal int       `json:"totalUnmatchedInternal"`
@@ -32,19 +36,73 @@ type ReconSummaryResponse struct {
 type UnmatchedTransactionResponse struct {
 	ID              string    `json:"id"`
 	TaskID          string    `json:"taskId"`
-	Amount          float64   `json:"amount"`
+	Amount          Money     `json:"amount"`
+	Currency        string    `json:"currency"`
 	TransactionTime time.Time `json:"transactionTime"`
 	Type            string    `json:"type"`
 	Description     string    `json:"description"`
 }
 
 type UnmatchedBankStatementResponse struct {
-	ID        int       `json:"id"`
-	TaskID    string    `json:"taskId"`
-	Amount    float64   `json:"amount"`
-	Date      time.Time `json:"date"`
-	Reference string    `json:"reference"`
-	BankName  string    `json:"bankName"`
+	ID              int       `json:"id"`
+	TaskID          string    `json:"taskId"`
+	BankStatementID string    `json:"bankStatementId"`
+	Amount          Money     `json:"amount"`
+	Currency        string    `json:"currency"`
+	Date            time.Time `json:"date"`
+	Reference       string    `json:"reference"`
+	BankName        string    `json:"bankName"`
+}
+
+type MatchedPairResponse struct {
+	ID                  int       `json:"id"`
+	TaskID              string    `json:"taskId"`
+	Rule                string    `json:"rule"`
+	MatchType           string    `json:"matchType"`
+	AggregateMatchID    string    `json:"aggregateMatchId,omitempty"`
+	TransactionID       string    `json:"transactionId"`
+	TransactionAmount   Money     `json:"transactionAmount"`
+	TransactionCurrency string    `json:"transactionCurrency"`
+	TransactionTime     time.Time `json:"transactionTime"`
+	BankStatementID     string    `json:"bankStatementId"`
+	BankAmount          Money     `json:"bankAmount"`
+	BankCurrency        string    `json:"bankCurrency"`
+	BankDate            time.Time `json:"bankDate"`
+	BankReference       string    `json:"bankReference"`
+	// AmountDifference is the bank amount less the transaction amount, in the
+	// bank currency. It is left out for the pairs of a group.
+	AmountDifference *Money    `json:"amountDifference,omitempty"`
+	CreatedAt        time.Time `json:"createdAt"`
+}
+
+type AggregateMatchResponse struct {
+	ID        string                       `json:"id"`
+	TaskID    string                       `json:"taskId"`
+	MatchType string                       `json:"matchType"`
+	Rule      string                       `json:"rule"`
+	Amount    Money                        `json:"amount"`
+	Currency  string                       `json:"currency"`
+	Items     []AggregateMatchItemResponse `json:"items"`
+	CreatedAt time.Time                    `json:"createdAt"`
+}
+
+type AggregateMatchItemResponse struct {
+	RecordType string    `json:"recordType"`
+	RecordID   string    `json:"recordId"`
+	Amount     Money     `json:"amount"`
+	Currency   string    `json:"currency"`
+	Date       time.Time `json:"date"`
+}
+
+type FXRateResponse struct {
+	BaseCurrency  string    `json:"baseCurrency"`
+	QuoteCurrency string    `json:"quoteCurrency"`
+	Date          time.Time `json:"date"`
+	Rate          string    `json:"rate"`
+}
+
+type FXRateImportResponse struct {
+	Imported int `json:"imported"`
 }
 
 type PaginatedResponse struct {
@@ -53,3 +111,12 @@ type PaginatedResponse struct {
 	Limit      int `json:"limit"`
 	Offset     int `json:"offset"`
 }
+
+type TaskStatusResponse struct {
+	TaskID     string     `json:"taskId"`
+	Status     TaskStatus `json:"status"`
+	Error      string     `json:"error,omitempty"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	UpdatedAt  time.Time  `json:"updatedAt"`
+	FinishedAt *time.Time `json:"finishedAt,omitempty"`
+}
@@ -0,0 +1,189 @@
+package model
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Event types, each published with its current schema version
+const (
+	EventTypeCompilationRequested    = "compilation.requested"
+	EventTypeReconciliationRequested = "reconciliation.requested"
+	EventTypeReconciliationCompleted = "reconciliation.completed"
+)
+
+// Headers carrying the envelope of an event
+const (
+	HeaderEventType     = "event_type"
+	HeaderSchemaVersion = "schema_version"
+	HeaderEventID       = "event_id"
+	HeaderOccurredAt    = "occurred_at"
+	HeaderTaskID        = "task_id"
+	HeaderTraceParent   = "traceparent"
+)
+
+// Upcaster converts a payload of one schema version to the next version
+type Upcaster func(payload []byte) ([]byte, error)
+
+type eventSchema struct {
+	version int
+	// upcasters holds the upcaster from each older version to the next
+	upcasters map[int]Upcaster
+}
+
+// eventSchemas lists the current version of every event type. Events
+// published before the envelope existed carry no headers and are read as
+// version 0, whose payload is the same as version 1.
+var eventSchemas = map[string]eventSchema{
+	EventTypeCompilationRequested: {
+		version:   1,
+		upcasters: map[int]Upcaster{0: unchangedPayload},
+	},
+	EventTypeReconciliationRequested: {
+		version:   1,
+		upcasters: map[int]Upcaster{0: unchangedPayload},
+	},
+	EventTypeReconciliationCompleted: {
+		version: 1,
+	},
+}
+
+func unchangedPayload(payload []byte) ([]byte, error) {
+	return payload, nil
+}
+
+// EventEnvelope describes an event. It travels in the message headers so the
+// payload keeps the shape of the event itself.
+type EventEnvelope struct {
+	EventType     string
+	SchemaVersion int
+	EventID       string
+	OccurredAt    time.Time
+	TaskID        string
+	// TraceParent is the W3C trace context of the event
+	TraceParent string
+}
+
+// NewEventEnvelope creates the envelope of a new event of one of the event
+// types above, continuing the trace carried by ctx or starting a new one
+func NewEventEnvelope(ctx context.Context, eventType, taskID string) EventEnvelope {
+	return EventEnvelope{
+		EventType:     eventType,
+		SchemaVersion: eventSchemas[eventType].version,
+		EventID:       uuid.New().String(),
+		OccurredAt:    time.Now().UTC(),
+		TaskID:        taskID,
+		TraceParent:   childTraceParent(TraceParentFromContext(ctx)),
+	}
+}
+
+// Headers returns the envelope as message headers
+func (e EventEnvelope) Headers() map[string]string {
+	headers := map[string]string{
+		HeaderEventType:     e.EventType,
+		HeaderSchemaVersion: strconv.Itoa(e.SchemaVersion),
+		HeaderEventID:       e.EventID,
+		HeaderOccurredAt:    e.OccurredAt.Format(time.RFC3339Nano),
+		HeaderTaskID:        e.TaskID,
+	}
+	if e.TraceParent != "" {
+		headers[HeaderTraceParent] = e.TraceParent
+	}
+	return headers
+}
+
+// ParseEventEnvelope reads the envelope from message headers. A message
+// without an event type predates the envelope and is returned as version 0 of
+// defaultType.
+func ParseEventEnvelope(headers map[string]string, defaultType string) (EventEnvelope, error) {
+	eventType := headers[HeaderEventType]
+	if eventType == "" {
+		return EventEnvelope{EventType: defaultType, TaskID: headers[HeaderTaskID]}, nil
+	}
+
+	version, err := strconv.Atoi(headers[HeaderSchemaVersion])
+	if err != nil {
+		return EventEnvelope{}, fmt.Errorf("%w: schema version %q", ErrInvalidEventEnvelope, headers[HeaderSchemaVersion])
+	}
+
+	var occurredAt time.Time
+	if value := headers[HeaderOccurredAt]; value != "" {
+		occurredAt, err = time.Parse(time.RFC3339Nano, value)
+		if err != nil {
+			return EventEnvelope{}, fmt.Errorf("%w: occurred at %q", ErrInvalidEventEnvelope, value)
+		}
+	}
+
+	return EventEnvelope{
+		EventType:     eventType,
+		SchemaVersion: version,
+		EventID:       headers[HeaderEventID],
+		OccurredAt:    occurredAt,
+		TaskID:        headers[HeaderTaskID],
+		TraceParent:   headers[HeaderTraceParent],
+	}, nil
+}
+
+// UpcastEvent converts the payload of an event to the current version of its
+// type. Unknown types and versions newer than the current one are rejected.
+func UpcastEvent(envelope EventEnvelope, payload []byte) ([]byte, error) {
+	schema, ok := eventSchemas[envelope.EventType]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownEventType, envelope.EventType)
+	}
+	if envelope.SchemaVersion > schema.version || envelope.SchemaVersion < 0 {
+		return nil, fmt.Errorf("%w: %s version %d", ErrUnsupportedEventVersion, envelope.EventType, envelope.SchemaVersion)
+	}
+
+	var err error
+	for version := envelope.SchemaVersion; version < schema.version; version++ {
+		upcast, ok := schema.upcasters[version]
+		if !ok {
+			return nil, fmt.Errorf("%w: %s version %d", ErrUnsupportedEventVersion, envelope.EventType, version)
+		}
+		if payload, err = upcast(payload); err != nil {
+			return nil, fmt.Errorf("failed to upcast %s from version %d: %w", envelope.EventType, version, err)
+		}
+	}
+	return payload, nil
+}
+
+type traceParentKey struct{}
+
+// ContextWithTraceParent returns a context carrying the W3C trace context of
+// the event being handled, so the events it publishes continue its trace
+func ContextWithTraceParent(ctx context.Context, traceParent string) context.Context {
+	if traceParent == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, traceParentKey{}, traceParent)
+}
+
+// TraceParentFromContext returns the W3C trace context carried by ctx, if any
+func TraceParentFromContext(ctx context.Context) string {
+	traceParent, _ := ctx.Value(traceParentKey{}).(string)
+	return traceParent
+}
+
+// childTraceParent returns a trace context with a new parent ID in the trace
+// of parent, or in a new trace when parent is not a valid trace context
+func childTraceParent(parent string) string {
+	traceID := randomHex(16)
+	// version-traceid-parentid-flags, e.g. 00-<32 hex>-<16 hex>-01
+	if len(parent) == 55 && parent[2] == '-' && parent[35] == '-' && parent[52] == '-' {
+		traceID = parent[3:35]
+	}
+	return "00-" + traceID + "-" + randomHex(8) + "-01"
+}
+
+func randomHex(n int) string {
+	b := make([]byte, n)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,124 @@
+package model_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aferryc/yars/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const traceParent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+
+func TestNewEventEnvelope(t *testing.T) {
+	t.Run("Starts a new trace", func(t *testing.T) {
+		// Execute
+		envelope := model.NewEventEnvelope(context.Background(), model.EventTypeCompilationRequested, "task-1")
+
+		// Assert
+		assert.Equal(t, model.EventTypeCompilationRequested, envelope.EventType)
+		assert.Equal(t, 1, envelope.SchemaVersion)
+		assert.Equal(t, "task-1", envelope.TaskID)
+		assert.NotEmpty(t, envelope.EventID)
+		assert.False(t, envelope.OccurredAt.IsZero())
+		assert.Regexp(t, `^00-[0-9a-f]{32}-[0-9a-f]{16}-01$`, envelope.TraceParent)
+	})
+
+	t.Run("Continues the trace of the context", func(t *testing.T) {
+		// Setup
+		ctx := model.ContextWithTraceParent(context.Background(), traceParent)
+
+		// Execute
+		envelope := model.NewEventEnvelope(ctx, model.EventTypeReconciliationRequested, "task-1")
+
+		// Assert
+		assert.Equal(t, traceParent[:36], envelope.TraceParent[:36])
+		assert.NotEqual(t, traceParent, envelope.TraceParent)
+	})
+}
+
+func TestParseEventEnvelope(t *testing.T) {
+	t.Run("Round trip through headers", func(t *testing.T) {
+		// Setup
+		ctx := model.ContextWithTraceParent(context.Background(), traceParent)
+		envelope := model.NewEventEnvelope(ctx, model.EventTypeCompilationRequested, "task-1")
+
+		// Execute
+		parsed, err := model.ParseEventEnvelope(envelope.Headers(), model.EventTypeCompilationRequested)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, envelope.EventType, parsed.EventType)
+		assert.Equal(t, envelope.SchemaVersion, parsed.SchemaVersion)
+		assert.Equal(t, envelope.EventID, parsed.EventID)
+		assert.True(t, envelope.OccurredAt.Equal(parsed.OccurredAt))
+		assert.Equal(t, envelope.TaskID, parsed.TaskID)
+		assert.Equal(t, envelope.TraceParent, parsed.TraceParent)
+	})
+
+	t.Run("Message without envelope", func(t *testing.T) {
+		// Execute
+		parsed, err := model.ParseEventEnvelope(nil, model.EventTypeCompilationRequested)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, model.EventTypeCompilationRequested, parsed.EventType)
+		assert.Equal(t, 0, parsed.SchemaVersion)
+	})
+
+	t.Run("Invalid schema version", func(t *testing.T) {
+		// Execute
+		_, err := model.ParseEventEnvelope(map[string]string{
+			model.HeaderEventType:     model.EventTypeCompilationRequested,
+			model.HeaderSchemaVersion: "v1",
+		}, model.EventTypeCompilationRequested)
+
+		// Assert
+		assert.ErrorIs(t, err, model.ErrInvalidEventEnvelope)
+	})
+}
+
+func TestUpcastEvent(t *testing.T) {
+	payload := []byte(`{"taskID":"task-1"}`)
+
+	tests := []struct {
+		name        string
+		envelope    model.EventEnvelope
+		expectedErr error
+	}{
+		{
+			name:     "Current version",
+			envelope: model.EventEnvelope{EventType: model.EventTypeCompilationRequested, SchemaVersion: 1},
+		},
+		{
+			name:     "Version 0 is upcast",
+			envelope: model.EventEnvelope{EventType: model.EventTypeReconciliationRequested, SchemaVersion: 0},
+		},
+		{
+			name:        "Newer version is rejected",
+			envelope:    model.EventEnvelope{EventType: model.EventTypeCompilationRequested, SchemaVersion: 2},
+			expectedErr: model.ErrUnsupportedEventVersion,
+		},
+		{
+			name:        "Unknown type is rejected",
+			envelope:    model.EventEnvelope{EventType: "task.archived", SchemaVersion: 1},
+			expectedErr: model.ErrUnknownEventType,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Execute
+			upcast, err := model.UpcastEvent(tt.envelope, payload)
+
+			// Assert
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, payload, upcast)
+		})
+	}
+}
@@ -3,8 +3,22 @@ package model
 import "errors"
 
 var (
-	ErrTransactionNotFound    = errors.New("transaction not found")
-	ErrBankStatementNotFound  = errors.New("bank statement not found")
-	ErrTransactionMismatch    = errors.New("transaction mismatch")
-	ErrInvalidTransactionData = errors.New("invalid transaction data")
+	ErrTransactionNotFound      = errors.New("transaction not found")
+	ErrBankStatementNotFound    = errors.New("bank statement not found")
+	ErrTransactionMismatch      = errors.New("transaction mismatch")
+	ErrInvalidTransactionData   = errors.New("invalid transaction data")
+	ErrCurrencyMismatch         = errors.New("currency mismatch")
+	ErrFXRateNotFound           = errors.New("fx rate not found")
+	ErrInvalidFXRate            = errors.New("invalid fx rate")
+	ErrTaskNotFound             = errors.New("task not found")
+	ErrInvalidTaskTransition    = errors.New("invalid task status transition")
+	ErrUnknownEventType         = errors.New("unknown event type")
+	ErrUnsupportedEventVersion  = errors.New("unsupported event schema version")
+	ErrInvalidEventEnvelope     = errors.New("invalid event envelope")
+	ErrObjectNotFound           = errors.New("object not found")
+	ErrInvalidObjectName        = errors.New("invalid object name")
+	ErrInvalidSignedURL         = errors.New("invalid signed url")
+	ErrSignedURLExpired         = errors.New("signed url expired")
+	ErrInvalidIngestionProfile  = errors.New("invalid ingestion profile")
+	ErrIngestionProfileNotFound = errors.New("ingestion profile not found")
 )
@@ -2,6 +2,14 @@ package model
 
 import "time"
 
+// Message is an event delivered to a handler, independent of the broker
+type Message struct {
+	Topic   string
+	Key     []byte
+	Value   []byte
+	Headers map[string]string
+}
+
 type CompilerEvent struct {
 	BankStatement string    `json:"bank_statement"`
 	Transaction   string    `json:"transaction"`
@@ -11,8 +19,28 @@ type CompilerEvent struct {
 	TaskID        string    `json:"taskID"`
 }
 
+// ReconciliationCompletedEvent reports the figures of a finished
+// reconciliation to downstream services
+type ReconciliationCompletedEvent struct {
+	TaskID                 string    `json:"taskID"`
+	BankName               string    `json:"bankName"`
+	StartDate              time.Time `json:"startDate"`
+	EndDate                time.Time `json:"endDate"`
+	TotalTransaction       int       `json:"totalTransaction"`
+	TotalMatched           int       `json:"totalMatched"`
+	TotalUnmatchedInternal int       `json:"totalUnmatchedInternal"`
+	TotalUnmatchedBank     int       `json:"totalUnmatchedBank"`
+	TotalDiscrepancy       Money     `json:"totalDiscrepancy"`
+	Currency               string    `json:"currency"`
+	MissingRates           []string  `json:"missingRates,omitempty"`
+	CompletedAt            time.Time `json:"completedAt"`
+}
+
+// ReconciliationEvent asks for the data ingested by a task for a bank to be
+// reconciled
 type ReconciliationEvent struct {
 	TaskID    string    `json:"taskID"`
+	BankName  string    `json:"bankName"`
 	StartDate time.Time `json:"startDate,omitempty"`
 	EndDate   time.Time `json:"endDate,omitempty"`
 }
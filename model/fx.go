@@ -0,0 +1,127 @@
+package model
+
+import (
+	"math/big"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// fxRateDecimals matches the scale of the fx_rates.rate column
+const fxRateDecimals = 10
+
+// FXRate is the price of one unit of BaseCurrency in QuoteCurrency on Date
+type FXRate struct {
+	BaseCurrency  string
+	QuoteCurrency string
+	Date          time.Time
+	Rate          *big.Rat
+}
+
+// ParseFXRate parses a positive decimal exchange rate such as "15234.5" exactly
+func ParseFXRate(s string) (*big.Rat, error) {
+	value := strings.TrimSpace(s)
+	if value == "" || strings.ContainsAny(value, "eE/") {
+		return nil, errors.Errorf("[ParseFXRate] invalid rate %q", s)
+	}
+	rate, ok := new(big.Rat).SetString(value)
+	if !ok || rate.Sign() <= 0 {
+		return nil, errors.Errorf("[ParseFXRate] invalid rate %q", s)
+	}
+	return rate, nil
+}
+
+// FormatFXRate formats a rate as a plain decimal with up to fxRateDecimals
+// decimal places and no trailing zeros
+func FormatFXRate(rate *big.Rat) string {
+	if rate == nil {
+		return ""
+	}
+	value := rate.FloatString(fxRateDecimals)
+	value = strings.TrimRight(value, "0")
+	return strings.TrimSuffix(value, ".")
+}
+
+// FXRateTable answers which rate applies to a currency pair on a given date.
+// The rate published on the date itself wins, otherwise the latest rate from
+// the previous lookbackDays is used, e.g. Friday's rate on a Sunday.
+type FXRateTable struct {
+	rates        map[string][]FXRate
+	lookbackDays int
+}
+
+// NewFXRateTable indexes the given rates for lookups
+func NewFXRateTable(rates []FXRate, lookbackDays int) *FXRateTable {
+	table := &FXRateTable{
+		rates:        make(map[string][]FXRate),
+		lookbackDays: lookbackDays,
+	}
+	for _, rate := range rates {
+		key := fxPairKey(rate.BaseCurrency, rate.QuoteCurrency)
+		table.rates[key] = append(table.rates[key], rate)
+	}
+	for _, pairRates := range table.rates {
+		sort.SliceStable(pairRates, func(i, j int) bool {
+			return pairRates[i].Date.Before(pairRates[j].Date)
+		})
+	}
+	return table
+}
+
+// Rate returns the rate to convert base into quote on the given date. Only the
+// opposite pair being published is enough, its rate is inverted.
+func (t *FXRateTable) Rate(base, quote string, date time.Time) (*big.Rat, bool) {
+	base, quote = strings.ToUpper(base), strings.ToUpper(quote)
+	if base == quote {
+		return big.NewRat(1, 1), true
+	}
+	if t == nil {
+		return nil, false
+	}
+	if rate, ok := t.lookup(base, quote, date); ok {
+		return rate, true
+	}
+	if rate, ok := t.lookup(quote, base, date); ok {
+		return new(big.Rat).Inv(rate), true
+	}
+	return nil, false
+}
+
+// Convert converts the amount into currency using the rate effective on date
+func (t *FXRateTable) Convert(amount Money, currency string, date time.Time) (Money, error) {
+	rate, ok := t.Rate(amount.Currency(), currency, date)
+	if !ok {
+		return Money{}, errors.Wrapf(ErrFXRateNotFound, "[FXRateTable.Convert] %s/%s on %s", amount.Currency(), strings.ToUpper(currency), date.Format("2006-01-02"))
+	}
+	return amount.Convert(rate, currency)
+}
+
+func (t *FXRateTable) lookup(base, quote string, date time.Time) (*big.Rat, bool) {
+	pairRates := t.rates[fxPairKey(base, quote)]
+	day := truncateToDay(date)
+
+	// Index of the first rate published after the requested day
+	i := sort.Search(len(pairRates), func(i int) bool {
+		return truncateToDay(pairRates[i].Date).After(day)
+	})
+	if i == 0 {
+		return nil, false
+	}
+
+	latest := pairRates[i-1]
+	if day.Sub(truncateToDay(latest.Date)) > time.Duration(t.lookbackDays)*24*time.Hour {
+		return nil, false
+	}
+	return latest.Rate, true
+}
+
+func fxPairKey(base, quote string) string {
+	return strings.ToUpper(base) + "/" + strings.ToUpper(quote)
+}
+
+func truncateToDay(t time.Time) time.Time {
+	y, m, d := t.UTC().Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
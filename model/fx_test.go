@@ -0,0 +1,104 @@
+package model_test
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/aferryc/yars/model"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseFXRate(t *testing.T) {
+	tests := []struct {
+		name          string
+		input         string
+		expected      *big.Rat
+		expectedError bool
+	}{
+		{name: "Decimal", input: "0.9215", expected: big.NewRat(9215, 10000)},
+		{name: "Whole number", input: " 15500 ", expected: big.NewRat(15500, 1)},
+		{name: "Empty", input: "", expectedError: true},
+		{name: "Zero", input: "0", expectedError: true},
+		{name: "Negative", input: "-1.2", expectedError: true},
+		{name: "Fraction", input: "1/3", expectedError: true},
+		{name: "Exponent", input: "1e3", expectedError: true},
+		{name: "Not a number", input: "abc", expectedError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rate, err := model.ParseFXRate(tt.input)
+			if tt.expectedError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, 0, tt.expected.Cmp(rate))
+		})
+	}
+}
+
+func TestFormatFXRate(t *testing.T) {
+	assert.Equal(t, "0.9215", model.FormatFXRate(big.NewRat(9215, 10000)))
+	assert.Equal(t, "15500", model.FormatFXRate(big.NewRat(15500, 1)))
+	assert.Equal(t, "0.3333333333", model.FormatFXRate(big.NewRat(1, 3)))
+	assert.Equal(t, "", model.FormatFXRate(nil))
+}
+
+func TestFXRateTable(t *testing.T) {
+	day := func(d int) time.Time {
+		return time.Date(2023, 1, d, 0, 0, 0, 0, time.UTC)
+	}
+	table := model.NewFXRateTable([]model.FXRate{
+		{BaseCurrency: "USD", QuoteCurrency: "EUR", Date: day(10), Rate: big.NewRat(9, 10)},
+		{BaseCurrency: "USD", QuoteCurrency: "EUR", Date: day(6), Rate: big.NewRat(8, 10)},
+		{BaseCurrency: "USD", QuoteCurrency: "IDR", Date: day(6), Rate: big.NewRat(15000, 1)},
+	}, 3)
+
+	t.Run("Rate published on the date", func(t *testing.T) {
+		rate, ok := table.Rate("USD", "EUR", day(10))
+		require.True(t, ok)
+		assert.Equal(t, 0, big.NewRat(9, 10).Cmp(rate))
+	})
+
+	t.Run("Latest earlier rate within the lookback", func(t *testing.T) {
+		rate, ok := table.Rate("usd", "eur", time.Date(2023, 1, 9, 18, 30, 0, 0, time.UTC))
+		require.True(t, ok)
+		assert.Equal(t, 0, big.NewRat(8, 10).Cmp(rate))
+	})
+
+	t.Run("Rate older than the lookback", func(t *testing.T) {
+		_, ok := table.Rate("USD", "IDR", day(10))
+		assert.False(t, ok)
+	})
+
+	t.Run("No rate before the date", func(t *testing.T) {
+		_, ok := table.Rate("USD", "EUR", day(5))
+		assert.False(t, ok)
+	})
+
+	t.Run("Inverse of the opposite pair", func(t *testing.T) {
+		rate, ok := table.Rate("EUR", "USD", day(10))
+		require.True(t, ok)
+		assert.Equal(t, 0, big.NewRat(10, 9).Cmp(rate))
+	})
+
+	t.Run("Same currency", func(t *testing.T) {
+		var empty *model.FXRateTable
+		rate, ok := empty.Rate("JPY", "JPY", day(10))
+		require.True(t, ok)
+		assert.Equal(t, 0, big.NewRat(1, 1).Cmp(rate))
+	})
+
+	t.Run("Convert", func(t *testing.T) {
+		converted, err := table.Convert(model.NewMoney(10000, "USD"), "EUR", day(10))
+		require.NoError(t, err)
+		assert.Equal(t, model.NewMoney(9000, "EUR"), converted)
+
+		_, err = table.Convert(model.NewMoney(10000, "USD"), "GBP", day(10))
+		assert.True(t, errors.Is(err, model.ErrFXRateNotFound))
+	})
+}
@@ -0,0 +1,359 @@
+package model
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// DefaultMinorUnits is the number of decimal places used for currencies not
+// listed in currencyMinorUnits, and for amounts without a currency.
+const DefaultMinorUnits = 2
+
+// currencyMinorUnits lists the ISO 4217 currencies whose minor unit differs
+// from DefaultMinorUnits.
+var currencyMinorUnits = map[string]int{
+	"BHD": 3,
+	"CLP": 0,
+	"IQD": 3,
+	"ISK": 0,
+	"JOD": 3,
+	"JPY": 0,
+	"KRW": 0,
+	"KWD": 3,
+	"LYD": 3,
+	"OMR": 3,
+	"TND": 3,
+	"UGX": 0,
+	"VND": 0,
+}
+
+// scanUnits is the number of decimal places kept when scanning an amount whose
+// currency is not known yet, the most any currency in currencyMinorUnits has
+const scanUnits = 3
+
+// MinorUnits returns the number of decimal places of the given currency
+func MinorUnits(currency string) int {
+	if units, ok := currencyMinorUnits[strings.ToUpper(currency)]; ok {
+		return units
+	}
+	return DefaultMinorUnits
+}
+
+// Money is an exact amount held as an integer number of minor units of its
+// currency, e.g. cents for USD. An empty currency uses DefaultMinorUnits.
+type Money struct {
+	minor    int64
+	currency string
+	// scanned marks an amount scanned without a currency, held with scanUnits
+	// decimal places until WithCurrency rescales it
+	scanned bool
+}
+
+// NewMoney creates an amount from a number of minor units
+func NewMoney(minor int64, currency string) Money {
+	return Money{
+		minor:    minor,
+		currency: strings.ToUpper(currency),
+	}
+}
+
+// ParseMoney parses a decimal string such as "-1234.50" without going through
+// float64. Digits beyond the currency's minor units are only accepted when they
+// are zero, so "1.50" is a valid JPY amount but "1.5" is not.
+func ParseMoney(s string, currency string) (Money, error) {
+	currency = strings.ToUpper(currency)
+	return parseMoney(s, currency, MinorUnits(currency))
+}
+
+// parseMoney parses s into an amount with the given decimal places
+func parseMoney(s string, currency string, units int) (Money, error) {
+
+	value := strings.TrimSpace(s)
+	negative := false
+	if value != "" && (value[0] == '-' || value[0] == '+') {
+		negative = value[0] == '-'
+		value = value[1:]
+	}
+
+	whole, fraction, _ := strings.Cut(value, ".")
+	if whole == "" && fraction == "" {
+		return Money{}, errors.Errorf("[ParseMoney] invalid amount %q", s)
+	}
+	if !IsDigits(whole) || !IsDigits(fraction) {
+		return Money{}, errors.Errorf("[ParseMoney] invalid amount %q", s)
+	}
+	if len(fraction) > units {
+		if strings.Trim(fraction[units:], "0") != "" {
+			return Money{}, errors.Errorf("[ParseMoney] amount %q has more than %d decimal places for %q", s, units, currency)
+		}
+		fraction = fraction[:units]
+	}
+	fraction += strings.Repeat("0", units-len(fraction))
+
+	digits := strings.TrimLeft(whole+fraction, "0")
+	if digits == "" {
+		return Money{currency: currency}, nil
+	}
+	minor, err := strconv.ParseInt(digits, 10, 64)
+	if err != nil {
+		return Money{}, errors.Wrapf(err, "[ParseMoney] amount %q out of range", s)
+	}
+	if negative {
+		minor = -minor
+	}
+
+	return Money{minor: minor, currency: currency}, nil
+}
+
+// MustParseMoney is like ParseMoney but panics on invalid input. It is meant
+// for constants and tests.
+func MustParseMoney(s string, currency string) Money {
+	m, err := ParseMoney(s, currency)
+	if err != nil {
+		panic(err)
+	}
+	return m
+}
+
+// Minor returns the amount in minor units, or in thousandths for an amount
+// scanned without a currency
+func (m Money) Minor() int64 {
+	return m.minor
+}
+
+// Currency returns the ISO 4217 code, empty when the amount has no currency
+func (m Money) Currency() string {
+	return m.currency
+}
+
+func (m Money) IsZero() bool {
+	return m.minor == 0
+}
+
+// Sign returns -1, 0 or 1 depending on the sign of the amount
+func (m Money) Sign() int {
+	switch {
+	case m.minor < 0:
+		return -1
+	case m.minor > 0:
+		return 1
+	}
+	return 0
+}
+
+func (m Money) Neg() Money {
+	return Money{minor: -m.minor, currency: m.currency, scanned: m.scanned}
+}
+
+func (m Money) Abs() Money {
+	if m.minor < 0 {
+		return m.Neg()
+	}
+	return m
+}
+
+// Add returns the sum of both amounts, which must share the same currency
+func (m Money) Add(other Money) (Money, error) {
+	if m.currency != other.currency {
+		return Money{}, errors.Wrapf(ErrCurrencyMismatch, "[Money.Add] %q and %q", m.currency, other.currency)
+	}
+	if m.scanned != other.scanned {
+		m, other = m.withScanUnits(), other.withScanUnits()
+	}
+	if (other.minor > 0 && m.minor > math.MaxInt64-other.minor) ||
+		(other.minor < 0 && m.minor < math.MinInt64-other.minor) {
+		return Money{}, errors.New("[Money.Add] amount out of range")
+	}
+	return Money{minor: m.minor + other.minor, currency: m.currency, scanned: m.scanned}, nil
+}
+
+// units returns the number of decimal places of the minor units of m
+func (m Money) units() int {
+	if m.scanned {
+		return scanUnits
+	}
+	return MinorUnits(m.currency)
+}
+
+// withScanUnits returns m held with scanUnits decimal places, as if scanned
+func (m Money) withScanUnits() Money {
+	minor := m.minor
+	for units := m.units(); units < scanUnits; units++ {
+		minor *= 10
+	}
+	return Money{minor: minor, currency: m.currency, scanned: true}
+}
+
+// WithCurrency returns the same value in the given currency, rescaling the
+// minor units when both currencies have different decimal places. Digits that
+// do not fit the new currency are rounded half away from zero.
+func (m Money) WithCurrency(currency string) Money {
+	currency = strings.ToUpper(currency)
+	from, to := m.units(), MinorUnits(currency)
+	minor := m.minor
+	for ; from < to; from++ {
+		minor *= 10
+	}
+	for ; from > to; from-- {
+		remainder := minor % 10
+		minor /= 10
+		if remainder >= 5 {
+			minor++
+		} else if remainder <= -5 {
+			minor--
+		}
+	}
+	return Money{minor: minor, currency: currency}
+}
+
+// Convert multiplies the amount by an exchange rate into the given currency,
+// rounding half away from zero to the target currency's minor units.
+func (m Money) Convert(rate *big.Rat, currency string) (Money, error) {
+	if rate == nil || rate.Sign() <= 0 {
+		return Money{}, errors.New("[Money.Convert] exchange rate must be positive")
+	}
+	currency = strings.ToUpper(currency)
+
+	// value = minor * rate * 10^(to - from), all in exact rational arithmetic
+	value := new(big.Rat).Mul(new(big.Rat).SetInt64(m.minor), rate)
+	scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(abs(MinorUnits(currency)-m.units()))), nil)
+	if MinorUnits(currency) > m.units() {
+		value.Mul(value, new(big.Rat).SetInt(scale))
+	} else {
+		value.Quo(value, new(big.Rat).SetInt(scale))
+	}
+
+	// Round half away from zero: (2|num| + den) / 2den, then restore the sign
+	num := new(big.Int).Abs(value.Num())
+	num.Add(num.Lsh(num, 1), value.Denom())
+	rounded := num.Quo(num, new(big.Int).Lsh(value.Denom(), 1))
+	if value.Sign() < 0 {
+		rounded.Neg(rounded)
+	}
+	if !rounded.IsInt64() {
+		return Money{}, errors.New("[Money.Convert] amount out of range")
+	}
+
+	return Money{minor: rounded.Int64(), currency: currency}, nil
+}
+
+// Key identifies the amount and currency, so equal amounts in different
+// currencies never share a key
+func (m Money) Key() string {
+	if m.currency == "" {
+		return m.String()
+	}
+	return m.currency + " " + m.String()
+}
+
+// String formats the amount with exactly the currency's minor units, e.g. "-12.30"
+func (m Money) String() string {
+	units := m.units()
+	// Work on the unsigned value so math.MinInt64 does not overflow
+	magnitude := uint64(m.minor)
+	sign := ""
+	if m.minor < 0 {
+		magnitude = -magnitude
+		sign = "-"
+	}
+
+	digits := strconv.FormatUint(magnitude, 10)
+	if units == 0 {
+		return sign + digits
+	}
+	if len(digits) <= units {
+		digits = strings.Repeat("0", units-len(digits)+1) + digits
+	}
+	return fmt.Sprintf("%s%s.%s", sign, digits[:len(digits)-units], digits[len(digits)-units:])
+}
+
+// MarshalJSON encodes the amount as a JSON number with its exact decimal digits
+func (m Money) MarshalJSON() ([]byte, error) {
+	return []byte(m.String()), nil
+}
+
+// UnmarshalJSON accepts both JSON numbers and quoted decimal strings, keeping
+// the currency already set on m.
+func (m *Money) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(data, []byte("null")) {
+		return nil
+	}
+	value := string(data)
+	if unquoted, err := strconv.Unquote(value); err == nil {
+		value = unquoted
+	}
+	parsed, err := ParseMoney(value, m.currency)
+	if err != nil {
+		return err
+	}
+	*m = parsed
+	return nil
+}
+
+// Value stores the amount as a decimal string so DECIMAL columns receive it exactly
+func (m Money) Value() (driver.Value, error) {
+	return m.String(), nil
+}
+
+// Scan reads a DECIMAL column, keeping the currency already set on m. Without a
+// currency the amount keeps scanUnits decimal places, so that WithCurrency can
+// rescale it once the currency column is read.
+func (m *Money) Scan(src any) error {
+	var value string
+	switch v := src.(type) {
+	case nil:
+		*m = Money{currency: m.currency}
+		return nil
+	case []byte:
+		value = string(v)
+	case string:
+		value = v
+	case int64:
+		value = strconv.FormatInt(v, 10)
+	case float64:
+		value = strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return errors.Errorf("[Money.Scan] unsupported type %T", src)
+	}
+
+	if m.currency == "" {
+		parsed, err := parseMoney(value, "", scanUnits)
+		if err != nil {
+			return err
+		}
+		parsed.scanned = true
+		*m = parsed
+		return nil
+	}
+
+	parsed, err := ParseMoney(value, m.currency)
+	if err != nil {
+		return err
+	}
+	*m = parsed
+	return nil
+}
+
+// IsDigits reports whether s is made of the digits 0 to 9 only
+func IsDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
@@ -0,0 +1,215 @@
+package model_test
+
+import (
+	"encoding/json"
+	"math"
+	"math/big"
+	"testing"
+
+	"github.com/aferryc/yars/model"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseMoney(t *testing.T) {
+	tests := []struct {
+		name          string
+		input         string
+		currency      string
+		expected      int64
+		expectedError bool
+	}{
+		{name: "Two decimals", input: "100.50", expected: 10050},
+		{name: "One decimal", input: "100.5", expected: 10050},
+		{name: "Whole number", input: "100", expected: 10000},
+		{name: "Negative", input: "-0.01", expected: -1},
+		{name: "Explicit plus sign", input: "+7.25", expected: 725},
+		{name: "Surrounding spaces", input: " 12.30 ", expected: 1230},
+		{name: "Leading dot", input: ".75", expected: 75},
+		{name: "Trailing zero digits", input: "1.2500", expected: 125},
+		{name: "Zero decimal currency", input: "1500", currency: "JPY", expected: 1500},
+		{name: "Zero decimal currency with zero fraction", input: "1500.00", currency: "jpy", expected: 1500},
+		{name: "Three decimal currency", input: "1.005", currency: "BHD", expected: 1005},
+		{name: "Sub-cent amount", input: "100.505", expectedError: true},
+		{name: "Fraction on zero decimal currency", input: "1.5", currency: "JPY", expectedError: true},
+		{name: "Empty", input: "", expectedError: true},
+		{name: "Sign only", input: "-", expectedError: true},
+		{name: "Not a number", input: "abc", expectedError: true},
+		{name: "Thousands separator", input: "1,000.00", expectedError: true},
+		{name: "Exponent", input: "1e3", expectedError: true},
+		{name: "Out of range", input: "99999999999999999999", expectedError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := model.ParseMoney(tt.input, tt.currency)
+			if tt.expectedError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, m.Minor())
+		})
+	}
+}
+
+func TestMoney_String(t *testing.T) {
+	t.Run("Pads minor units", func(t *testing.T) {
+		assert.Equal(t, "0.05", model.NewMoney(5, "").String())
+		assert.Equal(t, "-0.05", model.NewMoney(-5, "").String())
+		assert.Equal(t, "0.00", model.Money{}.String())
+	})
+
+	t.Run("Uses the currency minor units", func(t *testing.T) {
+		assert.Equal(t, "1500", model.NewMoney(1500, "JPY").String())
+		assert.Equal(t, "1.005", model.NewMoney(1005, "BHD").String())
+		assert.Equal(t, "1234.56", model.NewMoney(123456, "USD").String())
+	})
+
+	t.Run("Smallest value", func(t *testing.T) {
+		assert.Equal(t, "-92233720368547758.08", model.NewMoney(math.MinInt64, "").String())
+	})
+}
+
+func TestMoney_Add(t *testing.T) {
+	t.Run("Sums exactly", func(t *testing.T) {
+		var total model.Money
+		tenCents := model.MustParseMoney("0.10", "")
+		for i := 0; i < 10; i++ {
+			var err error
+			total, err = total.Add(tenCents)
+			require.NoError(t, err)
+		}
+		assert.Equal(t, model.MustParseMoney("1.00", ""), total)
+	})
+
+	t.Run("Currency mismatch", func(t *testing.T) {
+		_, err := model.NewMoney(100, "USD").Add(model.NewMoney(100, "EUR"))
+		assert.True(t, errors.Is(err, model.ErrCurrencyMismatch))
+	})
+
+	t.Run("Overflow", func(t *testing.T) {
+		_, err := model.NewMoney(math.MaxInt64, "").Add(model.NewMoney(1, ""))
+		assert.Error(t, err)
+	})
+}
+
+func TestMoney_JSON(t *testing.T) {
+	t.Run("Marshals as an exact number", func(t *testing.T) {
+		data, err := json.Marshal(model.Transaction{ID: "tx1", Amount: model.NewMoney(10050, "")})
+		require.NoError(t, err)
+		assert.Contains(t, string(data), `"amount":100.50`)
+	})
+
+	t.Run("Unmarshals numbers and strings", func(t *testing.T) {
+		var stmts []model.BankStatement
+		err := json.Unmarshal([]byte(`[{"amount":100.5},{"amount":"-20.25"},{"amount":null}]`), &stmts)
+		require.NoError(t, err)
+		require.Len(t, stmts, 3)
+		assert.Equal(t, int64(10050), stmts[0].Amount.Minor())
+		assert.Equal(t, int64(-2025), stmts[1].Amount.Minor())
+		assert.True(t, stmts[2].Amount.IsZero())
+	})
+
+	t.Run("Rejects sub-cent amounts", func(t *testing.T) {
+		var stmt model.BankStatement
+		err := json.Unmarshal([]byte(`{"amount":0.001}`), &stmt)
+		assert.Error(t, err)
+	})
+}
+
+func TestMoney_SQL(t *testing.T) {
+	t.Run("Value is a decimal string", func(t *testing.T) {
+		value, err := model.NewMoney(-12345, "").Value()
+		require.NoError(t, err)
+		assert.Equal(t, "-123.45", value)
+	})
+
+	t.Run("Scan supported types", func(t *testing.T) {
+		for _, src := range []any{[]byte("150.75"), "150.75", 150.75} {
+			var m model.Money
+			require.NoError(t, m.Scan(src))
+			assert.Equal(t, model.NewMoney(15075, "USD"), m.WithCurrency("USD"))
+		}
+
+		var m model.Money
+		require.NoError(t, m.Scan(int64(3)))
+		assert.Equal(t, model.NewMoney(300, "USD"), m.WithCurrency("USD"))
+
+		require.NoError(t, m.Scan(nil))
+		assert.True(t, m.IsZero())
+	})
+
+	t.Run("Scan keeps three decimal places until the currency is known", func(t *testing.T) {
+		var m model.Money
+		require.NoError(t, m.Scan([]byte("1.234")))
+		assert.Equal(t, "1.234", m.String())
+		assert.Equal(t, model.NewMoney(1234, "BHD"), m.WithCurrency("BHD"))
+		assert.Equal(t, model.NewMoney(123, "USD"), m.WithCurrency("USD"))
+		assert.Equal(t, model.NewMoney(-1234, "KWD"), m.Neg().WithCurrency("KWD"))
+
+		sum, err := m.Add(model.MustParseMoney("1.00", ""))
+		require.NoError(t, err)
+		assert.Equal(t, model.NewMoney(2234, "OMR"), sum.WithCurrency("OMR"))
+	})
+
+	t.Run("Scan unsupported type", func(t *testing.T) {
+		var m model.Money
+		assert.Error(t, m.Scan(true))
+	})
+}
+
+func TestMoney_WithCurrency(t *testing.T) {
+	t.Run("Keeps the value between currencies", func(t *testing.T) {
+		scanned := model.MustParseMoney("1500.000", "")
+		assert.Equal(t, model.NewMoney(1500, "JPY"), scanned.WithCurrency("jpy"))
+		assert.Equal(t, model.NewMoney(1500000, "BHD"), model.NewMoney(150000, "").WithCurrency("BHD"))
+		assert.Equal(t, model.NewMoney(150000, "USD"), model.NewMoney(150000, "").WithCurrency("USD"))
+	})
+
+	t.Run("Rounds half away from zero", func(t *testing.T) {
+		assert.Equal(t, int64(101), model.NewMoney(1005, "BHD").WithCurrency("USD").Minor())
+		assert.Equal(t, int64(-101), model.NewMoney(-1005, "BHD").WithCurrency("USD").Minor())
+		assert.Equal(t, int64(100), model.NewMoney(1004, "BHD").WithCurrency("USD").Minor())
+	})
+}
+
+func TestMoney_Convert(t *testing.T) {
+	t.Run("Converts between currencies with different minor units", func(t *testing.T) {
+		rate, err := model.ParseFXRate("15500.25")
+		require.NoError(t, err)
+
+		converted, err := model.NewMoney(1000, "USD").Convert(rate, "IDR")
+		require.NoError(t, err)
+		assert.Equal(t, "155002.50", converted.String())
+
+		yen, err := model.NewMoney(1, "USD").Convert(big.NewRat(1495, 10), "JPY")
+		require.NoError(t, err)
+		assert.Equal(t, model.NewMoney(1, "JPY"), yen)
+	})
+
+	t.Run("Rounds half away from zero", func(t *testing.T) {
+		half := big.NewRat(1, 2)
+		converted, err := model.NewMoney(5, "USD").Convert(half, "EUR")
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), converted.Minor())
+
+		converted, err = model.NewMoney(-5, "USD").Convert(half, "EUR")
+		require.NoError(t, err)
+		assert.Equal(t, int64(-3), converted.Minor())
+	})
+
+	t.Run("Rejects non-positive rates", func(t *testing.T) {
+		_, err := model.NewMoney(100, "USD").Convert(big.NewRat(0, 1), "EUR")
+		assert.Error(t, err)
+		_, err = model.NewMoney(100, "USD").Convert(nil, "EUR")
+		assert.Error(t, err)
+	})
+}
+
+func TestMoney_Key(t *testing.T) {
+	assert.Equal(t, "100.00", model.NewMoney(10000, "").Key())
+	assert.Equal(t, "USD 100.00", model.NewMoney(10000, "USD").Key())
+	assert.NotEqual(t, model.NewMoney(10000, "USD").Key(), model.NewMoney(10000, "EUR").Key())
+}
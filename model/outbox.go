@@ -0,0 +1,29 @@
+package model
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// OutboxEvent is an event stored in the same database transaction as the data
+// it describes, and published to Kafka by the outbox relay once committed
+type OutboxEvent struct {
+	ID       int64
+	Topic    string
+	Envelope EventEnvelope
+	Payload  json.RawMessage
+}
+
+// NewOutboxEvent serializes event to be published on topic with envelope
+func NewOutboxEvent(topic string, envelope EventEnvelope, event any) (OutboxEvent, error) {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return OutboxEvent{}, fmt.Errorf("failed to serialize %s event: %w", envelope.EventType, err)
+	}
+
+	return OutboxEvent{
+		Topic:    topic,
+		Envelope: envelope,
+		Payload:  payload,
+	}, nil
+}
@@ -0,0 +1,126 @@
+package model
+
+import "time"
+
+// Fields of the CSV files. Transaction files use id, amount, type,
+// transaction_time and currency, bank statement files id, amount, date,
+// reference and currency.
+const (
+	FieldID              = "id"
+	FieldAmount          = "amount"
+	FieldType            = "type"
+	FieldTransactionTime = "transaction_time"
+	FieldDate            = "date"
+	FieldReference       = "reference"
+	FieldCurrency        = "currency"
+	// FieldDebit and FieldCredit hold the amounts of SignConventionSplit
+	FieldDebit  = "debit"
+	FieldCredit = "credit"
+	// FieldIndicator holds the debit or credit indicator of
+	// SignConventionIndicator
+	FieldIndicator = "indicator"
+)
+
+// Sign conventions of the amounts of a CSV file
+const (
+	// SignConventionSigned reads the amount with its sign, debits being
+	// negative
+	SignConventionSigned = "signed"
+	// SignConventionIndicator reads unsigned amounts, negated when the
+	// indicator column holds one of the debit indicators
+	SignConventionIndicator = "indicator"
+	// SignConventionSplit reads debits and credits from separate columns
+	SignConventionSplit = "split"
+)
+
+// IngestionProfile describes the CSV exports of a bank. It is selected by the
+// bank name of the compilation request, files of banks without a profile are
+// read with DefaultTransactionFormat and DefaultBankStatementFormat.
+type IngestionProfile struct {
+	BankName string `json:"bankName"`
+	// Transactions and BankStatements are the formats of each file, the
+	// default format is used when one is nil
+	Transactions   *CSVFormat `json:"transactions,omitempty"`
+	BankStatements *CSVFormat `json:"bankStatements,omitempty"`
+	UpdatedAt      time.Time  `json:"updatedAt"`
+}
+
+// CSVFormat is the layout of a CSV file
+type CSVFormat struct {
+	// Delimiter separates the fields, a comma when empty
+	Delimiter string `json:"delimiter,omitempty"`
+	// Columns maps each field to its column. Fields without a column are
+	// left empty.
+	Columns map[string]Column `json:"columns"`
+	// DateLayouts are the Go time layouts dates are parsed with, tried in
+	// order, e.g. "02.01.2006"
+	DateLayouts []string `json:"dateLayouts"`
+	// DecimalSeparator is a period when empty
+	DecimalSeparator string `json:"decimalSeparator,omitempty"`
+	// ThousandsSeparator is removed from amounts, amounts with one are
+	// rejected when empty
+	ThousandsSeparator string `json:"thousandsSeparator,omitempty"`
+	// SignConvention is SignConventionSigned when empty
+	SignConvention string `json:"signConvention,omitempty"`
+	// DebitIndicators are the indicator values marking a debit, compared
+	// ignoring case
+	DebitIndicators []string `json:"debitIndicators,omitempty"`
+}
+
+// Column locates a CSV column by its header name, or by its zero-based index
+// when the header is not stable
+type Column struct {
+	Name  string `json:"name,omitempty"`
+	Index *int   `json:"index,omitempty"`
+}
+
+// ColumnIndex locates a column by index
+func ColumnIndex(index int) Column {
+	return Column{Index: &index}
+}
+
+// DefaultTransactionFormat is the layout of transaction files without a
+// profile: id,amount,type,transaction_time with an optional currency
+func DefaultTransactionFormat() CSVFormat {
+	return CSVFormat{
+		Columns: map[string]Column{
+			FieldID:              ColumnIndex(0),
+			FieldAmount:          ColumnIndex(1),
+			FieldType:            ColumnIndex(2),
+			FieldTransactionTime: ColumnIndex(3),
+			FieldCurrency:        ColumnIndex(4),
+		},
+		DateLayouts: []string{"2006-01-02T15:04:05Z"},
+	}
+}
+
+// DefaultBankStatementFormat is the layout of bank statement files without a
+// profile: id,amount,date with an optional reference and currency
+func DefaultBankStatementFormat() CSVFormat {
+	return CSVFormat{
+		Columns: map[string]Column{
+			FieldID:        ColumnIndex(0),
+			FieldAmount:    ColumnIndex(1),
+			FieldDate:      ColumnIndex(2),
+			FieldReference: ColumnIndex(3),
+			FieldCurrency:  ColumnIndex(4),
+		},
+		DateLayouts: []string{"2006-01-02"},
+	}
+}
+
+// TransactionFormat returns the format of the transaction files of the bank
+func (p IngestionProfile) TransactionFormat() CSVFormat {
+	if p.Transactions == nil {
+		return DefaultTransactionFormat()
+	}
+	return *p.Transactions
+}
+
+// BankStatementFormat returns the format of the statement files of the bank
+func (p IngestionProfile) BankStatementFormat() CSVFormat {
+	if p.BankStatements == nil {
+		return DefaultBankStatementFormat()
+	}
+	return *p.BankStatements
+}
@@ -1,20 +1,28 @@
 package model
 
-import (
-	"fmt"
-	"time"
-)
+import "time"
 
-const amountFormat = "%.2f"
 const TransactionFile = "transactions.csv"
 const BankStatementFile = "bank_statement.csv"
 
 type Transaction struct {
 	ID              string    `json:"id"`
-	Amount          float64   `json:"amount"`
+	Amount          Money     `json:"amount"`
 	TransactionTime time.Time `json:"date"`
 	Type            string    `json:"type"` // e.g., "DEBIT" or "CREDIT"
 	Description     string    `json:"description"`
+	// TaskID and BankName identify the upload the transaction was ingested from
+	TaskID   string `json:"task_id,omitempty"`
+	BankName string `json:"bank_name,omitempty"`
+}
+
+// SignedAmount returns the amount as it should appear on the bank statement,
+// debits are negative and credits are positive.
+func (t Transaction) SignedAmount() Money {
+	if t.Type == "DEBIT" {
+		return t.Amount.Neg()
+	}
+	return t.Amount
 }
 
 type TransactionList struct {
@@ -30,15 +38,9 @@ func (t TransactionList) Precompile() TransactionPrecompiled {
 	countlist := make(map[string]int)
 	list := make(map[string][]Transaction)
 	for _, tx := range t.Transactions {
-		amount := tx.Amount
-		if tx.Type == "DEBIT" {
-			amount = -amount
-		}
-
-		// Normalize the amount to a string with two decimal places
-		// This is important for consistent string keys in the map
-		// and to avoid floating-point precision issues
-		amountStr := fmt.Sprintf(amountFormat, amount)
+		// Money formats with a fixed number of decimal places, so equal
+		// amounts in the same currency always produce the same key
+		amountStr := tx.SignedAmount().Key()
 		countlist[amountStr]++
 		list[amountStr] = append(list[amountStr], tx)
 	}
@@ -51,10 +53,21 @@ func (t TransactionList) Precompile() TransactionPrecompiled {
 
 type BankStatement struct {
 	ID        string    `json:"id"`
-	Amount    float64   `json:"amount"`
+	Amount    Money     `json:"amount"`
 	Date      time.Time `json:"date"`
 	Reference string    `json:"reference"`
-	BankName  string    `json:"bank_name"`
+	// Narrative is the free text the bank describes the entry with, such as
+	// the :86: field of MT940
+	Narrative string `json:"narrative,omitempty"`
+	// EndToEndID is the reference the payer gave the payment, such as the
+	// EndToEndId of ISO 20022
+	EndToEndID string `json:"end_to_end_id,omitempty"`
+	// Counterparty is the name of the payer of a credit or the payee of a
+	// debit
+	Counterparty string `json:"counterparty,omitempty"`
+	BankName     string `json:"bank_name"`
+	// TaskID identifies the upload the statement was ingested from
+	TaskID string `json:"task_id,omitempty"`
 }
 
 type BankStatementList struct {
@@ -70,7 +83,7 @@ func (t BankStatementList) Precompile() BankStatementPrecompiled {
 	countlist := make(map[string]int)
 	list := make(map[string][]BankStatement)
 	for _, tx := range t.BankStatements {
-		amountStr := fmt.Sprintf(amountFormat, tx.Amount)
+		amountStr := tx.Amount.Key()
 		countlist[amountStr]++
 		list[amountStr] = append(list[amountStr], tx)
 	}
@@ -81,11 +94,37 @@ func (t BankStatementList) Precompile() BankStatementPrecompiled {
 	}
 }
 
+const (
+	// MatchOneToOne pairs a single internal transaction with a single bank line
+	MatchOneToOne = "ONE_TO_ONE"
+	// MatchManyToOne groups several internal transactions settled as one bank line
+	MatchManyToOne = "MANY_TO_ONE"
+	// MatchOneToMany groups one internal transaction split across several bank lines
+	MatchOneToMany = "ONE_TO_MANY"
+)
+
+// Match links internal transactions to the bank statements they were
+// reconciled with, together with the rule that produced the match.
+type Match struct {
+	Rule           string
+	Type           string
+	Transactions   []Transaction
+	BankStatements []BankStatement
+	// Difference is the bank amount less the transaction amount, in the bank
+	// currency, of a one-to-one match. It is nil for groups and when no FX rate
+	// converts the transaction amount.
+	Difference *Money
+}
+
 type ReconciliationSummary struct {
 	UnmatchedInternal []Transaction
 	UnmatchedBank     []BankStatement
+	Matches           []Match
 	TotalMatched      int
-	TotalDiscrepancy  float64
+	TotalDiscrepancy  Money
 	TotalTransaction  int
 	TaskID            string
+	// MissingRates lists the currencies of the unmatched records left out of
+	// TotalDiscrepancy for lack of an FX rate, the total being incomplete
+	MissingRates []string
 }
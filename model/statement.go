@@ -0,0 +1,26 @@
+package model
+
+import "time"
+
+// Statement is an account statement of a bank file such as MT940, holding its
+// entries and the balances they should add up to
+type Statement struct {
+	// Reference identifies the statement, :20: in MT940
+	Reference string `json:"reference"`
+	// Account is the account the statement is for, :25: in MT940
+	Account string `json:"account"`
+	// Number is the statement and page number, :28C: in MT940
+	Number         string          `json:"number,omitempty"`
+	OpeningBalance Balance         `json:"openingBalance"`
+	ClosingBalance Balance         `json:"closingBalance"`
+	Entries        []BankStatement `json:"entries"`
+	// TaskID and BankName identify the upload the statement was ingested from
+	TaskID   string `json:"task_id,omitempty"`
+	BankName string `json:"bank_name,omitempty"`
+}
+
+// Balance is the balance of an account at the end of a day
+type Balance struct {
+	Date   time.Time `json:"date"`
+	Amount Money     `json:"amount"`
+}
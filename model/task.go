@@ -0,0 +1,65 @@
+package model
+
+import "time"
+
+// TaskStatus is the stage a reconciliation task has reached
+type TaskStatus string
+
+const (
+	// TaskCreated is set when the upload URLs are generated
+	TaskCreated TaskStatus = "CREATED"
+	// TaskUploaded is set once the files are uploaded and compilation is requested
+	TaskUploaded TaskStatus = "UPLOADED"
+	// TaskCompiling is set while the compiler ingests the uploaded files
+	TaskCompiling TaskStatus = "COMPILING"
+	// TaskCompiled is set once the files are ingested
+	TaskCompiled TaskStatus = "COMPILED"
+	// TaskReconciling is set while the records are being matched
+	TaskReconciling TaskStatus = "RECONCILING"
+	// TaskCompleted is set once the reconciliation results are stored
+	TaskCompleted TaskStatus = "COMPLETED"
+	// TaskFailed is set when any stage fails, with the error message
+	TaskFailed TaskStatus = "FAILED"
+)
+
+// taskTransitions lists the states each state can be reached from. A stage
+// may be entered again when its event is redelivered, and a failed task may
+// be retried from the stage that failed.
+var taskTransitions = map[TaskStatus][]TaskStatus{
+	TaskUploaded:    {TaskCreated, TaskFailed},
+	TaskCompiling:   {TaskUploaded, TaskCompiling, TaskFailed},
+	TaskCompiled:    {TaskCompiling},
+	TaskReconciling: {TaskCompiled, TaskReconciling, TaskFailed},
+	TaskCompleted:   {TaskReconciling},
+	TaskFailed:      {TaskCreated, TaskUploaded, TaskCompiling, TaskCompiled, TaskReconciling, TaskFailed},
+}
+
+// PreviousStates returns the states a task may move to s from
+func (s TaskStatus) PreviousStates() []TaskStatus {
+	return taskTransitions[s]
+}
+
+// CanTransition reports whether a task in from may move to to
+func CanTransition(from, to TaskStatus) bool {
+	for _, previous := range taskTransitions[to] {
+		if previous == from {
+			return true
+		}
+	}
+	return false
+}
+
+// IsFinished reports whether the task has stopped, successfully or not
+func (s TaskStatus) IsFinished() bool {
+	return s == TaskCompleted || s == TaskFailed
+}
+
+// ReconTask tracks a reconciliation from upload to results
+type ReconTask struct {
+	TaskID     string
+	Status     TaskStatus
+	Error      string
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+	FinishedAt *time.Time
+}
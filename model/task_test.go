@@ -0,0 +1,36 @@
+package model_test
+
+import (
+	"testing"
+
+	"github.com/aferryc/yars/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCanTransition(t *testing.T) {
+	tests := []struct {
+		from     model.TaskStatus
+		to       model.TaskStatus
+		expected bool
+	}{
+		{from: model.TaskCreated, to: model.TaskUploaded, expected: true},
+		{from: model.TaskUploaded, to: model.TaskCompiling, expected: true},
+		{from: model.TaskCompiling, to: model.TaskCompiled, expected: true},
+		{from: model.TaskCompiled, to: model.TaskReconciling, expected: true},
+		{from: model.TaskReconciling, to: model.TaskCompleted, expected: true},
+		{from: model.TaskCompiling, to: model.TaskFailed, expected: true},
+		{from: model.TaskCompiling, to: model.TaskCompiling, expected: true},
+		{from: model.TaskFailed, to: model.TaskCompiling, expected: true},
+		{from: model.TaskCreated, to: model.TaskCompiling, expected: false},
+		{from: model.TaskUploaded, to: model.TaskReconciling, expected: false},
+		{from: model.TaskCompleted, to: model.TaskReconciling, expected: false},
+		{from: model.TaskCompleted, to: model.TaskFailed, expected: false},
+		{from: model.TaskFailed, to: model.TaskCompleted, expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.from)+" to "+string(tt.to), func(t *testing.T) {
+			assert.Equal(t, tt.expected, model.CanTransition(tt.from, tt.to))
+		})
+	}
+}
@@ -2,13 +2,18 @@ package gcs
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
+	"log"
 	"net/http"
-	"os"
 	"time"
 
 	"cloud.google.com/go/storage"
+	"github.com/aferryc/yars/internal/config"
+	"github.com/aferryc/yars/model"
+	"github.com/aferryc/yars/repository/rangereader"
+	"google.golang.org/api/googleapi"
 )
 
 type UploadURLInfo struct {
@@ -20,21 +25,32 @@ type UploadURLInfo struct {
 
 type GCSRepo struct {
 	bucketName string
+	cfg        config.GCSConfig
 	client     *storage.Client
 }
 
-func NewGCSRepository(bucketName string, client *storage.Client) (*GCSRepo, error) {
+func NewGCSRepository(bucketName string, cfg config.GCSConfig, client *storage.Client) (*GCSRepo, error) {
 	return &GCSRepo{
 		bucketName: bucketName,
+		cfg:        cfg,
 		client:     client,
 	}, nil
 }
 
+// emulatorURL is the address clients reach the emulator at, empty when GCS
+// itself is used
+func (u *GCSRepo) emulatorURL() string {
+	if u.cfg.EmulatorPublicURL != "" {
+		return u.cfg.EmulatorPublicURL
+	}
+	return u.cfg.EmulatorHost
+}
+
 func (u *GCSRepo) GenerateUploadURL(objectName string, contentType string, expires time.Time) (string, error) {
 	// Check if we're using the emulator
-	if emulatorHost := os.Getenv("STORAGE_EMULATOR_HOST"); emulatorHost != "" {
+	if emulatorURL := u.emulatorURL(); emulatorURL != "" {
 		// For emulator, just construct a direct URL without signing
-		return fmt.Sprintf("%s/upload/storage/v1/b/%s/o?name=%s&uploadType=media", "http://localhost:4443", u.bucketName, objectName), nil
+		return fmt.Sprintf("%s/upload/storage/v1/b/%s/o?name=%s&uploadType=media", emulatorURL, u.bucketName, objectName), nil
 	}
 
 	opts := &storage.SignedURLOptions{
@@ -58,9 +74,9 @@ func (u *GCSRepo) GenerateUploadURL(objectName string, contentType string, expir
 
 func (u *GCSRepo) GenerateDownloadURL(objectName string) (string, error) {
 	// Check if we're using the emulator
-	if emulatorHost := os.Getenv("STORAGE_EMULATOR_HOST"); emulatorHost != "" {
+	if emulatorURL := u.emulatorURL(); emulatorURL != "" {
 		// For emulator, just construct a direct URL without signing
-		return fmt.Sprintf("%s/storage/v1/b/%s/o/%s?alt=media", "http://localhost:4443", u.bucketName, objectName), nil
+		return fmt.Sprintf("%s/storage/v1/b/%s/o/%s?alt=media", emulatorURL, u.bucketName, objectName), nil
 	}
 	opts := &storage.SignedURLOptions{
 		GoogleAccessID: "some@example.com",
@@ -77,49 +93,108 @@ func (u *GCSRepo) GenerateDownloadURL(objectName string) (string, error) {
 	return url, nil
 }
 
-func (u *GCSRepo) DownloadFromBucket(ctx context.Context, objectName string) (*os.File, error) {
-	tempFile, err := os.CreateTemp("", "download-*.csv")
-	if err != nil {
-		return nil, fmt.Errorf("error creating temp file: %w", err)
+// UploadMethod is POST for the emulator upload endpoint and PUT for signed URLs
+func (u *GCSRepo) UploadMethod() string {
+	if u.emulatorURL() != "" {
+		return http.MethodPost
 	}
-	defer func() {
-		if err != nil {
-			tempFile.Close()
-			os.Remove(tempFile.Name())
-		}
-	}()
-
-	// Generate download URL instead of using bucket directly
-	downloadURL := fmt.Sprintf("%s/storage/v1/b/%s/o/%s?alt=media", "http://bucket:4443", u.bucketName, objectName)
+	return http.MethodPut
+}
 
-	// Create HTTP request
-	req, err := http.NewRequestWithContext(ctx, "GET", downloadURL, nil)
-	if err != nil {
-		return nil, fmt.Errorf("error creating request: %w", err)
+// NewReader streams the object through the client. When the connection
+// drops, the read resumes from the first byte not read yet, pinned to the
+// generation first read so that a replaced object fails with
+// rangereader.ErrObjectChanged.
+func (u *GCSRepo) NewReader(ctx context.Context, objectName string) (io.ReadCloser, error) {
+	object := u.client.Bucket(u.bucketName).Object(objectName)
+	reader, err := object.NewRangeReader(ctx, 0, -1)
+	if errors.Is(err, storage.ErrObjectNotExist) {
+		return nil, model.ErrObjectNotFound
 	}
-
-	// Send the request
-	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("error downloading file: %w", err)
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("download failed with status code: %d", resp.StatusCode)
-	}
+	return &objectReader{
+		ctx:        ctx,
+		object:     object.If(storage.Conditions{GenerationMatch: reader.Attrs.Generation}),
+		maxResumes: rangereader.DefaultMaxResumes,
+		reader:     reader,
+	}, nil
+}
+
+// objectReader reads an object, resuming with range reads when the
+// connection drops
+type objectReader struct {
+	ctx        context.Context
+	object     *storage.ObjectHandle
+	maxResumes int
+
+	reader  *storage.Reader
+	offset  int64
+	resumes int
+}
 
-	// Copy content to temp file
-	if _, err = io.Copy(tempFile, resp.Body); err != nil {
-		return nil, fmt.Errorf("error copying object content: %w", err)
+func (r *objectReader) Read(p []byte) (int, error) {
+	for {
+		n, err := r.reader.Read(p)
+		r.offset += int64(n)
+		if err == nil || errors.Is(err, io.EOF) {
+			return n, err
+		}
+		if n > 0 {
+			// Return what was read, the next call hits the error again
+			return n, nil
+		}
+		if ctxErr := r.ctx.Err(); ctxErr != nil {
+			return 0, ctxErr
+		}
+		if r.resumes >= r.maxResumes {
+			return 0, fmt.Errorf("error reading object after %d resumes: %w", r.resumes, err)
+		}
+
+		r.resumes++
+		log.Printf("Error reading object at byte %d, resuming (%d/%d): %v", r.offset, r.resumes, r.maxResumes, err)
+		r.reader.Close()
+		if err := r.wait(); err != nil {
+			return 0, err
+		}
+		if err := r.open(); err != nil {
+			return 0, err
+		}
 	}
+}
+
+func (r *objectReader) Close() error {
+	return r.reader.Close()
+}
 
-	// Rewind file to beginning
-	if _, err = tempFile.Seek(0, 0); err != nil {
-		return nil, fmt.Errorf("error rewinding temp file: %w", err)
+// open reads the object from the current offset
+func (r *objectReader) open() error {
+	reader, err := r.object.NewRangeReader(r.ctx, r.offset, -1)
+	var apiErr *googleapi.Error
+	switch {
+	case err == nil:
+		r.reader = reader
+		return nil
+	case errors.Is(err, storage.ErrObjectNotExist),
+		errors.As(err, &apiErr) && apiErr.Code == http.StatusPreconditionFailed:
+		return rangereader.ErrObjectChanged
+	default:
+		return fmt.Errorf("error downloading file: %w", err)
 	}
+}
+
+func (r *objectReader) wait() error {
+	timer := time.NewTimer(rangereader.ResumeBackoff * time.Duration(r.resumes))
+	defer timer.Stop()
 
-	return tempFile, nil
+	select {
+	case <-r.ctx.Done():
+		return r.ctx.Err()
+	case <-timer.C:
+		return nil
+	}
 }
 
 func (u *GCSRepo) Close() error {
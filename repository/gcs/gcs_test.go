@@ -0,0 +1,174 @@
+package gcs_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/storage"
+	"github.com/aferryc/yars/internal/config"
+	"github.com/aferryc/yars/model"
+	"github.com/aferryc/yars/repository/gcs"
+	"github.com/aferryc/yars/repository/rangereader"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/api/option"
+)
+
+const content = "id,amount,date\n101,500.25,2023-01-15\n102,750.50,2023-01-16\n"
+
+// fakeGCS serves the object of generation through the XML API, dropping the
+// connection after dropAfter bytes for the first drops requests. The
+// generations read, whether requested or required by a condition, are
+// recorded.
+type fakeGCS struct {
+	mu          sync.Mutex
+	drops       int
+	dropAfter   int
+	generation  int64
+	ranges      []string
+	generations []string
+}
+
+func (s *fakeGCS) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	s.ranges = append(s.ranges, r.Header.Get("Range"))
+	s.generations = append(s.generations, r.URL.Query().Get("generation")+r.Header.Get("X-Goog-If-Generation-Match"))
+	drop := s.drops > 0
+	s.drops--
+	generation := strconv.FormatInt(s.generation, 10)
+	s.mu.Unlock()
+
+	if r.URL.Path != "/yars-bucket/uploads/bank_statement.csv" {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	// A read of another generation finds no object, while a generation
+	// condition fails
+	if requested := r.URL.Query().Get("generation"); requested != "" && requested != generation {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	if match := r.Header.Get("X-Goog-If-Generation-Match"); match != "" && match != generation {
+		w.WriteHeader(http.StatusPreconditionFailed)
+		return
+	}
+
+	body := content
+	status := http.StatusOK
+	if rangeHeader := r.Header.Get("Range"); rangeHeader != "" {
+		offset, _ := strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(rangeHeader, "bytes="), "-"))
+		body = content[offset:]
+		status = http.StatusPartialContent
+		w.Header().Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", offset, len(content)-1, len(content)))
+	}
+
+	w.Header().Set("X-Goog-Generation", generation)
+	w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+	w.WriteHeader(status)
+	if !drop {
+		_, _ = io.WriteString(w, body)
+		return
+	}
+
+	// Send part of the body, then close the connection mid-response
+	_, _ = io.WriteString(w, body[:s.dropAfter])
+	w.(http.Flusher).Flush()
+	conn, _, err := w.(http.Hijacker).Hijack()
+	if err == nil {
+		conn.Close()
+	}
+}
+
+func newRepository(t *testing.T, server *httptest.Server) *gcs.GCSRepo {
+	client, err := storage.NewClient(context.Background(), option.WithEndpoint(server.URL+"/storage/v1/"), option.WithoutAuthentication())
+	require.NoError(t, err)
+	repo, err := gcs.NewGCSRepository("yars-bucket", config.GCSConfig{EmulatorHost: server.URL}, client)
+	require.NoError(t, err)
+	return repo
+}
+
+func TestGCSRepo_NewReader(t *testing.T) {
+	t.Run("Resumes from the last byte read of the same generation", func(t *testing.T) {
+		// Setup
+		handler := &fakeGCS{drops: 2, dropAfter: 10, generation: 7}
+		server := httptest.NewServer(handler)
+		defer server.Close()
+		repo := newRepository(t, server)
+
+		// Execute
+		reader, err := repo.NewReader(context.Background(), "uploads/bank_statement.csv")
+		require.NoError(t, err)
+		defer reader.Close()
+		read, err := io.ReadAll(reader)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, content, string(read))
+		assert.Equal(t, []string{"", "bytes=10-", "bytes=20-"}, handler.ranges)
+		assert.Equal(t, []string{"", "7", "7"}, handler.generations)
+	})
+
+	t.Run("Fails when the object changed", func(t *testing.T) {
+		// Setup
+		handler := &fakeGCS{drops: 1, dropAfter: 10, generation: 7}
+		server := httptest.NewServer(handler)
+		defer server.Close()
+		repo := newRepository(t, server)
+
+		reader, err := repo.NewReader(context.Background(), "uploads/bank_statement.csv")
+		require.NoError(t, err)
+		defer reader.Close()
+		handler.mu.Lock()
+		handler.generation = 8
+		handler.mu.Unlock()
+
+		// Execute
+		_, err = io.ReadAll(reader)
+
+		// Assert
+		assert.ErrorIs(t, err, rangereader.ErrObjectChanged)
+		assert.Equal(t, []string{"", "7", "7"}, handler.generations)
+	})
+
+	t.Run("Missing object", func(t *testing.T) {
+		// Setup
+		server := httptest.NewServer(&fakeGCS{generation: 7})
+		defer server.Close()
+		repo := newRepository(t, server)
+
+		// Execute
+		reader, err := repo.NewReader(context.Background(), "uploads/missing.csv")
+
+		// Assert
+		assert.ErrorIs(t, err, model.ErrObjectNotFound)
+		assert.Nil(t, reader)
+	})
+}
+
+func TestGCSRepo_EmulatorURLs(t *testing.T) {
+	// Setup
+	repo, err := gcs.NewGCSRepository("yars-bucket", config.GCSConfig{
+		EmulatorHost:      "http://bucket:4443",
+		EmulatorPublicURL: "http://localhost:4443",
+	}, nil)
+	require.NoError(t, err)
+
+	// Execute
+	uploadURL, uploadErr := repo.GenerateUploadURL("uploads/bank_statement.csv", "text/csv", time.Now().Add(time.Hour))
+	downloadURL, downloadErr := repo.GenerateDownloadURL("uploads/bank_statement.csv")
+
+	// Assert
+	require.NoError(t, uploadErr)
+	require.NoError(t, downloadErr)
+	assert.Equal(t, "http://localhost:4443/upload/storage/v1/b/yars-bucket/o?name=uploads/bank_statement.csv&uploadType=media", uploadURL)
+	assert.Equal(t, "http://localhost:4443/storage/v1/b/yars-bucket/o/uploads/bank_statement.csv?alt=media", downloadURL)
+	assert.Equal(t, http.MethodPost, repo.UploadMethod())
+}
@@ -5,15 +5,24 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"sort"
 
+	"github.com/aferryc/yars/model"
 	"github.com/twmb/franz-go/pkg/kgo"
 )
 
+// Client produces records. It is implemented by *kgo.Client, and by
+// *memory.Broker to run without Kafka.
+type Client interface {
+	ProduceSync(ctx context.Context, rs ...*kgo.Record) kgo.ProduceResults
+	Close()
+}
+
 type KafkaProducer struct {
-	client *kgo.Client
+	client Client
 }
 
-func NewKafkaRepository(client *kgo.Client) *KafkaProducer {
+func NewKafkaRepository(client Client) *KafkaProducer {
 	return &KafkaProducer{
 		client: client,
 	}
@@ -59,6 +68,40 @@ func (p *KafkaProducer) Publish(ctx context.Context, topic string, key string, m
 	return nil
 }
 
+func (p *KafkaProducer) PublishEvent(ctx context.Context, topic string, envelope model.EventEnvelope, event any) error {
+	if topic == "" {
+		return errors.New("topic cannot be empty")
+	}
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to serialize event: %v", err)
+	}
+
+	headers := envelope.Headers()
+	keys := make([]string, 0, len(headers))
+	for key := range headers {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	record := &kgo.Record{
+		Topic:   topic,
+		Key:     []byte(envelope.TaskID),
+		Value:   payload,
+		Headers: make([]kgo.RecordHeader, 0, len(keys)),
+	}
+	for _, key := range keys {
+		record.Headers = append(record.Headers, kgo.RecordHeader{Key: key, Value: []byte(headers[key])})
+	}
+
+	if err := p.client.ProduceSync(ctx, record).FirstErr(); err != nil {
+		return fmt.Errorf("failed to publish event: %v", err)
+	}
+
+	return nil
+}
+
 func (p *KafkaProducer) Close() error {
 	if p.client != nil {
 		p.client.Close()
@@ -0,0 +1,186 @@
+package local
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/aferryc/yars/model"
+)
+
+// Operations a signed URL can be used for
+const (
+	OperationUpload   = "upload"
+	OperationDownload = "download"
+)
+
+// RoutePrefix is the path the YARS server serves signed URLs under
+const RoutePrefix = "/storage"
+
+const downloadURLExpiry = 15 * time.Minute
+
+// LocalStorage stores objects as files under a directory, to run without
+// GCS. Objects are uploaded and downloaded through URLs served by the YARS
+// server, signed so they cannot be forged, altered or used once expired.
+type LocalStorage struct {
+	dir        string
+	baseURL    string
+	signingKey []byte
+}
+
+// NewLocalStorage creates the storage, creating dir if needed. baseURL is the
+// address the YARS server is reached at by the clients of the URLs.
+func NewLocalStorage(dir, baseURL string, signingKey []byte) (*LocalStorage, error) {
+	if len(signingKey) == 0 {
+		return nil, errors.New("signing key is required")
+	}
+	if _, err := url.Parse(baseURL); err != nil {
+		return nil, fmt.Errorf("invalid base URL: %w", err)
+	}
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, fmt.Errorf("error creating storage directory: %w", err)
+	}
+
+	return &LocalStorage{
+		dir:        dir,
+		baseURL:    baseURL,
+		signingKey: signingKey,
+	}, nil
+}
+
+func (l *LocalStorage) GenerateUploadURL(objectName string, contentType string, expires time.Time) (string, error) {
+	return l.signedURL(OperationUpload, objectName, expires)
+}
+
+func (l *LocalStorage) GenerateDownloadURL(objectName string) (string, error) {
+	return l.signedURL(OperationDownload, objectName, time.Now().Add(downloadURLExpiry))
+}
+
+// UploadMethod is PUT, although the server accepts POST as well
+func (l *LocalStorage) UploadMethod() string {
+	return http.MethodPut
+}
+
+// NewReader opens the stored file
+func (l *LocalStorage) NewReader(ctx context.Context, objectName string) (io.ReadCloser, error) {
+	file, err := l.Open(objectName)
+	if err != nil {
+		return nil, err
+	}
+	return file, nil
+}
+
+// Open opens the object for reading, or returns model.ErrObjectNotFound
+func (l *LocalStorage) Open(objectName string) (*os.File, error) {
+	path, err := l.path(objectName)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %s", model.ErrObjectNotFound, objectName)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error opening object: %w", err)
+	}
+	return file, nil
+}
+
+// Write stores the object, replacing any previous version. Readers never see a
+// partially written object.
+func (l *LocalStorage) Write(objectName string, r io.Reader) (err error) {
+	path, err := l.path(objectName)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return fmt.Errorf("error creating object directory: %w", err)
+	}
+
+	tempFile, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return fmt.Errorf("error creating temp file: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			tempFile.Close()
+			os.Remove(tempFile.Name())
+		}
+	}()
+
+	if _, err = io.Copy(tempFile, r); err != nil {
+		return fmt.Errorf("error writing object: %w", err)
+	}
+	if err = tempFile.Close(); err != nil {
+		return fmt.Errorf("error closing object: %w", err)
+	}
+	if err = os.Rename(tempFile.Name(), path); err != nil {
+		return fmt.Errorf("error storing object: %w", err)
+	}
+	return nil
+}
+
+// VerifyURL checks the expiry and signature found in the query of a URL
+// against the operation it is used for and the object of its path
+func (l *LocalStorage) VerifyURL(operation, objectName, expires, signature string) error {
+	expiresAt, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil {
+		return fmt.Errorf("%w: invalid expiry", model.ErrInvalidSignedURL)
+	}
+
+	given, err := hex.DecodeString(signature)
+	if err != nil || !hmac.Equal(given, l.sign(operation, objectName, expiresAt)) {
+		return model.ErrInvalidSignedURL
+	}
+
+	if time.Now().Unix() > expiresAt {
+		return model.ErrSignedURLExpired
+	}
+	return nil
+}
+
+func (l *LocalStorage) signedURL(operation, objectName string, expires time.Time) (string, error) {
+	if _, err := l.path(objectName); err != nil {
+		return "", err
+	}
+
+	u, err := url.Parse(l.baseURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid base URL: %w", err)
+	}
+	u = u.JoinPath(RoutePrefix, objectName)
+	u.RawQuery = url.Values{
+		"expires":   {strconv.FormatInt(expires.Unix(), 10)},
+		"signature": {hex.EncodeToString(l.sign(operation, objectName, expires.Unix()))},
+	}.Encode()
+
+	return u.String(), nil
+}
+
+func (l *LocalStorage) sign(operation, objectName string, expires int64) []byte {
+	mac := hmac.New(sha256.New, l.signingKey)
+	fmt.Fprintf(mac, "%s\n%s\n%d", operation, objectName, expires)
+	return mac.Sum(nil)
+}
+
+// path returns the file of the object, rejecting names that would resolve
+// outside of the storage directory
+func (l *LocalStorage) path(objectName string) (string, error) {
+	name := filepath.FromSlash(objectName)
+	if objectName == "" || !filepath.IsLocal(name) {
+		return "", fmt.Errorf("%w: %q", model.ErrInvalidObjectName, objectName)
+	}
+	return filepath.Join(l.dir, name), nil
+}
@@ -0,0 +1,168 @@
+package local_test
+
+import (
+	"context"
+	"io"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aferryc/yars/model"
+	"github.com/aferryc/yars/repository/local"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newStorage(t *testing.T) *local.LocalStorage {
+	t.Helper()
+	storage, err := local.NewLocalStorage(t.TempDir(), "http://localhost:8080", []byte("secret"))
+	require.NoError(t, err)
+	return storage
+}
+
+// query returns the path, expiry and signature of a signed URL
+func query(t *testing.T, signedURL string) (string, string, string) {
+	t.Helper()
+	u, err := url.Parse(signedURL)
+	require.NoError(t, err)
+	return u.Path, u.Query().Get("expires"), u.Query().Get("signature")
+}
+
+func TestNewLocalStorage(t *testing.T) {
+	t.Run("Requires a signing key", func(t *testing.T) {
+		// Execute
+		storage, err := local.NewLocalStorage(t.TempDir(), "http://localhost:8080", nil)
+
+		// Assert
+		assert.Error(t, err)
+		assert.Nil(t, storage)
+	})
+}
+
+func TestLocalStorage_SignedURLs(t *testing.T) {
+	t.Run("Upload URL is verified for uploads of the object", func(t *testing.T) {
+		// Setup
+		storage := newStorage(t)
+		expires := time.Now().Add(time.Hour)
+
+		// Execute
+		signedURL, err := storage.GenerateUploadURL("uploads/bank.csv", "text/csv", expires)
+
+		// Assert
+		require.NoError(t, err)
+		path, exp, signature := query(t, signedURL)
+		assert.True(t, strings.HasPrefix(signedURL, "http://localhost:8080/storage/uploads/bank.csv?"))
+		assert.Equal(t, "/storage/uploads/bank.csv", path)
+		assert.Equal(t, strconv.FormatInt(expires.Unix(), 10), exp)
+		assert.NoError(t, storage.VerifyURL(local.OperationUpload, "uploads/bank.csv", exp, signature))
+		assert.ErrorIs(t, storage.VerifyURL(local.OperationDownload, "uploads/bank.csv", exp, signature), model.ErrInvalidSignedURL)
+		assert.ErrorIs(t, storage.VerifyURL(local.OperationUpload, "uploads/other.csv", exp, signature), model.ErrInvalidSignedURL)
+	})
+
+	t.Run("Download URL is verified for downloads of the object", func(t *testing.T) {
+		// Setup
+		storage := newStorage(t)
+
+		// Execute
+		signedURL, err := storage.GenerateDownloadURL("uploads/bank.csv")
+
+		// Assert
+		require.NoError(t, err)
+		_, exp, signature := query(t, signedURL)
+		assert.NoError(t, storage.VerifyURL(local.OperationDownload, "uploads/bank.csv", exp, signature))
+		assert.ErrorIs(t, storage.VerifyURL(local.OperationUpload, "uploads/bank.csv", exp, signature), model.ErrInvalidSignedURL)
+	})
+
+	t.Run("Rejects an altered expiry", func(t *testing.T) {
+		// Setup
+		storage := newStorage(t)
+		signedURL, err := storage.GenerateUploadURL("bank.csv", "text/csv", time.Now().Add(time.Minute))
+		require.NoError(t, err)
+		_, _, signature := query(t, signedURL)
+		later := strconv.FormatInt(time.Now().Add(time.Hour).Unix(), 10)
+
+		// Execute
+		err = storage.VerifyURL(local.OperationUpload, "bank.csv", later, signature)
+
+		// Assert
+		assert.ErrorIs(t, err, model.ErrInvalidSignedURL)
+	})
+
+	t.Run("Rejects a URL signed with another key", func(t *testing.T) {
+		// Setup
+		other, err := local.NewLocalStorage(t.TempDir(), "http://localhost:8080", []byte("other"))
+		require.NoError(t, err)
+		signedURL, err := other.GenerateUploadURL("bank.csv", "text/csv", time.Now().Add(time.Minute))
+		require.NoError(t, err)
+		_, exp, signature := query(t, signedURL)
+
+		// Execute
+		err = newStorage(t).VerifyURL(local.OperationUpload, "bank.csv", exp, signature)
+
+		// Assert
+		assert.ErrorIs(t, err, model.ErrInvalidSignedURL)
+	})
+
+	t.Run("Rejects an expired URL", func(t *testing.T) {
+		// Setup
+		storage := newStorage(t)
+		signedURL, err := storage.GenerateUploadURL("bank.csv", "text/csv", time.Now().Add(-time.Minute))
+		require.NoError(t, err)
+		_, exp, signature := query(t, signedURL)
+
+		// Execute
+		err = storage.VerifyURL(local.OperationUpload, "bank.csv", exp, signature)
+
+		// Assert
+		assert.ErrorIs(t, err, model.ErrSignedURLExpired)
+	})
+
+	t.Run("Rejects object names outside of the directory", func(t *testing.T) {
+		// Setup
+		storage := newStorage(t)
+
+		// Execute
+		_, err := storage.GenerateUploadURL("../bank.csv", "text/csv", time.Now().Add(time.Minute))
+
+		// Assert
+		assert.ErrorIs(t, err, model.ErrInvalidObjectName)
+	})
+}
+
+func TestLocalStorage_WriteAndRead(t *testing.T) {
+	t.Run("Reads the written object", func(t *testing.T) {
+		// Setup
+		storage := newStorage(t)
+		require.NoError(t, storage.Write("uploads/bank.csv", strings.NewReader("first")))
+
+		// Execute
+		require.NoError(t, storage.Write("uploads/bank.csv", strings.NewReader("second")))
+		file, err := storage.NewReader(context.Background(), "uploads/bank.csv")
+
+		// Assert
+		require.NoError(t, err)
+		defer file.Close()
+		content, err := io.ReadAll(file)
+		require.NoError(t, err)
+		assert.Equal(t, "second", string(content))
+	})
+
+	t.Run("Missing object", func(t *testing.T) {
+		// Execute
+		file, err := newStorage(t).NewReader(context.Background(), "uploads/missing.csv")
+
+		// Assert
+		assert.ErrorIs(t, err, model.ErrObjectNotFound)
+		assert.Nil(t, file)
+	})
+
+	t.Run("Rejects object names outside of the directory", func(t *testing.T) {
+		// Execute
+		err := newStorage(t).Write("/etc/passwd", strings.NewReader("content"))
+
+		// Assert
+		assert.ErrorIs(t, err, model.ErrInvalidObjectName)
+	})
+}
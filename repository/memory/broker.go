@@ -0,0 +1,128 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/twmb/franz-go/pkg/kgo"
+)
+
+var ErrBrokerClosed = errors.New("broker closed")
+
+// Broker is an in-process stand-in for Kafka, to run every service in one
+// process. kafka.NewKafkaRepository publishes to it and
+// transport.NewMemoryConsumer consumes from it.
+//
+// Topics are created on first use, so records produced to a topic nothing
+// consumes yet are kept until a consumer reads them. Records are kept in
+// memory, so records not consumed when the process stops are lost.
+type Broker struct {
+	mu     sync.Mutex
+	topics map[string]*topic
+	closed bool
+	// done is closed with the broker to wake up waiting consumers
+	done chan struct{}
+}
+
+type topic struct {
+	records    []*kgo.Record
+	nextOffset int64
+	// ready is closed and replaced whenever a record is added, waking up
+	// every consumer waiting on it
+	ready chan struct{}
+}
+
+// NewBroker creates an empty broker
+func NewBroker() *Broker {
+	return &Broker{
+		topics: make(map[string]*topic),
+		done:   make(chan struct{}),
+	}
+}
+
+// topic returns the topic named name, creating it if needed. b.mu must be
+// held.
+func (b *Broker) topic(name string) *topic {
+	t, ok := b.topics[name]
+	if !ok {
+		t = &topic{ready: make(chan struct{})}
+		b.topics[name] = t
+	}
+	return t
+}
+
+// ProduceSync adds the records to their topics
+func (b *Broker) ProduceSync(_ context.Context, rs ...*kgo.Record) kgo.ProduceResults {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	results := make(kgo.ProduceResults, 0, len(rs))
+	for _, record := range rs {
+		if b.closed {
+			results = append(results, kgo.ProduceResult{Record: record, Err: ErrBrokerClosed})
+			continue
+		}
+
+		t := b.topic(record.Topic)
+		record.Offset = t.nextOffset
+		if record.Timestamp.IsZero() {
+			record.Timestamp = time.Now()
+		}
+		t.nextOffset++
+		t.records = append(t.records, record)
+
+		close(t.ready)
+		t.ready = make(chan struct{})
+
+		results = append(results, kgo.ProduceResult{Record: record})
+	}
+	return results
+}
+
+// Next removes and returns the oldest record of the topic, waiting for one
+// until ctx is cancelled or the broker is closed. Each record is returned to
+// a single caller.
+func (b *Broker) Next(ctx context.Context, topicName string) (*kgo.Record, error) {
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		b.mu.Lock()
+		if b.closed {
+			b.mu.Unlock()
+			return nil, ErrBrokerClosed
+		}
+		t := b.topic(topicName)
+		if len(t.records) > 0 {
+			record := t.records[0]
+			t.records[0] = nil
+			t.records = t.records[1:]
+			b.mu.Unlock()
+			return record, nil
+		}
+		ready := t.ready
+		b.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-b.done:
+		case <-ready:
+		}
+	}
+}
+
+// Close stops the broker. Records not consumed yet are dropped.
+func (b *Broker) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return
+	}
+	b.closed = true
+	close(b.done)
+}
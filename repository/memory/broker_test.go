@@ -0,0 +1,127 @@
+package memory_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aferryc/yars/repository/kafka"
+	"github.com/aferryc/yars/repository/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/twmb/franz-go/pkg/kgo"
+)
+
+func TestBroker(t *testing.T) {
+	t.Run("Records are consumed in order", func(t *testing.T) {
+		// Setup
+		broker := memory.NewBroker()
+		defer broker.Close()
+
+		// Execute
+		results := broker.ProduceSync(context.Background(),
+			&kgo.Record{Topic: "events", Value: []byte("first")},
+			&kgo.Record{Topic: "events", Value: []byte("second")})
+		first, err := broker.Next(context.Background(), "events")
+		require.NoError(t, err)
+		second, err := broker.Next(context.Background(), "events")
+		require.NoError(t, err)
+
+		// Assert
+		assert.NoError(t, results.FirstErr())
+		assert.Equal(t, "first", string(first.Value))
+		assert.Equal(t, int64(0), first.Offset)
+		assert.Equal(t, "second", string(second.Value))
+		assert.Equal(t, int64(1), second.Offset)
+	})
+
+	t.Run("Next waits for a record", func(t *testing.T) {
+		// Setup
+		broker := memory.NewBroker()
+		defer broker.Close()
+
+		var wg sync.WaitGroup
+		var record *kgo.Record
+		var err error
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			record, err = broker.Next(context.Background(), "events")
+		}()
+
+		// Execute
+		time.Sleep(10 * time.Millisecond)
+		broker.ProduceSync(context.Background(), &kgo.Record{Topic: "events", Value: []byte("late")})
+		wg.Wait()
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, "late", string(record.Value))
+	})
+
+	t.Run("Records of a topic without consumer are kept", func(t *testing.T) {
+		// Setup
+		broker := memory.NewBroker()
+		defer broker.Close()
+
+		// Execute
+		results := broker.ProduceSync(context.Background(), &kgo.Record{Topic: "events.dlq", Value: []byte("dead")})
+		record, err := broker.Next(context.Background(), "events.dlq")
+
+		// Assert
+		require.NoError(t, results.FirstErr())
+		require.NoError(t, err)
+		assert.Equal(t, "dead", string(record.Value))
+		assert.Equal(t, int64(0), record.Offset)
+	})
+
+	t.Run("Next stops when the context is cancelled", func(t *testing.T) {
+		// Setup
+		broker := memory.NewBroker()
+		defer broker.Close()
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+
+		// Execute
+		_, err := broker.Next(ctx, "events")
+
+		// Assert
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+
+	t.Run("Closed broker", func(t *testing.T) {
+		// Setup
+		broker := memory.NewBroker()
+		done := make(chan error)
+		go func() {
+			_, err := broker.Next(context.Background(), "events")
+			done <- err
+		}()
+
+		// Execute
+		broker.Close()
+		results := broker.ProduceSync(context.Background(), &kgo.Record{Topic: "events"})
+
+		// Assert
+		assert.ErrorIs(t, <-done, memory.ErrBrokerClosed)
+		assert.ErrorIs(t, results.FirstErr(), memory.ErrBrokerClosed)
+	})
+
+	t.Run("Kafka repository publishes to the broker", func(t *testing.T) {
+		// Setup
+		broker := memory.NewBroker()
+		repo := kafka.NewKafkaRepository(broker)
+		defer repo.Close()
+
+		// Execute
+		err := repo.Publish(context.Background(), "events", "task-1", map[string]string{"taskID": "task-1"})
+		require.NoError(t, err)
+		record, err := broker.Next(context.Background(), "events")
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, "task-1", string(record.Key))
+		assert.JSONEq(t, `{"taskID":"task-1"}`, string(record.Value))
+	})
+}
@@ -6,7 +6,7 @@ package repositorymock
 
 import (
 	context "context"
-	os "os"
+	io "io"
 	reflect "reflect"
 	time "time"
 
@@ -39,33 +39,18 @@ func (m *MockBankStatementRepository) EXPECT() *MockBankStatementRepositoryMockR
 }
 
 // FetchAll mocks base method.
-func (m *MockBankStatementRepository) FetchAll(start, end time.Time) (model.BankStatementList, error) {
+func (m *MockBankStatementRepository) FetchAll(taskID, bank string, start, end time.Time) (model.BankStatementList, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "FetchAll", start, end)
+	ret := m.ctrl.Call(m, "FetchAll", taskID, bank, start, end)
 	ret0, _ := ret[0].(model.BankStatementList)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // FetchAll indicates an expected call of FetchAll.
-func (mr *MockBankStatementRepositoryMockRecorder) FetchAll(start, end interface{}) *gomock.Call {
+func (mr *MockBankStatementRepositoryMockRecorder) FetchAll(taskID, bank, start, end interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FetchAll", reflect.TypeOf((*MockBankStatementRepository)(nil).FetchAll), start, end)
-}
-
-// FindByID mocks base method.
-func (m *MockBankStatementRepository) FindByID(id int) (model.BankStatement, error) {
-	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "FindByID", id)
-	ret0, _ := ret[0].(model.BankStatement)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
-}
-
-// FindByID indicates an expected call of FindByID.
-func (mr *MockBankStatementRepositoryMockRecorder) FindByID(id interface{}) *gomock.Call {
-	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByID", reflect.TypeOf((*MockBankStatementRepository)(nil).FindByID), id)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FetchAll", reflect.TypeOf((*MockBankStatementRepository)(nil).FetchAll), taskID, bank, start, end)
 }
 
 // Save mocks base method.
@@ -82,6 +67,57 @@ func (mr *MockBankStatementRepositoryMockRecorder) Save(statement interface{}) *
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Save", reflect.TypeOf((*MockBankStatementRepository)(nil).Save), statement)
 }
 
+// SaveBatch mocks base method.
+func (m *MockBankStatementRepository) SaveBatch(ctx context.Context, statements []model.BankStatement) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveBatch", ctx, statements)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveBatch indicates an expected call of SaveBatch.
+func (mr *MockBankStatementRepositoryMockRecorder) SaveBatch(ctx, statements interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveBatch", reflect.TypeOf((*MockBankStatementRepository)(nil).SaveBatch), ctx, statements)
+}
+
+// MockStatementRepository is a mock of StatementRepository interface.
+type MockStatementRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockStatementRepositoryMockRecorder
+}
+
+// MockStatementRepositoryMockRecorder is the mock recorder for MockStatementRepository.
+type MockStatementRepositoryMockRecorder struct {
+	mock *MockStatementRepository
+}
+
+// NewMockStatementRepository creates a new mock instance.
+func NewMockStatementRepository(ctrl *gomock.Controller) *MockStatementRepository {
+	mock := &MockStatementRepository{ctrl: ctrl}
+	mock.recorder = &MockStatementRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockStatementRepository) EXPECT() *MockStatementRepositoryMockRecorder {
+	return m.recorder
+}
+
+// SaveBalances mocks base method.
+func (m *MockStatementRepository) SaveBalances(ctx context.Context, statements []model.Statement) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveBalances", ctx, statements)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveBalances indicates an expected call of SaveBalances.
+func (mr *MockStatementRepositoryMockRecorder) SaveBalances(ctx, statements interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveBalances", reflect.TypeOf((*MockStatementRepository)(nil).SaveBalances), ctx, statements)
+}
+
 // MockInternalTransactionRepository is a mock of InternalTransactionRepository interface.
 type MockInternalTransactionRepository struct {
 	ctrl     *gomock.Controller
@@ -106,18 +142,18 @@ func (m *MockInternalTransactionRepository) EXPECT() *MockInternalTransactionRep
 }
 
 // FetchAll mocks base method.
-func (m *MockInternalTransactionRepository) FetchAll(start, end time.Time) (model.TransactionList, error) {
+func (m *MockInternalTransactionRepository) FetchAll(taskID, bank string, start, end time.Time) (model.TransactionList, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "FetchAll", start, end)
+	ret := m.ctrl.Call(m, "FetchAll", taskID, bank, start, end)
 	ret0, _ := ret[0].(model.TransactionList)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // FetchAll indicates an expected call of FetchAll.
-func (mr *MockInternalTransactionRepositoryMockRecorder) FetchAll(start, end interface{}) *gomock.Call {
+func (mr *MockInternalTransactionRepositoryMockRecorder) FetchAll(taskID, bank, start, end interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FetchAll", reflect.TypeOf((*MockInternalTransactionRepository)(nil).FetchAll), start, end)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FetchAll", reflect.TypeOf((*MockInternalTransactionRepository)(nil).FetchAll), taskID, bank, start, end)
 }
 
 // FindByID mocks base method.
@@ -149,72 +185,100 @@ func (mr *MockInternalTransactionRepositoryMockRecorder) Save(transaction interf
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Save", reflect.TypeOf((*MockInternalTransactionRepository)(nil).Save), transaction)
 }
 
-// MockGCSRepository is a mock of GCSRepository interface.
-type MockGCSRepository struct {
+// SaveBatch mocks base method.
+func (m *MockInternalTransactionRepository) SaveBatch(ctx context.Context, transactions []model.Transaction) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveBatch", ctx, transactions)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveBatch indicates an expected call of SaveBatch.
+func (mr *MockInternalTransactionRepositoryMockRecorder) SaveBatch(ctx, transactions interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveBatch", reflect.TypeOf((*MockInternalTransactionRepository)(nil).SaveBatch), ctx, transactions)
+}
+
+// MockStorageRepository is a mock of StorageRepository interface.
+type MockStorageRepository struct {
 	ctrl     *gomock.Controller
-	recorder *MockGCSRepositoryMockRecorder
+	recorder *MockStorageRepositoryMockRecorder
 }
 
-// MockGCSRepositoryMockRecorder is the mock recorder for MockGCSRepository.
-type MockGCSRepositoryMockRecorder struct {
-	mock *MockGCSRepository
+// MockStorageRepositoryMockRecorder is the mock recorder for MockStorageRepository.
+type MockStorageRepositoryMockRecorder struct {
+	mock *MockStorageRepository
 }
 
-// NewMockGCSRepository creates a new mock instance.
-func NewMockGCSRepository(ctrl *gomock.Controller) *MockGCSRepository {
-	mock := &MockGCSRepository{ctrl: ctrl}
-	mock.recorder = &MockGCSRepositoryMockRecorder{mock}
+// NewMockStorageRepository creates a new mock instance.
+func NewMockStorageRepository(ctrl *gomock.Controller) *MockStorageRepository {
+	mock := &MockStorageRepository{ctrl: ctrl}
+	mock.recorder = &MockStorageRepositoryMockRecorder{mock}
 	return mock
 }
 
 // EXPECT returns an object that allows the caller to indicate expected use.
-func (m *MockGCSRepository) EXPECT() *MockGCSRepositoryMockRecorder {
+func (m *MockStorageRepository) EXPECT() *MockStorageRepositoryMockRecorder {
 	return m.recorder
 }
 
-// DownloadFromBucket mocks base method.
-func (m *MockGCSRepository) DownloadFromBucket(ctx context.Context, objectName string) (*os.File, error) {
+// GenerateDownloadURL mocks base method.
+func (m *MockStorageRepository) GenerateDownloadURL(objectName string) (string, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DownloadFromBucket", ctx, objectName)
-	ret0, _ := ret[0].(*os.File)
+	ret := m.ctrl.Call(m, "GenerateDownloadURL", objectName)
+	ret0, _ := ret[0].(string)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// DownloadFromBucket indicates an expected call of DownloadFromBucket.
-func (mr *MockGCSRepositoryMockRecorder) DownloadFromBucket(ctx, objectName interface{}) *gomock.Call {
+// GenerateDownloadURL indicates an expected call of GenerateDownloadURL.
+func (mr *MockStorageRepositoryMockRecorder) GenerateDownloadURL(objectName interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DownloadFromBucket", reflect.TypeOf((*MockGCSRepository)(nil).DownloadFromBucket), ctx, objectName)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GenerateDownloadURL", reflect.TypeOf((*MockStorageRepository)(nil).GenerateDownloadURL), objectName)
 }
 
-// GenerateDownloadURL mocks base method.
-func (m *MockGCSRepository) GenerateDownloadURL(objectName string) (string, error) {
+// GenerateUploadURL mocks base method.
+func (m *MockStorageRepository) GenerateUploadURL(objectName, contentType string, expires time.Time) (string, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GenerateDownloadURL", objectName)
+	ret := m.ctrl.Call(m, "GenerateUploadURL", objectName, contentType, expires)
 	ret0, _ := ret[0].(string)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GenerateDownloadURL indicates an expected call of GenerateDownloadURL.
-func (mr *MockGCSRepositoryMockRecorder) GenerateDownloadURL(objectName interface{}) *gomock.Call {
+// GenerateUploadURL indicates an expected call of GenerateUploadURL.
+func (mr *MockStorageRepositoryMockRecorder) GenerateUploadURL(objectName, contentType, expires interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GenerateDownloadURL", reflect.TypeOf((*MockGCSRepository)(nil).GenerateDownloadURL), objectName)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GenerateUploadURL", reflect.TypeOf((*MockStorageRepository)(nil).GenerateUploadURL), objectName, contentType, expires)
 }
 
-// GenerateUploadURL mocks base method.
-func (m *MockGCSRepository) GenerateUploadURL(objectName, contentType string, expires time.Time) (string, error) {
+// NewReader mocks base method.
+func (m *MockStorageRepository) NewReader(ctx context.Context, objectName string) (io.ReadCloser, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GenerateUploadURL", objectName, contentType, expires)
-	ret0, _ := ret[0].(string)
+	ret := m.ctrl.Call(m, "NewReader", ctx, objectName)
+	ret0, _ := ret[0].(io.ReadCloser)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GenerateUploadURL indicates an expected call of GenerateUploadURL.
-func (mr *MockGCSRepositoryMockRecorder) GenerateUploadURL(objectName, contentType, expires interface{}) *gomock.Call {
+// NewReader indicates an expected call of NewReader.
+func (mr *MockStorageRepositoryMockRecorder) NewReader(ctx, objectName interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GenerateUploadURL", reflect.TypeOf((*MockGCSRepository)(nil).GenerateUploadURL), objectName, contentType, expires)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NewReader", reflect.TypeOf((*MockStorageRepository)(nil).NewReader), ctx, objectName)
+}
+
+// UploadMethod mocks base method.
+func (m *MockStorageRepository) UploadMethod() string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UploadMethod")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// UploadMethod indicates an expected call of UploadMethod.
+func (mr *MockStorageRepositoryMockRecorder) UploadMethod() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UploadMethod", reflect.TypeOf((*MockStorageRepository)(nil).UploadMethod))
 }
 
 // MockKafkaRepository is a mock of KafkaRepository interface.
@@ -268,6 +332,20 @@ func (mr *MockKafkaRepositoryMockRecorder) Publish(ctx, topic, key, message inte
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Publish", reflect.TypeOf((*MockKafkaRepository)(nil).Publish), ctx, topic, key, message)
 }
 
+// PublishEvent mocks base method.
+func (m *MockKafkaRepository) PublishEvent(ctx context.Context, topic string, envelope model.EventEnvelope, event any) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PublishEvent", ctx, topic, envelope, event)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PublishEvent indicates an expected call of PublishEvent.
+func (mr *MockKafkaRepositoryMockRecorder) PublishEvent(ctx, topic, envelope, event interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PublishEvent", reflect.TypeOf((*MockKafkaRepository)(nil).PublishEvent), ctx, topic, envelope, event)
+}
+
 // MockReconResultRepository is a mock of ReconResultRepository interface.
 type MockReconResultRepository struct {
 	ctrl     *gomock.Controller
@@ -291,6 +369,38 @@ func (m *MockReconResultRepository) EXPECT() *MockReconResultRepositoryMockRecor
 	return m.recorder
 }
 
+// GetAggregateMatches mocks base method.
+func (m *MockReconResultRepository) GetAggregateMatches(ctx context.Context, taskID string, limit, offset int) ([]postgres.AggregateMatch, int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAggregateMatches", ctx, taskID, limit, offset)
+	ret0, _ := ret[0].([]postgres.AggregateMatch)
+	ret1, _ := ret[1].(int)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetAggregateMatches indicates an expected call of GetAggregateMatches.
+func (mr *MockReconResultRepositoryMockRecorder) GetAggregateMatches(ctx, taskID, limit, offset interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAggregateMatches", reflect.TypeOf((*MockReconResultRepository)(nil).GetAggregateMatches), ctx, taskID, limit, offset)
+}
+
+// GetMatchedPairs mocks base method.
+func (m *MockReconResultRepository) GetMatchedPairs(ctx context.Context, taskID string, limit, offset int) ([]postgres.MatchedPair, int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMatchedPairs", ctx, taskID, limit, offset)
+	ret0, _ := ret[0].([]postgres.MatchedPair)
+	ret1, _ := ret[1].(int)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetMatchedPairs indicates an expected call of GetMatchedPairs.
+func (mr *MockReconResultRepositoryMockRecorder) GetMatchedPairs(ctx, taskID, limit, offset interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMatchedPairs", reflect.TypeOf((*MockReconResultRepository)(nil).GetMatchedPairs), ctx, taskID, limit, offset)
+}
+
 // GetUnmatchedBankStatements mocks base method.
 func (m *MockReconResultRepository) GetUnmatchedBankStatements(ctx context.Context, taskID string, limit, offset int) ([]postgres.UnmatchedBankStatement, int, error) {
 	m.ctrl.T.Helper()
@@ -352,3 +462,320 @@ func (mr *MockReconResultRepositoryMockRecorder) StoreSummary(ctx, summary, star
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StoreSummary", reflect.TypeOf((*MockReconResultRepository)(nil).StoreSummary), ctx, summary, startDate, endDate)
 }
+
+// MockFXRateRepository is a mock of FXRateRepository interface.
+type MockFXRateRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockFXRateRepositoryMockRecorder
+}
+
+// MockFXRateRepositoryMockRecorder is the mock recorder for MockFXRateRepository.
+type MockFXRateRepositoryMockRecorder struct {
+	mock *MockFXRateRepository
+}
+
+// NewMockFXRateRepository creates a new mock instance.
+func NewMockFXRateRepository(ctrl *gomock.Controller) *MockFXRateRepository {
+	mock := &MockFXRateRepository{ctrl: ctrl}
+	mock.recorder = &MockFXRateRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockFXRateRepository) EXPECT() *MockFXRateRepositoryMockRecorder {
+	return m.recorder
+}
+
+// FetchRates mocks base method.
+func (m *MockFXRateRepository) FetchRates(ctx context.Context, start, end time.Time) ([]model.FXRate, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FetchRates", ctx, start, end)
+	ret0, _ := ret[0].([]model.FXRate)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FetchRates indicates an expected call of FetchRates.
+func (mr *MockFXRateRepositoryMockRecorder) FetchRates(ctx, start, end interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FetchRates", reflect.TypeOf((*MockFXRateRepository)(nil).FetchRates), ctx, start, end)
+}
+
+// GetRate mocks base method.
+func (m *MockFXRateRepository) GetRate(ctx context.Context, baseCurrency, quoteCurrency string, date time.Time) (model.FXRate, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRate", ctx, baseCurrency, quoteCurrency, date)
+	ret0, _ := ret[0].(model.FXRate)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRate indicates an expected call of GetRate.
+func (mr *MockFXRateRepositoryMockRecorder) GetRate(ctx, baseCurrency, quoteCurrency, date interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRate", reflect.TypeOf((*MockFXRateRepository)(nil).GetRate), ctx, baseCurrency, quoteCurrency, date)
+}
+
+// SaveRates mocks base method.
+func (m *MockFXRateRepository) SaveRates(ctx context.Context, rates []model.FXRate) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveRates", ctx, rates)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveRates indicates an expected call of SaveRates.
+func (mr *MockFXRateRepositoryMockRecorder) SaveRates(ctx, rates interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveRates", reflect.TypeOf((*MockFXRateRepository)(nil).SaveRates), ctx, rates)
+}
+
+// MockIngestionProfileRepository is a mock of IngestionProfileRepository interface.
+type MockIngestionProfileRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockIngestionProfileRepositoryMockRecorder
+}
+
+// MockIngestionProfileRepositoryMockRecorder is the mock recorder for MockIngestionProfileRepository.
+type MockIngestionProfileRepositoryMockRecorder struct {
+	mock *MockIngestionProfileRepository
+}
+
+// NewMockIngestionProfileRepository creates a new mock instance.
+func NewMockIngestionProfileRepository(ctrl *gomock.Controller) *MockIngestionProfileRepository {
+	mock := &MockIngestionProfileRepository{ctrl: ctrl}
+	mock.recorder = &MockIngestionProfileRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIngestionProfileRepository) EXPECT() *MockIngestionProfileRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Delete mocks base method.
+func (m *MockIngestionProfileRepository) Delete(ctx context.Context, bankName string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, bankName)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockIngestionProfileRepositoryMockRecorder) Delete(ctx, bankName interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockIngestionProfileRepository)(nil).Delete), ctx, bankName)
+}
+
+// Get mocks base method.
+func (m *MockIngestionProfileRepository) Get(ctx context.Context, bankName string) (model.IngestionProfile, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", ctx, bankName)
+	ret0, _ := ret[0].(model.IngestionProfile)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockIngestionProfileRepositoryMockRecorder) Get(ctx, bankName interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockIngestionProfileRepository)(nil).Get), ctx, bankName)
+}
+
+// List mocks base method.
+func (m *MockIngestionProfileRepository) List(ctx context.Context) ([]model.IngestionProfile, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", ctx)
+	ret0, _ := ret[0].([]model.IngestionProfile)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockIngestionProfileRepositoryMockRecorder) List(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockIngestionProfileRepository)(nil).List), ctx)
+}
+
+// Save mocks base method.
+func (m *MockIngestionProfileRepository) Save(ctx context.Context, profile model.IngestionProfile) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Save", ctx, profile)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Save indicates an expected call of Save.
+func (mr *MockIngestionProfileRepositoryMockRecorder) Save(ctx, profile interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Save", reflect.TypeOf((*MockIngestionProfileRepository)(nil).Save), ctx, profile)
+}
+
+// MockReconTaskRepository is a mock of ReconTaskRepository interface.
+type MockReconTaskRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockReconTaskRepositoryMockRecorder
+}
+
+// MockReconTaskRepositoryMockRecorder is the mock recorder for MockReconTaskRepository.
+type MockReconTaskRepositoryMockRecorder struct {
+	mock *MockReconTaskRepository
+}
+
+// NewMockReconTaskRepository creates a new mock instance.
+func NewMockReconTaskRepository(ctrl *gomock.Controller) *MockReconTaskRepository {
+	mock := &MockReconTaskRepository{ctrl: ctrl}
+	mock.recorder = &MockReconTaskRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockReconTaskRepository) EXPECT() *MockReconTaskRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockReconTaskRepository) Create(ctx context.Context, taskID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, taskID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockReconTaskRepositoryMockRecorder) Create(ctx, taskID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockReconTaskRepository)(nil).Create), ctx, taskID)
+}
+
+// Get mocks base method.
+func (m *MockReconTaskRepository) Get(ctx context.Context, taskID string) (model.ReconTask, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", ctx, taskID)
+	ret0, _ := ret[0].(model.ReconTask)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockReconTaskRepositoryMockRecorder) Get(ctx, taskID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockReconTaskRepository)(nil).Get), ctx, taskID)
+}
+
+// UpdateStatus mocks base method.
+func (m *MockReconTaskRepository) UpdateStatus(ctx context.Context, taskID string, status model.TaskStatus, message string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateStatus", ctx, taskID, status, message)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateStatus indicates an expected call of UpdateStatus.
+func (mr *MockReconTaskRepositoryMockRecorder) UpdateStatus(ctx, taskID, status, message interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateStatus", reflect.TypeOf((*MockReconTaskRepository)(nil).UpdateStatus), ctx, taskID, status, message)
+}
+
+// MockTransactor is a mock of Transactor interface.
+type MockTransactor struct {
+	ctrl     *gomock.Controller
+	recorder *MockTransactorMockRecorder
+}
+
+// MockTransactorMockRecorder is the mock recorder for MockTransactor.
+type MockTransactorMockRecorder struct {
+	mock *MockTransactor
+}
+
+// NewMockTransactor creates a new mock instance.
+func NewMockTransactor(ctrl *gomock.Controller) *MockTransactor {
+	mock := &MockTransactor{ctrl: ctrl}
+	mock.recorder = &MockTransactorMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTransactor) EXPECT() *MockTransactorMockRecorder {
+	return m.recorder
+}
+
+// WithinTx mocks base method.
+func (m *MockTransactor) WithinTx(ctx context.Context, fn func(context.Context) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WithinTx", ctx, fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// WithinTx indicates an expected call of WithinTx.
+func (mr *MockTransactorMockRecorder) WithinTx(ctx, fn interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithinTx", reflect.TypeOf((*MockTransactor)(nil).WithinTx), ctx, fn)
+}
+
+// MockOutboxRepository is a mock of OutboxRepository interface.
+type MockOutboxRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockOutboxRepositoryMockRecorder
+}
+
+// MockOutboxRepositoryMockRecorder is the mock recorder for MockOutboxRepository.
+type MockOutboxRepositoryMockRecorder struct {
+	mock *MockOutboxRepository
+}
+
+// NewMockOutboxRepository creates a new mock instance.
+func NewMockOutboxRepository(ctrl *gomock.Controller) *MockOutboxRepository {
+	mock := &MockOutboxRepository{ctrl: ctrl}
+	mock.recorder = &MockOutboxRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockOutboxRepository) EXPECT() *MockOutboxRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Add mocks base method.
+func (m *MockOutboxRepository) Add(ctx context.Context, event model.OutboxEvent) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Add", ctx, event)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Add indicates an expected call of Add.
+func (mr *MockOutboxRepositoryMockRecorder) Add(ctx, event interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Add", reflect.TypeOf((*MockOutboxRepository)(nil).Add), ctx, event)
+}
+
+// FetchUnsent mocks base method.
+func (m *MockOutboxRepository) FetchUnsent(ctx context.Context, limit int) ([]model.OutboxEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FetchUnsent", ctx, limit)
+	ret0, _ := ret[0].([]model.OutboxEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FetchUnsent indicates an expected call of FetchUnsent.
+func (mr *MockOutboxRepositoryMockRecorder) FetchUnsent(ctx, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FetchUnsent", reflect.TypeOf((*MockOutboxRepository)(nil).FetchUnsent), ctx, limit)
+}
+
+// MarkSent mocks base method.
+func (m *MockOutboxRepository) MarkSent(ctx context.Context, ids []int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkSent", ctx, ids)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkSent indicates an expected call of MarkSent.
+func (mr *MockOutboxRepositoryMockRecorder) MarkSent(ctx, ids interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkSent", reflect.TypeOf((*MockOutboxRepository)(nil).MarkSent), ctx, ids)
+}
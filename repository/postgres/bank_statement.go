@@ -1,7 +1,8 @@
 package postgres
 
 import (
-	"errors"
+	"context"
+	"database/sql"
 	"time"
 
 	"github.com/aferryc/yars/model"
@@ -13,10 +14,16 @@ type DBBankStatementRepository struct {
 }
 
 type DBBankStatement struct {
-	ID     string    `db:"id"`
-	Amount float64   `db:"amount"`
-	Date   time.Time `db:"date"`
-	Bank   string    `db:"bank"`
+	ID           string         `db:"id"`
+	TaskID       string         `db:"task_id"`
+	Amount       model.Money    `db:"amount"`
+	Currency     string         `db:"currency"`
+	Date         time.Time      `db:"date"`
+	Reference    sql.NullString `db:"reference"`
+	Narrative    sql.NullString `db:"narrative"`
+	EndToEndID   sql.NullString `db:"end_to_end_id"`
+	Counterparty sql.NullString `db:"counterparty"`
+	Bank         string         `db:"bank"`
 }
 
 func NewDBBankStatementRepository(db *sqlx.DB) *DBBankStatementRepository {
@@ -25,10 +32,12 @@ func NewDBBankStatementRepository(db *sqlx.DB) *DBBankStatementRepository {
 	}
 }
 
-func (r *DBBankStatementRepository) FetchAll(start, end time.Time) (model.BankStatementList, error) {
+func (r *DBBankStatementRepository) FetchAll(taskID, bank string, start, end time.Time) (model.BankStatementList, error) {
 	var dbStatements []DBBankStatement
 
-	err := r.db.Select(&dbStatements, "SELECT id, amount, date, bank FROM bank_statements WHERE date BETWEEN $1 AND $2", start, end)
+	err := r.db.Select(&dbStatements, `
+		SELECT id, task_id, amount, currency, date, reference, narrative, end_to_end_id, counterparty, bank FROM bank_statements
+		WHERE task_id = $1 AND bank = $2 AND date BETWEEN $3 AND $4`, taskID, bank, start, end)
 	if err != nil {
 		return model.BankStatementList{}, err
 	}
@@ -36,9 +45,15 @@ func (r *DBBankStatementRepository) FetchAll(start, end time.Time) (model.BankSt
 	statements := make([]model.BankStatement, len(dbStatements))
 	for i, dbStmt := range dbStatements {
 		statements[i] = model.BankStatement{
-			ID:     dbStmt.ID,
-			Amount: dbStmt.Amount,
-			Date:   dbStmt.Date,
+			ID:           dbStmt.ID,
+			Amount:       dbStmt.Amount.WithCurrency(dbStmt.Currency),
+			Date:         dbStmt.Date,
+			Reference:    dbStmt.Reference.String,
+			Narrative:    dbStmt.Narrative.String,
+			EndToEndID:   dbStmt.EndToEndID.String,
+			Counterparty: dbStmt.Counterparty.String,
+			BankName:     dbStmt.Bank,
+			TaskID:       dbStmt.TaskID,
 		}
 	}
 
@@ -49,36 +64,55 @@ func (r *DBBankStatementRepository) FetchAll(start, end time.Time) (model.BankSt
 
 func (r *DBBankStatementRepository) Save(statement model.BankStatement) error {
 	dbStmt := DBBankStatement{
-		ID:     statement.ID,
-		Amount: statement.Amount,
-		Date:   statement.Date,
+		ID:           statement.ID,
+		TaskID:       statement.TaskID,
+		Bank:         statement.BankName,
+		Amount:       statement.Amount,
+		Currency:     statement.Amount.Currency(),
+		Date:         statement.Date,
+		Reference:    sql.NullString{String: statement.Reference, Valid: statement.Reference != ""},
+		Narrative:    sql.NullString{String: statement.Narrative, Valid: statement.Narrative != ""},
+		EndToEndID:   sql.NullString{String: statement.EndToEndID, Valid: statement.EndToEndID != ""},
+		Counterparty: sql.NullString{String: statement.Counterparty, Valid: statement.Counterparty != ""},
 	}
 
 	query := `
-	INSERT INTO bank_statements (id, amount, date, bank) 
-	VALUES (:id, :amount, :date, :bank)
-	ON CONFLICT (id, date, bank) DO UPDATE SET
-		amount = :amount
+	INSERT INTO bank_statements (id, task_id, amount, currency, date, reference, narrative, end_to_end_id, counterparty, bank) 
+	VALUES (:id, :task_id, :amount, :currency, :date, :reference, :narrative, :end_to_end_id, :counterparty, :bank)
+	ON CONFLICT (task_id, bank, id) DO UPDATE SET
+		amount = :amount,
+		currency = :currency,
+		date = :date,
+		reference = :reference,
+		narrative = :narrative,
+		end_to_end_id = :end_to_end_id,
+		counterparty = :counterparty
 	`
 
 	_, err := r.db.NamedExec(query, dbStmt)
 	return err
 }
 
-func (r *DBBankStatementRepository) FindByID(id int) (model.BankStatement, error) {
-	var dbStmt DBBankStatement
+var bankStatementColumns = []string{"id", "task_id", "amount", "currency", "date", "reference", "narrative", "end_to_end_id", "counterparty", "bank"}
 
-	err := r.db.Get(&dbStmt, "SELECT id, amount, date, bank FROM bank_statements WHERE id = $1", id)
-	if err != nil {
-		if err.Error() == "sql: no rows in result set" {
-			return model.BankStatement{}, errors.New("bank statement not found")
+// SaveBatch upserts the statements with COPY, much faster than Save for large
+// files
+func (r *DBBankStatementRepository) SaveBatch(ctx context.Context, statements []model.BankStatement) error {
+	rows := make([][]any, len(statements))
+	for i, statement := range statements {
+		rows[i] = []any{
+			statement.ID,
+			statement.TaskID,
+			statement.Amount,
+			statement.Amount.Currency(),
+			statement.Date,
+			sql.NullString{String: statement.Reference, Valid: statement.Reference != ""},
+			sql.NullString{String: statement.Narrative, Valid: statement.Narrative != ""},
+			sql.NullString{String: statement.EndToEndID, Valid: statement.EndToEndID != ""},
+			sql.NullString{String: statement.Counterparty, Valid: statement.Counterparty != ""},
+			statement.BankName,
 		}
-		return model.BankStatement{}, err
 	}
 
-	return model.BankStatement{
-		ID:     dbStmt.ID,
-		Amount: dbStmt.Amount,
-		Date:   dbStmt.Date,
-	}, nil
+	return bulkUpsert(ctx, r.db, "bank_statements", bankStatementColumns, []string{"task_id", "bank", "id"}, rows)
 }
@@ -0,0 +1,101 @@
+package postgres_test
+
+import (
+	"context"
+	"database/sql/driver"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/aferryc/yars/model"
+	"github.com/aferryc/yars/repository/postgres"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDBBankStatementRepository_SaveBatch(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+	repo := postgres.NewDBBankStatementRepository(sqlxDB)
+
+	ctx := context.Background()
+	date := time.Date(2023, 1, 15, 0, 0, 0, 0, time.UTC)
+	statements := []model.BankStatement{
+		{ID: "bs-1", TaskID: "task-1", BankName: "TestBank", Amount: money("500.25"), Date: date, Reference: "REF-1", Narrative: "INVOICE 42",
+			EndToEndID: "E2E-1", Counterparty: "ACME CORP"},
+		{ID: "bs-2", TaskID: "task-1", BankName: "TestBank", Amount: money("750.50"), Date: date},
+	}
+
+	t.Run("Copies the batch and merges it", func(t *testing.T) {
+		// Setup expectations
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta("CREATE TEMP TABLE bank_statements_staging (LIKE bank_statements")).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		copyIn := mock.ExpectPrepare(regexp.QuoteMeta(pq.CopyIn("bank_statements_staging",
+			"id", "task_id", "amount", "currency", "date", "reference", "narrative", "end_to_end_id", "counterparty", "bank")))
+		copyIn.ExpectExec().
+			WithArgs("bs-1", "task-1", statements[0].Amount.String(), "", date, "REF-1", "INVOICE 42", "E2E-1", "ACME CORP", "TestBank").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		// Missing references and details are stored as NULL
+		copyIn.ExpectExec().
+			WithArgs("bs-2", "task-1", statements[1].Amount.String(), "", date,
+				driver.Value(nil), driver.Value(nil), driver.Value(nil), driver.Value(nil), "TestBank").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		copyIn.ExpectExec().WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec(`INSERT INTO bank_statements .* FROM bank_statements_staging\s+` +
+			`ORDER BY task_id, bank, id, staging_seq DESC\s+` +
+			`ON CONFLICT \(task_id, bank, id\) DO UPDATE SET amount = EXCLUDED.amount, currency = EXCLUDED.currency, ` +
+			`date = EXCLUDED.date, reference = EXCLUDED.reference`).
+			WillReturnResult(sqlmock.NewResult(0, 2))
+		mock.ExpectExec("DROP TABLE bank_statements_staging").WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectCommit()
+
+		// Execute
+		err := repo.SaveBatch(ctx, statements)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestDBBankStatementRepository_FetchAll(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+	repo := postgres.NewDBBankStatementRepository(sqlxDB)
+
+	start := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2023, 1, 31, 0, 0, 0, 0, time.UTC)
+	date := time.Date(2023, 1, 15, 0, 0, 0, 0, time.UTC)
+
+	t.Run("Loads amounts with the decimal places of their currency", func(t *testing.T) {
+		// Setup expectations
+		mock.ExpectQuery(regexp.QuoteMeta("FROM bank_statements")).
+			WithArgs("task-1", "TestBank", start, end).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "task_id", "amount", "currency", "date", "reference", "narrative", "end_to_end_id", "counterparty", "bank"}).
+				AddRow("bs-1", "task-1", []byte("1.234"), "BHD", date, "REF-1", nil, nil, nil, "TestBank").
+				AddRow("bs-2", "task-1", []byte("-500.250"), "USD", date, nil, nil, nil, nil, "TestBank").
+				AddRow("bs-3", "task-1", []byte("1500.000"), "JPY", date, nil, nil, nil, nil, "TestBank"))
+
+		// Execute
+		list, err := repo.FetchAll("task-1", "TestBank", start, end)
+
+		// Assert
+		require.NoError(t, err)
+		require.Len(t, list.BankStatements, 3)
+		assert.Equal(t, model.MustParseMoney("1.234", "BHD"), list.BankStatements[0].Amount)
+		assert.Equal(t, "REF-1", list.BankStatements[0].Reference)
+		assert.Equal(t, model.MustParseMoney("-500.25", "USD"), list.BankStatements[1].Amount)
+		assert.Equal(t, model.MustParseMoney("1500", "JPY"), list.BankStatements[2].Amount)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -0,0 +1,86 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+)
+
+// bulkUpsert writes rows into table, updating the rows whose key already
+// exists. The rows are streamed with COPY into a temporary staging table and
+// merged with a single statement. When a key appears more than once, the last
+// row wins, as with one upsert per row. It joins the transaction of ctx when
+// there is one.
+func bulkUpsert(ctx context.Context, db *sqlx.DB, table string, columns, key []string, rows [][]any) error {
+	if len(rows) == 0 {
+		return nil
+	}
+
+	staging := table + "_staging"
+	return NewDBTransactor(db).WithinTx(ctx, func(ctx context.Context) error {
+		tx, _ := txFromContext(ctx)
+
+		_, err := tx.ExecContext(ctx, fmt.Sprintf(
+			`CREATE TEMP TABLE %s (LIKE %s INCLUDING DEFAULTS, staging_seq BIGSERIAL) ON COMMIT DROP`,
+			staging, table))
+		if err != nil {
+			return errors.Wrapf(err, "[bulkUpsert] error creating staging table for %s", table)
+		}
+
+		if err := copyRows(ctx, tx, staging, columns, rows); err != nil {
+			return err
+		}
+
+		updates := make([]string, 0, len(columns))
+		for _, column := range columns {
+			if !slices.Contains(key, column) {
+				updates = append(updates, column+" = EXCLUDED."+column)
+			}
+		}
+		keyList := strings.Join(key, ", ")
+		columnList := strings.Join(columns, ", ")
+
+		_, err = tx.ExecContext(ctx, fmt.Sprintf(`
+			INSERT INTO %s (%s)
+			SELECT DISTINCT ON (%s) %s FROM %s
+			ORDER BY %s, staging_seq DESC
+			ON CONFLICT (%s) DO UPDATE SET %s`,
+			table, columnList, keyList, columnList, staging, keyList, keyList, strings.Join(updates, ", ")))
+		if err != nil {
+			return errors.Wrapf(err, "[bulkUpsert] error merging rows into %s", table)
+		}
+
+		// Dropped here as well as on commit, for callers writing several
+		// batches in one transaction
+		if _, err := tx.ExecContext(ctx, "DROP TABLE "+staging); err != nil {
+			return errors.Wrapf(err, "[bulkUpsert] error dropping staging table for %s", table)
+		}
+		return nil
+	})
+}
+
+// copyRows streams rows into table with COPY
+func copyRows(ctx context.Context, tx *sqlx.Tx, table string, columns []string, rows [][]any) error {
+	stmt, err := tx.PrepareContext(ctx, pq.CopyIn(table, columns...))
+	if err != nil {
+		return errors.Wrapf(err, "[copyRows] error starting copy into %s", table)
+	}
+	defer stmt.Close()
+
+	for _, row := range rows {
+		if _, err := stmt.ExecContext(ctx, row...); err != nil {
+			return errors.Wrapf(err, "[copyRows] error copying row into %s", table)
+		}
+	}
+
+	// An empty exec flushes the buffered rows
+	if _, err := stmt.ExecContext(ctx); err != nil {
+		return errors.Wrapf(err, "[copyRows] error flushing copy into %s", table)
+	}
+	return nil
+}
@@ -0,0 +1,133 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+
+	"github.com/aferryc/yars/internal/utils"
+	"github.com/aferryc/yars/model"
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+)
+
+func NewDBFXRateRepository(db *sqlx.DB) *DBFXRateRepository {
+	return &DBFXRateRepository{
+		db: db,
+	}
+}
+
+type DBFXRateRepository struct {
+	db *sqlx.DB
+}
+
+type DBFXRate struct {
+	BaseCurrency  string    `db:"base_currency"`
+	QuoteCurrency string    `db:"quote_currency"`
+	RateDate      time.Time `db:"rate_date"`
+	Rate          string    `db:"rate"`
+}
+
+// SaveRates upserts the rates, a rate already stored for the same pair and
+// date is replaced.
+func (r *DBFXRateRepository) SaveRates(ctx context.Context, rates []model.FXRate) error {
+	if len(rates) == 0 {
+		return nil
+	}
+
+	// Postgres rejects an upsert touching the same row twice, so only the
+	// last rate of a pair and date is kept
+	index := make(map[string]int, len(rates))
+	var records []DBFXRate
+	for _, rate := range rates {
+		record := DBFXRate{
+			BaseCurrency:  strings.ToUpper(rate.BaseCurrency),
+			QuoteCurrency: strings.ToUpper(rate.QuoteCurrency),
+			RateDate:      rate.Date,
+			Rate:          model.FormatFXRate(rate.Rate),
+		}
+		key := record.BaseCurrency + "/" + record.QuoteCurrency + "/" + record.RateDate.Format("2006-01-02")
+		if i, ok := index[key]; ok {
+			records[i] = record
+			continue
+		}
+		index[key] = len(records)
+		records = append(records, record)
+	}
+
+	tx, err := r.db.BeginTxx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return errors.Wrap(err, "[SaveRates] error beginning transaction")
+	}
+
+	for _, chunk := range utils.ChunkSlice(records, batchSize) {
+		_, err = tx.NamedExecContext(ctx, `
+			INSERT INTO fx_rates (
+				base_currency, quote_currency, rate_date, rate
+			) VALUES (
+				:base_currency, :quote_currency, :rate_date, :rate
+			)
+			ON CONFLICT (base_currency, quote_currency, rate_date) DO UPDATE SET
+				rate = EXCLUDED.rate,
+				updated_at = NOW()`, chunk)
+		if err != nil {
+			_ = tx.Rollback()
+			return errors.Wrap(err, "[SaveRates] error upserting fx rates")
+		}
+	}
+
+	return tx.Commit()
+}
+
+// FetchRates returns every rate published between start and end, inclusive
+func (r *DBFXRateRepository) FetchRates(ctx context.Context, start, end time.Time) ([]model.FXRate, error) {
+	var records []DBFXRate
+	err := r.db.SelectContext(ctx, &records, `
+		SELECT base_currency, quote_currency, rate_date, rate FROM fx_rates
+		WHERE rate_date BETWEEN $1 AND $2
+		ORDER BY rate_date`, start, end)
+	if err != nil {
+		return nil, errors.Wrap(err, "[FetchRates] error fetching fx rates")
+	}
+
+	rates := make([]model.FXRate, len(records))
+	for i, record := range records {
+		rates[i], err = record.toModel()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return rates, nil
+}
+
+// GetRate returns the latest rate for the pair published on or before date
+func (r *DBFXRateRepository) GetRate(ctx context.Context, baseCurrency, quoteCurrency string, date time.Time) (model.FXRate, error) {
+	var record DBFXRate
+	err := r.db.GetContext(ctx, &record, `
+		SELECT base_currency, quote_currency, rate_date, rate FROM fx_rates
+		WHERE base_currency = $1 AND quote_currency = $2 AND rate_date <= $3
+		ORDER BY rate_date DESC
+		LIMIT 1`, strings.ToUpper(baseCurrency), strings.ToUpper(quoteCurrency), date)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return model.FXRate{}, model.ErrFXRateNotFound
+		}
+		return model.FXRate{}, errors.Wrap(err, "[GetRate] error fetching fx rate")
+	}
+
+	return record.toModel()
+}
+
+func (r DBFXRate) toModel() (model.FXRate, error) {
+	rate, err := model.ParseFXRate(r.Rate)
+	if err != nil {
+		return model.FXRate{}, err
+	}
+	return model.FXRate{
+		BaseCurrency:  r.BaseCurrency,
+		QuoteCurrency: r.QuoteCurrency,
+		Date:          r.RateDate,
+		Rate:          rate,
+	}, nil
+}
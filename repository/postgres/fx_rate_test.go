@@ -0,0 +1,146 @@
+package postgres_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/aferryc/yars/model"
+	"github.com/aferryc/yars/repository/postgres"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDBFXRateRepository_SaveRates(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+	repo := postgres.NewDBFXRateRepository(sqlxDB)
+
+	ctx := context.Background()
+	date := time.Date(2023, 1, 13, 0, 0, 0, 0, time.UTC)
+
+	t.Run("Upserts rates keeping the last one of a pair and date", func(t *testing.T) {
+		rates := []model.FXRate{
+			{BaseCurrency: "usd", QuoteCurrency: "eur", Date: date, Rate: big.NewRat(9, 10)},
+			{BaseCurrency: "USD", QuoteCurrency: "JPY", Date: date, Rate: big.NewRat(1285, 10)},
+			{BaseCurrency: "USD", QuoteCurrency: "EUR", Date: date, Rate: big.NewRat(9215, 10000)},
+		}
+
+		// Setup expectations
+		mock.ExpectBegin()
+		mock.ExpectExec("INSERT INTO fx_rates .* ON CONFLICT").
+			WithArgs("USD", "EUR", date, "0.9215", "USD", "JPY", date, "128.5").
+			WillReturnResult(sqlmock.NewResult(0, 2))
+		mock.ExpectCommit()
+
+		// Execute
+		err := repo.SaveRates(ctx, rates)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Rolls back on error", func(t *testing.T) {
+		// Setup expectations
+		mock.ExpectBegin()
+		mock.ExpectExec("INSERT INTO fx_rates").
+			WillReturnError(errors.New("database error"))
+		mock.ExpectRollback()
+
+		// Execute
+		err := repo.SaveRates(ctx, []model.FXRate{
+			{BaseCurrency: "USD", QuoteCurrency: "EUR", Date: date, Rate: big.NewRat(9, 10)},
+		})
+
+		// Assert
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "database error")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestDBFXRateRepository_FetchRates(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+	repo := postgres.NewDBFXRateRepository(sqlxDB)
+
+	ctx := context.Background()
+	start := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2023, 1, 31, 0, 0, 0, 0, time.UTC)
+	date := time.Date(2023, 1, 13, 0, 0, 0, 0, time.UTC)
+
+	// Setup expectations
+	rows := sqlmock.NewRows([]string{"base_currency", "quote_currency", "rate_date", "rate"}).
+		AddRow("USD", "EUR", date, "0.9215000000").
+		AddRow("USD", "JPY", date, "128.5000000000")
+	mock.ExpectQuery("SELECT (.+) FROM fx_rates").
+		WithArgs(start, end).
+		WillReturnRows(rows)
+
+	// Execute
+	rates, err := repo.FetchRates(ctx, start, end)
+
+	// Assert
+	require.NoError(t, err)
+	require.Len(t, rates, 2)
+	assert.Equal(t, "EUR", rates[0].QuoteCurrency)
+	assert.Equal(t, date, rates[0].Date)
+	assert.Equal(t, 0, big.NewRat(9215, 10000).Cmp(rates[0].Rate))
+	assert.Equal(t, 0, big.NewRat(1285, 10).Cmp(rates[1].Rate))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDBFXRateRepository_GetRate(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+	repo := postgres.NewDBFXRateRepository(sqlxDB)
+
+	ctx := context.Background()
+	date := time.Date(2023, 1, 15, 0, 0, 0, 0, time.UTC)
+	published := time.Date(2023, 1, 13, 0, 0, 0, 0, time.UTC)
+
+	t.Run("Latest rate on or before the date", func(t *testing.T) {
+		rows := sqlmock.NewRows([]string{"base_currency", "quote_currency", "rate_date", "rate"}).
+			AddRow("USD", "EUR", published, "0.9215000000")
+		mock.ExpectQuery("SELECT (.+) FROM fx_rates (.+) ORDER BY rate_date DESC").
+			WithArgs("USD", "EUR", date).
+			WillReturnRows(rows)
+
+		// Execute
+		rate, err := repo.GetRate(ctx, "usd", "eur", date)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, published, rate.Date)
+		assert.Equal(t, 0, big.NewRat(9215, 10000).Cmp(rate.Rate))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Rate not found", func(t *testing.T) {
+		mock.ExpectQuery("SELECT (.+) FROM fx_rates").
+			WithArgs("USD", "GBP", date).
+			WillReturnError(sql.ErrNoRows)
+
+		// Execute
+		_, err := repo.GetRate(ctx, "USD", "GBP", date)
+
+		// Assert
+		assert.True(t, errors.Is(err, model.ErrFXRateNotFound))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
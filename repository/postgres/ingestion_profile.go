@@ -0,0 +1,153 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/aferryc/yars/model"
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+)
+
+func NewDBIngestionProfileRepository(db *sqlx.DB) *DBIngestionProfileRepository {
+	return &DBIngestionProfileRepository{
+		db: db,
+	}
+}
+
+type DBIngestionProfileRepository struct {
+	db *sqlx.DB
+}
+
+// IngestionProfile stores the formats of a profile as JSON, a NULL format
+// stands for the default one
+type IngestionProfile struct {
+	BankName            string    `db:"bank_name"`
+	TransactionFormat   []byte    `db:"transaction_format"`
+	BankStatementFormat []byte    `db:"bank_statement_format"`
+	UpdatedAt           time.Time `db:"updated_at"`
+}
+
+// Save creates the profile of the bank or replaces it
+func (r *DBIngestionProfileRepository) Save(ctx context.Context, profile model.IngestionProfile) error {
+	transactionFormat, err := marshalFormat(profile.Transactions)
+	if err != nil {
+		return errors.Wrap(err, "[SaveIngestionProfile] error encoding transaction format")
+	}
+	bankStatementFormat, err := marshalFormat(profile.BankStatements)
+	if err != nil {
+		return errors.Wrap(err, "[SaveIngestionProfile] error encoding bank statement format")
+	}
+
+	_, err = r.db.ExecContext(ctx, `
+		INSERT INTO ingestion_profiles (
+			bank_name, transaction_format, bank_statement_format, created_at, updated_at
+		) VALUES ($1, $2, $3, NOW(), NOW())
+		ON CONFLICT (bank_name) DO UPDATE SET
+			transaction_format = EXCLUDED.transaction_format,
+			bank_statement_format = EXCLUDED.bank_statement_format,
+			updated_at = NOW()`,
+		profile.BankName, transactionFormat, bankStatementFormat)
+	if err != nil {
+		return errors.Wrap(err, "[SaveIngestionProfile] error saving ingestion profile")
+	}
+	return nil
+}
+
+// Get returns the profile of the bank
+func (r *DBIngestionProfileRepository) Get(ctx context.Context, bankName string) (model.IngestionProfile, error) {
+	var record IngestionProfile
+	err := r.db.GetContext(ctx, &record, `
+		SELECT bank_name, transaction_format, bank_statement_format, updated_at
+		FROM ingestion_profiles
+		WHERE bank_name = $1`, bankName)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return model.IngestionProfile{}, model.ErrIngestionProfileNotFound
+		}
+		return model.IngestionProfile{}, errors.Wrap(err, "[GetIngestionProfile] error fetching ingestion profile")
+	}
+
+	return record.toModel()
+}
+
+// List returns every profile, ordered by bank name
+func (r *DBIngestionProfileRepository) List(ctx context.Context) ([]model.IngestionProfile, error) {
+	var records []IngestionProfile
+	err := r.db.SelectContext(ctx, &records, `
+		SELECT bank_name, transaction_format, bank_statement_format, updated_at
+		FROM ingestion_profiles
+		ORDER BY bank_name`)
+	if err != nil {
+		return nil, errors.Wrap(err, "[ListIngestionProfiles] error fetching ingestion profiles")
+	}
+
+	profiles := make([]model.IngestionProfile, len(records))
+	for i, record := range records {
+		profiles[i], err = record.toModel()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return profiles, nil
+}
+
+// Delete removes the profile of the bank, its files are then read in the
+// default formats
+func (r *DBIngestionProfileRepository) Delete(ctx context.Context, bankName string) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM ingestion_profiles WHERE bank_name = $1`, bankName)
+	if err != nil {
+		return errors.Wrap(err, "[DeleteIngestionProfile] error deleting ingestion profile")
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return errors.Wrap(err, "[DeleteIngestionProfile] error reading affected rows")
+	}
+	if affected == 0 {
+		return model.ErrIngestionProfileNotFound
+	}
+	return nil
+}
+
+func (r IngestionProfile) toModel() (model.IngestionProfile, error) {
+	transactions, err := unmarshalFormat(r.TransactionFormat)
+	if err != nil {
+		return model.IngestionProfile{}, errors.Wrapf(err, "[IngestionProfile] error decoding transaction format of %s", r.BankName)
+	}
+	bankStatements, err := unmarshalFormat(r.BankStatementFormat)
+	if err != nil {
+		return model.IngestionProfile{}, errors.Wrapf(err, "[IngestionProfile] error decoding bank statement format of %s", r.BankName)
+	}
+	return model.IngestionProfile{
+		BankName:       r.BankName,
+		Transactions:   transactions,
+		BankStatements: bankStatements,
+		UpdatedAt:      r.UpdatedAt,
+	}, nil
+}
+
+// marshalFormat encodes the format as text, pq would send bytes as bytea
+func marshalFormat(format *model.CSVFormat) (sql.NullString, error) {
+	if format == nil {
+		return sql.NullString{}, nil
+	}
+	data, err := json.Marshal(format)
+	if err != nil {
+		return sql.NullString{}, err
+	}
+	return sql.NullString{String: string(data), Valid: true}, nil
+}
+
+func unmarshalFormat(data []byte) (*model.CSVFormat, error) {
+	if data == nil {
+		return nil, nil
+	}
+	var format model.CSVFormat
+	if err := json.Unmarshal(data, &format); err != nil {
+		return nil, err
+	}
+	return &format, nil
+}
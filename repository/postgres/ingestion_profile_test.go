@@ -0,0 +1,114 @@
+package postgres_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/aferryc/yars/model"
+	"github.com/aferryc/yars/repository/postgres"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDBIngestionProfileRepository_Save(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+	repo := postgres.NewDBIngestionProfileRepository(sqlxDB)
+
+	t.Run("Stores the formats as JSON", func(t *testing.T) {
+		profile := model.IngestionProfile{
+			BankName: "TestBank",
+			BankStatements: &model.CSVFormat{
+				Columns:     map[string]model.Column{model.FieldID: {Name: "Reference"}},
+				DateLayouts: []string{"02.01.2006"},
+			},
+		}
+
+		// Setup expectations
+		mock.ExpectExec("INSERT INTO ingestion_profiles .* ON CONFLICT \\(bank_name\\) DO UPDATE").
+			WithArgs("TestBank", nil, `{"columns":{"id":{"name":"Reference"}},"dateLayouts":["02.01.2006"]}`).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		// Execute
+		err := repo.Save(context.Background(), profile)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestDBIngestionProfileRepository_Get(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+	repo := postgres.NewDBIngestionProfileRepository(sqlxDB)
+	updatedAt := time.Date(2023, 1, 15, 0, 0, 0, 0, time.UTC)
+
+	t.Run("Decodes the formats", func(t *testing.T) {
+		// Setup expectations
+		rows := sqlmock.NewRows([]string{"bank_name", "transaction_format", "bank_statement_format", "updated_at"}).
+			AddRow("TestBank", nil, []byte(`{"columns":{"id":{"index":2}},"dateLayouts":["2006-01-02"],"signConvention":"split"}`), updatedAt)
+		mock.ExpectQuery("SELECT .* FROM ingestion_profiles").
+			WithArgs("TestBank").
+			WillReturnRows(rows)
+
+		// Execute
+		profile, err := repo.Get(context.Background(), "TestBank")
+
+		// Assert
+		require.NoError(t, err)
+		assert.Nil(t, profile.Transactions)
+		require.NotNil(t, profile.BankStatements)
+		assert.Equal(t, model.ColumnIndex(2), profile.BankStatements.Columns[model.FieldID])
+		assert.Equal(t, model.SignConventionSplit, profile.BankStatements.SignConvention)
+		assert.Equal(t, updatedAt, profile.UpdatedAt)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Profile not found", func(t *testing.T) {
+		// Setup expectations
+		mock.ExpectQuery("SELECT .* FROM ingestion_profiles").
+			WithArgs("OtherBank").
+			WillReturnError(sql.ErrNoRows)
+
+		// Execute
+		_, err := repo.Get(context.Background(), "OtherBank")
+
+		// Assert
+		assert.ErrorIs(t, err, model.ErrIngestionProfileNotFound)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestDBIngestionProfileRepository_Delete(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+	repo := postgres.NewDBIngestionProfileRepository(sqlxDB)
+
+	t.Run("Profile not found", func(t *testing.T) {
+		// Setup expectations
+		mock.ExpectExec("DELETE FROM ingestion_profiles").
+			WithArgs("TestBank").
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		// Execute
+		err := repo.Delete(context.Background(), "TestBank")
+
+		// Assert
+		assert.ErrorIs(t, err, model.ErrIngestionProfileNotFound)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
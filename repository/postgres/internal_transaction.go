@@ -1,6 +1,7 @@
 package postgres
 
 import (
+	"context"
 	"errors"
 	"time"
 
@@ -19,16 +20,21 @@ type DBInternalTransactionRepository struct {
 }
 
 type DBTransaction struct {
-	ID              string    `db:"id"`
-	Amount          float64   `db:"amount"`
-	Type            string    `db:"type"`
-	TransactionTime time.Time `db:"transaction_time"`
+	ID              string      `db:"id"`
+	TaskID          string      `db:"task_id"`
+	Bank            string      `db:"bank"`
+	Amount          model.Money `db:"amount"`
+	Currency        string      `db:"currency"`
+	Type            string      `db:"type"`
+	TransactionTime time.Time   `db:"transaction_time"`
 }
 
-func (r *DBInternalTransactionRepository) FetchAll(start, end time.Time) (model.TransactionList, error) {
+func (r *DBInternalTransactionRepository) FetchAll(taskID, bank string, start, end time.Time) (model.TransactionList, error) {
 	var dbTransactions []DBTransaction
 
-	err := r.db.Select(&dbTransactions, "SELECT id, amount, type, transaction_time FROM transactions WHERE transaction_time BETWEEN $1 AND $2", start, end)
+	err := r.db.Select(&dbTransactions, `
+		SELECT id, task_id, bank, amount, currency, type, transaction_time FROM transactions
+		WHERE task_id = $1 AND bank = $2 AND transaction_time BETWEEN $3 AND $4`, taskID, bank, start, end)
 	if err != nil {
 		return model.TransactionList{}, err
 	}
@@ -38,9 +44,11 @@ func (r *DBInternalTransactionRepository) FetchAll(start, end time.Time) (model.
 	for i, dbTx := range dbTransactions {
 		transactions[i] = model.Transaction{
 			ID:              dbTx.ID,
-			Amount:          dbTx.Amount,
+			Amount:          dbTx.Amount.WithCurrency(dbTx.Currency),
 			Type:            dbTx.Type,
 			TransactionTime: dbTx.TransactionTime,
+			TaskID:          dbTx.TaskID,
+			BankName:        dbTx.Bank,
 		}
 	}
 
@@ -52,27 +60,53 @@ func (r *DBInternalTransactionRepository) FetchAll(start, end time.Time) (model.
 func (r *DBInternalTransactionRepository) Save(transaction model.Transaction) error {
 	dbTx := DBTransaction{
 		ID:              transaction.ID,
+		TaskID:          transaction.TaskID,
+		Bank:            transaction.BankName,
 		Amount:          transaction.Amount,
+		Currency:        transaction.Amount.Currency(),
 		Type:            transaction.Type,
 		TransactionTime: transaction.TransactionTime,
 	}
 
 	_, err := r.db.NamedExec(
-		`INSERT INTO transactions (id, amount, type, transaction_time) 
-		VALUES (:id, :amount, :type, :transaction_time)
-		ON CONFLICT (id, transaction_time) DO UPDATE SET
+		`INSERT INTO transactions (id, task_id, bank, amount, currency, type, transaction_time) 
+		VALUES (:id, :task_id, :bank, :amount, :currency, :type, :transaction_time)
+		ON CONFLICT (task_id, bank, id) DO UPDATE SET
 			amount = :amount, 
-			type = :type`,
+			currency = :currency,
+			type = :type,
+			transaction_time = :transaction_time`,
 		dbTx,
 	)
 
 	return err
 }
 
+var transactionColumns = []string{"id", "task_id", "bank", "amount", "currency", "type", "transaction_time"}
+
+// SaveBatch upserts the transactions with COPY, much faster than Save for
+// large files
+func (r *DBInternalTransactionRepository) SaveBatch(ctx context.Context, transactions []model.Transaction) error {
+	rows := make([][]any, len(transactions))
+	for i, transaction := range transactions {
+		rows[i] = []any{
+			transaction.ID,
+			transaction.TaskID,
+			transaction.BankName,
+			transaction.Amount,
+			transaction.Amount.Currency(),
+			transaction.Type,
+			transaction.TransactionTime,
+		}
+	}
+
+	return bulkUpsert(ctx, r.db, "transactions", transactionColumns, []string{"task_id", "bank", "id"}, rows)
+}
+
 func (r *DBInternalTransactionRepository) FindByID(id string) (model.Transaction, error) {
 	var dbTx DBTransaction
 
-	err := r.db.Get(&dbTx, "SELECT id, amount, type, transaction_time FROM transactions WHERE id = $1", id)
+	err := r.db.Get(&dbTx, "SELECT id, task_id, bank, amount, currency, type, transaction_time FROM transactions WHERE id = $1", id)
 	if err != nil {
 		if err.Error() == "sql: no rows in result set" {
 			return model.Transaction{}, errors.New("transaction not found")
@@ -82,8 +116,10 @@ func (r *DBInternalTransactionRepository) FindByID(id string) (model.Transaction
 
 	return model.Transaction{
 		ID:              dbTx.ID,
-		Amount:          dbTx.Amount,
+		Amount:          dbTx.Amount.WithCurrency(dbTx.Currency),
 		Type:            dbTx.Type,
 		TransactionTime: dbTx.TransactionTime,
+		TaskID:          dbTx.TaskID,
+		BankName:        dbTx.Bank,
 	}, nil
 }
@@ -0,0 +1,90 @@
+package postgres_test
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/aferryc/yars/model"
+	"github.com/aferryc/yars/repository/postgres"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDBInternalTransactionRepository_SaveBatch(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+	repo := postgres.NewDBInternalTransactionRepository(sqlxDB)
+
+	ctx := context.Background()
+	transactionTime := time.Date(2023, 1, 15, 14, 30, 45, 0, time.UTC)
+	transactions := []model.Transaction{
+		{ID: "tx1", TaskID: "task-1", BankName: "TestBank", Amount: money("100.50"), Type: "CREDIT", TransactionTime: transactionTime},
+		{ID: "tx2", TaskID: "task-1", BankName: "TestBank", Amount: model.MustParseMoney("200.75", "EUR"), Type: "DEBIT", TransactionTime: transactionTime},
+	}
+	copyQuery := regexp.QuoteMeta(pq.CopyIn("transactions_staging",
+		"id", "task_id", "bank", "amount", "currency", "type", "transaction_time"))
+
+	t.Run("Copies the batch and merges it", func(t *testing.T) {
+		// Setup expectations
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta("CREATE TEMP TABLE transactions_staging (LIKE transactions")).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		copyIn := mock.ExpectPrepare(copyQuery)
+		copyIn.ExpectExec().
+			WithArgs("tx1", "task-1", "TestBank", transactions[0].Amount.String(), "", "CREDIT", transactionTime).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		copyIn.ExpectExec().
+			WithArgs("tx2", "task-1", "TestBank", transactions[1].Amount.String(), "EUR", "DEBIT", transactionTime).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		copyIn.ExpectExec().WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec(`INSERT INTO transactions \(id, task_id, bank, amount, currency, type, transaction_time\)\s+` +
+			`SELECT DISTINCT ON \(task_id, bank, id\) .* FROM transactions_staging\s+` +
+			`ORDER BY task_id, bank, id, staging_seq DESC\s+` +
+			`ON CONFLICT \(task_id, bank, id\) DO UPDATE SET amount = EXCLUDED.amount, currency = EXCLUDED.currency, ` +
+			`type = EXCLUDED.type, transaction_time = EXCLUDED.transaction_time`).
+			WillReturnResult(sqlmock.NewResult(0, 2))
+		mock.ExpectExec("DROP TABLE transactions_staging").WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectCommit()
+
+		// Execute
+		err := repo.SaveBatch(ctx, transactions)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Empty batch", func(t *testing.T) {
+		// Execute
+		err := repo.SaveBatch(ctx, nil)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Copy error rolls back", func(t *testing.T) {
+		// Setup expectations
+		mock.ExpectBegin()
+		mock.ExpectExec("CREATE TEMP TABLE transactions_staging").WillReturnResult(sqlmock.NewResult(0, 0))
+		copyIn := mock.ExpectPrepare(copyQuery)
+		copyIn.ExpectExec().WillReturnError(errors.New("invalid input syntax"))
+		mock.ExpectRollback()
+
+		// Execute
+		err := repo.SaveBatch(ctx, transactions)
+
+		// Assert
+		assert.ErrorContains(t, err, "invalid input syntax")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -0,0 +1,101 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/aferryc/yars/model"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+)
+
+func NewDBOutboxRepository(db *sqlx.DB) *DBOutboxRepository {
+	return &DBOutboxRepository{
+		db: db,
+	}
+}
+
+type DBOutboxRepository struct {
+	db *sqlx.DB
+}
+
+type OutboxEvent struct {
+	ID            int64          `db:"id"`
+	Topic         string         `db:"topic"`
+	EventID       string         `db:"event_id"`
+	EventType     string         `db:"event_type"`
+	SchemaVersion int            `db:"schema_version"`
+	TaskID        string         `db:"task_id"`
+	TraceParent   sql.NullString `db:"trace_parent"`
+	Payload       []byte         `db:"payload"`
+	OccurredAt    time.Time      `db:"occurred_at"`
+}
+
+// Add stores the event to be published, in the transaction of ctx so it is
+// only published once the data it describes is committed
+func (r *DBOutboxRepository) Add(ctx context.Context, event model.OutboxEvent) error {
+	_, err := conn(ctx, r.db).ExecContext(ctx, `
+		INSERT INTO outbox_events (
+			topic, event_id, event_type, schema_version, task_id,
+			trace_parent, payload, occurred_at
+		) VALUES ($1, $2, $3, $4, $5, NULLIF($6, ''), $7, $8)`,
+		event.Topic, event.Envelope.EventID, event.Envelope.EventType, event.Envelope.SchemaVersion,
+		event.Envelope.TaskID, event.Envelope.TraceParent, []byte(event.Payload), event.Envelope.OccurredAt)
+	if err != nil {
+		return errors.Wrap(err, "[AddOutboxEvent] error storing event")
+	}
+	return nil
+}
+
+// FetchUnsent returns up to limit events not published yet, oldest first. The
+// events are locked until the transaction of ctx ends, and events locked by
+// another transaction are skipped, so concurrent relays never publish the same
+// event.
+func (r *DBOutboxRepository) FetchUnsent(ctx context.Context, limit int) ([]model.OutboxEvent, error) {
+	var records []OutboxEvent
+	err := conn(ctx, r.db).SelectContext(ctx, &records, `
+		SELECT id, topic, event_id, event_type, schema_version, task_id,
+			trace_parent, payload, occurred_at
+		FROM outbox_events
+		WHERE sent_at IS NULL
+		ORDER BY id
+		LIMIT $1
+		FOR UPDATE SKIP LOCKED`, limit)
+	if err != nil {
+		return nil, errors.Wrap(err, "[FetchUnsentOutboxEvents] error fetching events")
+	}
+
+	events := make([]model.OutboxEvent, 0, len(records))
+	for _, record := range records {
+		events = append(events, model.OutboxEvent{
+			ID:    record.ID,
+			Topic: record.Topic,
+			Envelope: model.EventEnvelope{
+				EventType:     record.EventType,
+				SchemaVersion: record.SchemaVersion,
+				EventID:       record.EventID,
+				OccurredAt:    record.OccurredAt,
+				TaskID:        record.TaskID,
+				TraceParent:   record.TraceParent.String,
+			},
+			Payload: record.Payload,
+		})
+	}
+	return events, nil
+}
+
+// MarkSent records the events as published
+func (r *DBOutboxRepository) MarkSent(ctx context.Context, ids []int64) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	_, err := conn(ctx, r.db).ExecContext(ctx, `
+		UPDATE outbox_events SET sent_at = NOW() WHERE id = ANY($1)`, pq.Array(ids))
+	if err != nil {
+		return errors.Wrap(err, "[MarkOutboxEventsSent] error marking events as sent")
+	}
+	return nil
+}
@@ -0,0 +1,201 @@
+package postgres_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/aferryc/yars/model"
+	"github.com/aferryc/yars/repository/postgres"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDBOutboxRepository_Add(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+	repo := postgres.NewDBOutboxRepository(sqlxDB)
+
+	ctx := context.Background()
+	occurredAt := time.Date(2023, 1, 15, 12, 0, 0, 0, time.UTC)
+	event := model.OutboxEvent{
+		Topic: "recon-topic",
+		Envelope: model.EventEnvelope{
+			EventType:     model.EventTypeReconciliationRequested,
+			SchemaVersion: 1,
+			EventID:       "event-1",
+			OccurredAt:    occurredAt,
+			TaskID:        "task-1",
+			TraceParent:   "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		},
+		Payload: []byte(`{"taskID":"task-1"}`),
+	}
+
+	t.Run("Stores the event", func(t *testing.T) {
+		// Setup expectations
+		mock.ExpectExec("INSERT INTO outbox_events").
+			WithArgs("recon-topic", "event-1", model.EventTypeReconciliationRequested, 1, "task-1",
+				event.Envelope.TraceParent, []byte(`{"taskID":"task-1"}`), occurredAt).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+
+		// Execute
+		err := repo.Add(ctx, event)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Joins the transaction of the context", func(t *testing.T) {
+		// Setup expectations
+		mock.ExpectBegin()
+		mock.ExpectExec("UPDATE recon_task SET").WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("INSERT INTO outbox_events").WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectCommit()
+
+		// Execute
+		taskRepo := postgres.NewDBReconTaskRepository(sqlxDB)
+		err := postgres.NewDBTransactor(sqlxDB).WithinTx(ctx, func(ctx context.Context) error {
+			if err := taskRepo.UpdateStatus(ctx, "task-1", model.TaskCompiled, ""); err != nil {
+				return err
+			}
+			return repo.Add(ctx, event)
+		})
+
+		// Assert
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Error rolls back the transaction", func(t *testing.T) {
+		// Setup expectations
+		mock.ExpectBegin()
+		mock.ExpectExec("UPDATE recon_task SET").WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("INSERT INTO outbox_events").WillReturnError(errors.New("db error"))
+		mock.ExpectRollback()
+
+		// Execute
+		taskRepo := postgres.NewDBReconTaskRepository(sqlxDB)
+		err := postgres.NewDBTransactor(sqlxDB).WithinTx(ctx, func(ctx context.Context) error {
+			if err := taskRepo.UpdateStatus(ctx, "task-1", model.TaskCompiled, ""); err != nil {
+				return err
+			}
+			return repo.Add(ctx, event)
+		})
+
+		// Assert
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "error storing event")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestDBOutboxRepository_FetchUnsent(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+	repo := postgres.NewDBOutboxRepository(sqlxDB)
+
+	ctx := context.Background()
+	occurredAt := time.Date(2023, 1, 15, 12, 0, 0, 0, time.UTC)
+	columns := []string{"id", "topic", "event_id", "event_type", "schema_version", "task_id", "trace_parent", "payload", "occurred_at"}
+
+	t.Run("Returns the unsent events", func(t *testing.T) {
+		// Setup expectations
+		mock.ExpectQuery("SELECT (.+) FROM outbox_events (.+) FOR UPDATE SKIP LOCKED").
+			WithArgs(10).
+			WillReturnRows(sqlmock.NewRows(columns).
+				AddRow(1, "recon-topic", "event-1", model.EventTypeReconciliationRequested, 1, "task-1", "00-trace", []byte(`{"taskID":"task-1"}`), occurredAt).
+				AddRow(2, "result-topic", "event-2", model.EventTypeReconciliationCompleted, 1, "task-2", nil, []byte(`{"taskID":"task-2"}`), occurredAt))
+
+		// Execute
+		events, err := repo.FetchUnsent(ctx, 10)
+
+		// Assert
+		require.NoError(t, err)
+		require.Len(t, events, 2)
+		assert.Equal(t, int64(1), events[0].ID)
+		assert.Equal(t, "recon-topic", events[0].Topic)
+		assert.Equal(t, model.EventEnvelope{
+			EventType:     model.EventTypeReconciliationRequested,
+			SchemaVersion: 1,
+			EventID:       "event-1",
+			OccurredAt:    occurredAt,
+			TaskID:        "task-1",
+			TraceParent:   "00-trace",
+		}, events[0].Envelope)
+		assert.JSONEq(t, `{"taskID":"task-1"}`, string(events[0].Payload))
+		assert.Empty(t, events[1].Envelope.TraceParent)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Error fetching events", func(t *testing.T) {
+		// Setup expectations
+		mock.ExpectQuery("SELECT (.+) FROM outbox_events").
+			WillReturnError(errors.New("db error"))
+
+		// Execute
+		events, err := repo.FetchUnsent(ctx, 10)
+
+		// Assert
+		assert.Error(t, err)
+		assert.Nil(t, events)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestDBOutboxRepository_MarkSent(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+	repo := postgres.NewDBOutboxRepository(sqlxDB)
+
+	ctx := context.Background()
+
+	t.Run("Marks the events", func(t *testing.T) {
+		// Setup expectations
+		mock.ExpectExec("UPDATE outbox_events SET sent_at").
+			WithArgs(pq.Array([]int64{1, 2})).
+			WillReturnResult(sqlmock.NewResult(0, 2))
+
+		// Execute
+		err := repo.MarkSent(ctx, []int64{1, 2})
+
+		// Assert
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("No events", func(t *testing.T) {
+		// Execute
+		err := repo.MarkSent(ctx, nil)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Error marking events", func(t *testing.T) {
+		// Setup expectations
+		mock.ExpectExec("UPDATE outbox_events SET sent_at").
+			WillReturnError(errors.New("db error"))
+
+		// Execute
+		err := repo.MarkSent(ctx, []int64{1})
+
+		// Assert
+		assert.Error(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
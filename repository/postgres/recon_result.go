@@ -7,7 +7,9 @@ import (
 
 	"github.com/aferryc/yars/internal/utils"
 	"github.com/aferryc/yars/model"
+	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 	"github.com/pkg/errors"
 )
 
@@ -22,75 +24,140 @@ type DBReconResultRepository struct {
 }
 
 type ReconSummary struct {
-	TaskID                 string    `db:"id"`
-	TotalMatched           int       `db:"matched"`
-	TotalDiscrepancy       float64   `db:"discrepancy"`
-	TotalTransaction       int       `db:"total_transaction"`
-	TotalUnmatchedBank     int       `db:"total_unmatched_bank"`
-	TotalUnmatchedInternal int       `db:"total_unmatched_internal"`
-	StartDate              time.Time `db:"start_date"`
-	EndDate                time.Time `db:"end_date"`
-	CreatedAt              time.Time `db:"created_at"`
-	UpdatedAt              time.Time `db:"updated_at"`
+	TaskID                 string         `db:"id"`
+	TotalMatched           int            `db:"matched"`
+	TotalDiscrepancy       model.Money    `db:"discrepancy"`
+	Currency               string         `db:"currency"`
+	MissingRates           pq.StringArray `db:"missing_rates"`
+	TotalTransaction       int            `db:"total_transaction"`
+	TotalUnmatchedBank     int            `db:"total_unmatched_bank"`
+	TotalUnmatchedInternal int            `db:"total_unmatched_internal"`
+	StartDate              time.Time      `db:"start_date"`
+	EndDate                time.Time      `db:"end_date"`
+	CreatedAt              time.Time      `db:"created_at"`
+	UpdatedAt              time.Time      `db:"updated_at"`
 }
 
 type UnmatchedTransaction struct {
-	ID              string    `db:"id"`
-	TaskID          string    `db:"task_id"`
-	Amount          float64   `db:"amount"`
-	TransactionTime time.Time `db:"transaction_time"`
-	Type            string    `db:"type"`
-	Description     string    `db:"description"`
-	CreatedAt       time.Time `db:"created_at"`
+	ID              string      `db:"id"`
+	TaskID          string      `db:"task_id"`
+	Amount          model.Money `db:"amount"`
+	Currency        string      `db:"currency"`
+	TransactionTime time.Time   `db:"transaction_time"`
+	Type            string      `db:"type"`
+	Description     string      `db:"description"`
+	CreatedAt       time.Time   `db:"created_at"`
 }
 
 type UnmatchedBankStatement struct {
-	ID        int       `db:"id"`
-	TaskID    string    `db:"task_id"`
-	Amount    float64   `db:"amount"`
-	Date      time.Time `db:"date"`
-	Reference string    `db:"reference"`
-	BankName  string    `db:"bank_name"`
-	CreatedAt time.Time `db:"created_at"`
+	ID              int         `db:"id"`
+	TaskID          string      `db:"task_id"`
+	BankStatementID string      `db:"bank_statement_id"`
+	Amount          model.Money `db:"amount"`
+	Currency        string      `db:"currency"`
+	Date            time.Time   `db:"date"`
+	Reference       string      `db:"reference"`
+	BankName        string      `db:"bank_name"`
+	CreatedAt       time.Time   `db:"created_at"`
 }
 
+type AggregateMatch struct {
+	ID        string               `db:"id"`
+	TaskID    string               `db:"task_id"`
+	MatchType string               `db:"match_type"`
+	Rule      string               `db:"rule"`
+	Amount    model.Money          `db:"amount"`
+	Currency  string               `db:"currency"`
+	CreatedAt time.Time            `db:"created_at"`
+	Items     []AggregateMatchItem `db:"-"`
+}
+
+type AggregateMatchItem struct {
+	ID               int         `db:"id"`
+	AggregateMatchID string      `db:"aggregate_match_id"`
+	RecordType       string      `db:"record_type"`
+	RecordID         string      `db:"record_id"`
+	Amount           model.Money `db:"amount"`
+	Currency         string      `db:"currency"`
+	Date             time.Time   `db:"date"`
+	CreatedAt        time.Time   `db:"created_at"`
+}
+
+type MatchedPair struct {
+	ID                  int            `db:"id"`
+	TaskID              string         `db:"task_id"`
+	Rule                string         `db:"rule"`
+	MatchType           string         `db:"match_type"`
+	AggregateMatchID    sql.NullString `db:"aggregate_match_id"`
+	TransactionID       string         `db:"transaction_id"`
+	TransactionAmount   model.Money    `db:"transaction_amount"`
+	TransactionCurrency string         `db:"transaction_currency"`
+	TransactionTime     time.Time      `db:"transaction_time"`
+	BankStatementID     string         `db:"bank_statement_id"`
+	BankAmount          model.Money    `db:"bank_amount"`
+	BankCurrency        string         `db:"bank_currency"`
+	BankDate            time.Time      `db:"bank_date"`
+	BankReference       sql.NullString `db:"bank_reference"`
+	// AmountDifference is the bank amount less the transaction amount, in the
+	// bank currency. It is NULL for the pairs of a group.
+	AmountDifference *model.Money `db:"amount_difference"`
+	CreatedAt        time.Time    `db:"created_at"`
+}
+
+const (
+	RecordTypeTransaction   = "TRANSACTION"
+	RecordTypeBankStatement = "BANK_STATEMENT"
+)
+
 const batchSize = 1000
 
+// StoreSummary stores the results of a task, replacing the results of an
+// earlier run so a redelivered event can store them again. It joins the
+// transaction of ctx when there is one.
 func (r *DBReconResultRepository) StoreSummary(ctx context.Context, summary model.ReconciliationSummary, startDate, endDate time.Time) error {
-	tx, err := r.db.BeginTxx(ctx, &sql.TxOptions{})
-	if err != nil {
-		return err
-	}
+	return NewDBTransactor(r.db).WithinTx(ctx, func(ctx context.Context) error {
+		tx, _ := txFromContext(ctx)
 
-	defer func() {
-		if err != nil {
-			_ = tx.Rollback()
+		if err := r.deleteReconResults(ctx, tx, summary.TaskID); err != nil {
+			return err
 		}
-	}()
 
-	if err = r.insertReconSummary(ctx, tx, summary, startDate, endDate); err != nil {
-		return err
-	}
+		if err := r.insertReconSummary(ctx, tx, summary, startDate, endDate); err != nil {
+			return err
+		}
 
-	if err = r.insertUnmatchedTransactions(ctx, tx, summary.TaskID, summary.UnmatchedInternal); err != nil {
-		return err
-	}
+		if err := r.insertUnmatchedTransactions(ctx, tx, summary.TaskID, summary.UnmatchedInternal); err != nil {
+			return err
+		}
 
-	if err = r.insertUnmatchedBankStatements(ctx, tx, summary.TaskID, summary.UnmatchedBank); err != nil {
-		return err
-	}
+		if err := r.insertUnmatchedBankStatements(ctx, tx, summary.TaskID, summary.UnmatchedBank); err != nil {
+			return err
+		}
+
+		groupIDs, err := r.insertAggregateMatches(ctx, tx, summary.TaskID, summary.Matches)
+		if err != nil {
+			return err
+		}
 
-	return tx.Commit()
+		return r.insertMatchedPairs(ctx, tx, summary.TaskID, summary.Matches, groupIDs)
+	})
+}
+
+// deleteReconResults removes the summary of a task, the other results are
+// removed with it by cascade
+func (r *DBReconResultRepository) deleteReconResults(ctx context.Context, tx *sqlx.Tx, taskID string) error {
+	_, err := tx.ExecContext(ctx, `DELETE FROM recon_summary WHERE id = $1`, taskID)
+	return err
 }
 
 func (r *DBReconResultRepository) insertReconSummary(ctx context.Context, tx *sqlx.Tx, summary model.ReconciliationSummary, startDate, endDate time.Time) error {
 	_, err := tx.NamedExecContext(ctx, `
 		INSERT INTO recon_summary (
-			id, matched, discrepancy, total_transaction, 
+			id, matched, discrepancy, currency, missing_rates, total_transaction,
 			total_unmatched_bank, total_unmatched_internal,
 			start_date, end_date, created_at, updated_at
 		) VALUES (
-			:id, :matched, :discrepancy, :total_transaction,
+			:id, :matched, :discrepancy, :currency, :missing_rates, :total_transaction,
 			:total_unmatched_bank, :total_unmatched_internal,
 			:start_date, :end_date, NOW(), NOW()
 		)`,
@@ -98,6 +165,8 @@ func (r *DBReconResultRepository) insertReconSummary(ctx context.Context, tx *sq
 			TaskID:                 summary.TaskID,
 			TotalMatched:           summary.TotalMatched,
 			TotalDiscrepancy:       summary.TotalDiscrepancy,
+			Currency:               summary.TotalDiscrepancy.Currency(),
+			MissingRates:           pq.StringArray(summary.MissingRates),
 			TotalTransaction:       summary.TotalTransaction,
 			TotalUnmatchedBank:     len(summary.UnmatchedBank),
 			TotalUnmatchedInternal: len(summary.UnmatchedInternal),
@@ -124,9 +193,9 @@ func (r *DBReconResultRepository) insertUnmatchedTransactions(ctx context.Contex
 func (r *DBReconResultRepository) insertUnmatchedTransactionsBatch(ctx context.Context, tx *sqlx.Tx, taskID string, unmatchedTxns []model.Transaction) error {
 	query := `
 		INSERT INTO unmatched_transactions (
-			id, task_id, amount, transaction_time, type, description
+			id, task_id, amount, currency, transaction_time, type, description
 		) VALUES (
-			:id, :task_id, :amount, :transaction_time, :type, :description
+			:id, :task_id, :amount, :currency, :transaction_time, :type, :description
 		)`
 
 	records := make([]UnmatchedTransaction, len(unmatchedTxns))
@@ -135,6 +204,7 @@ func (r *DBReconResultRepository) insertUnmatchedTransactionsBatch(ctx context.C
 			ID:              txn.ID,
 			TaskID:          taskID,
 			Amount:          txn.Amount,
+			Currency:        txn.Amount.Currency(),
 			TransactionTime: txn.TransactionTime,
 			Type:            txn.Type,
 			Description:     txn.Description,
@@ -167,19 +237,21 @@ func (r *DBReconResultRepository) insertUnmatchedBankStatements(ctx context.Cont
 func (r *DBReconResultRepository) insertUnmatchedBankStatementsBatch(ctx context.Context, tx *sqlx.Tx, taskID string, unmatchedStmts []model.BankStatement) error {
 	query := `
 		INSERT INTO unmatched_bank_statements (
-			task_id, amount, date, reference, bank_name
+			task_id, bank_statement_id, amount, currency, date, reference, bank_name
 		) VALUES (
-			:task_id, :amount, :date, :reference, :bank_name
+			:task_id, :bank_statement_id, :amount, :currency, :date, :reference, :bank_name
 		)`
 
 	records := make([]UnmatchedBankStatement, len(unmatchedStmts))
 	for j, stmt := range unmatchedStmts {
 		records[j] = UnmatchedBankStatement{
-			TaskID:    taskID,
-			Amount:    stmt.Amount,
-			Date:      stmt.Date,
-			Reference: stmt.Reference,
-			BankName:  stmt.BankName,
+			TaskID:          taskID,
+			BankStatementID: stmt.ID,
+			Amount:          stmt.Amount,
+			Currency:        stmt.Amount.Currency(),
+			Date:            stmt.Date,
+			Reference:       stmt.Reference,
+			BankName:        stmt.BankName,
 		}
 	}
 
@@ -191,6 +263,136 @@ func (r *DBReconResultRepository) insertUnmatchedBankStatementsBatch(ctx context
 	return nil
 }
 
+// insertAggregateMatches stores the grouping of every many-to-one and
+// one-to-many match and returns the group ID assigned to each match index.
+func (r *DBReconResultRepository) insertAggregateMatches(ctx context.Context, tx *sqlx.Tx, taskID string, matches []model.Match) (map[int]string, error) {
+	groupIDs := make(map[int]string)
+	var groups []AggregateMatch
+	var items []AggregateMatchItem
+	for i, match := range matches {
+		// One-to-one matches have no grouping to record
+		if match.Type == model.MatchOneToOne {
+			continue
+		}
+
+		groupID := uuid.New().String()
+		groupIDs[i] = groupID
+
+		// The group amount is the single record the group settles against
+		var amount model.Money
+		if match.Type == model.MatchManyToOne && len(match.BankStatements) > 0 {
+			amount = match.BankStatements[0].Amount
+		} else if len(match.Transactions) > 0 {
+			amount = match.Transactions[0].SignedAmount()
+		}
+
+		for _, txn := range match.Transactions {
+			items = append(items, AggregateMatchItem{
+				AggregateMatchID: groupID,
+				RecordType:       RecordTypeTransaction,
+				RecordID:         txn.ID,
+				Amount:           txn.SignedAmount(),
+				Currency:         txn.Amount.Currency(),
+				Date:             txn.TransactionTime,
+			})
+		}
+		for _, stmt := range match.BankStatements {
+			items = append(items, AggregateMatchItem{
+				AggregateMatchID: groupID,
+				RecordType:       RecordTypeBankStatement,
+				RecordID:         stmt.ID,
+				Amount:           stmt.Amount,
+				Currency:         stmt.Amount.Currency(),
+				Date:             stmt.Date,
+			})
+		}
+
+		groups = append(groups, AggregateMatch{
+			ID:        groupID,
+			TaskID:    taskID,
+			MatchType: match.Type,
+			Rule:      match.Rule,
+			Amount:    amount,
+			Currency:  amount.Currency(),
+		})
+	}
+
+	for _, chunk := range utils.ChunkSlice(groups, batchSize) {
+		_, err := tx.NamedExecContext(ctx, `
+			INSERT INTO aggregate_matches (
+				id, task_id, match_type, rule, amount, currency
+			) VALUES (
+				:id, :task_id, :match_type, :rule, :amount, :currency
+			)`, chunk)
+		if err != nil {
+			return nil, errors.Wrap(err, "[insertAggregateMatches] error inserting aggregate matches")
+		}
+	}
+
+	for _, chunk := range utils.ChunkSlice(items, batchSize) {
+		_, err := tx.NamedExecContext(ctx, `
+			INSERT INTO aggregate_match_items (
+				aggregate_match_id, record_type, record_id, amount, currency, date
+			) VALUES (
+				:aggregate_match_id, :record_type, :record_id, :amount, :currency, :date
+			)`, chunk)
+		if err != nil {
+			return nil, errors.Wrap(err, "[insertAggregateMatches] error inserting aggregate match items")
+		}
+	}
+
+	return groupIDs, nil
+}
+
+// insertMatchedPairs stores every transaction and bank statement pairing.
+// Aggregate matches are expanded into one pair per member, linked to their group.
+func (r *DBReconResultRepository) insertMatchedPairs(ctx context.Context, tx *sqlx.Tx, taskID string, matches []model.Match, groupIDs map[int]string) error {
+	var pairs []MatchedPair
+	for i, match := range matches {
+		groupID, grouped := groupIDs[i]
+		for _, txn := range match.Transactions {
+			for _, stmt := range match.BankStatements {
+				pairs = append(pairs, MatchedPair{
+					TaskID:              taskID,
+					Rule:                match.Rule,
+					MatchType:           match.Type,
+					AggregateMatchID:    sql.NullString{String: groupID, Valid: grouped},
+					TransactionID:       txn.ID,
+					TransactionAmount:   txn.SignedAmount(),
+					TransactionCurrency: txn.Amount.Currency(),
+					TransactionTime:     txn.TransactionTime,
+					BankStatementID:     stmt.ID,
+					BankAmount:          stmt.Amount,
+					BankCurrency:        stmt.Amount.Currency(),
+					BankDate:            stmt.Date,
+					BankReference:       sql.NullString{String: stmt.Reference, Valid: stmt.Reference != ""},
+					AmountDifference:    match.Difference,
+				})
+			}
+		}
+	}
+
+	for _, chunk := range utils.ChunkSlice(pairs, batchSize) {
+		_, err := tx.NamedExecContext(ctx, `
+			INSERT INTO matched_pairs (
+				task_id, rule, match_type, aggregate_match_id,
+				transaction_id, transaction_amount, transaction_currency, transaction_time,
+				bank_statement_id, bank_amount, bank_currency, bank_date, bank_reference,
+				amount_difference
+			) VALUES (
+				:task_id, :rule, :match_type, :aggregate_match_id,
+				:transaction_id, :transaction_amount, :transaction_currency, :transaction_time,
+				:bank_statement_id, :bank_amount, :bank_currency, :bank_date, :bank_reference,
+				:amount_difference
+			)`, chunk)
+		if err != nil {
+			return errors.Wrap(err, "[insertMatchedPairs] error inserting matched pairs")
+		}
+	}
+
+	return nil
+}
+
 func (r *DBReconResultRepository) GetUnmatchedTransactions(ctx context.Context, taskID string, limit, offset int) ([]UnmatchedTransaction, int, error) {
 	var transactions []UnmatchedTransaction
 	err := r.db.SelectContext(ctx, &transactions, `
@@ -235,6 +437,75 @@ func (r *DBReconResultRepository) GetUnmatchedBankStatements(ctx context.Context
 	return statements, total, nil
 }
 
+func (r *DBReconResultRepository) GetMatchedPairs(ctx context.Context, taskID string, limit, offset int) ([]MatchedPair, int, error) {
+	var pairs []MatchedPair
+	err := r.db.SelectContext(ctx, &pairs, `
+		SELECT * FROM matched_pairs 
+		WHERE task_id = $1
+		ORDER BY transaction_time DESC, id
+		LIMIT $2 OFFSET $3`, taskID, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var total int
+	err = r.db.GetContext(ctx, &total, `
+		SELECT COUNT(*) FROM matched_pairs 
+		WHERE task_id = $1`, taskID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return pairs, total, nil
+}
+
+func (r *DBReconResultRepository) GetAggregateMatches(ctx context.Context, taskID string, limit, offset int) ([]AggregateMatch, int, error) {
+	var matches []AggregateMatch
+	err := r.db.SelectContext(ctx, &matches, `
+		SELECT id, task_id, match_type, rule, amount, currency, created_at FROM aggregate_matches 
+		WHERE task_id = $1
+		ORDER BY created_at DESC, id
+		LIMIT $2 OFFSET $3`, taskID, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var total int
+	err = r.db.GetContext(ctx, &total, `
+		SELECT COUNT(*) FROM aggregate_matches 
+		WHERE task_id = $1`, taskID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if len(matches) == 0 {
+		return matches, total, nil
+	}
+
+	matchIDs := make([]string, len(matches))
+	matchIndex := make(map[string]int, len(matches))
+	for i, match := range matches {
+		matchIDs[i] = match.ID
+		matchIndex[match.ID] = i
+	}
+
+	var items []AggregateMatchItem
+	err = r.db.SelectContext(ctx, &items, `
+		SELECT * FROM aggregate_match_items 
+		WHERE aggregate_match_id = ANY($1)
+		ORDER BY id`, pq.Array(matchIDs))
+	if err != nil {
+		return nil, 0, err
+	}
+
+	for _, item := range items {
+		i := matchIndex[item.AggregateMatchID]
+		matches[i].Items = append(matches[i].Items, item)
+	}
+
+	return matches, total, nil
+}
+
 func (r *DBReconResultRepository) ListSummaries(ctx context.Context, limit, offset int) ([]ReconSummary, int, error) {
 	var summaries []ReconSummary
 	err := r.db.SelectContext(ctx, &summaries, `
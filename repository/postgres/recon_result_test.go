@@ -33,12 +33,12 @@ func TestDBReconResultRepository_StoreSummary(t *testing.T) {
 		summary := model.ReconciliationSummary{
 			TaskID:           taskID,
 			TotalMatched:     10,
-			TotalDiscrepancy: 150.75,
+			TotalDiscrepancy: money("150.75"),
 			TotalTransaction: 15,
 			UnmatchedInternal: []model.Transaction{
 				{
 					ID:              "tx1",
-					Amount:          100.50,
+					Amount:          money("100.50"),
 					TransactionTime: time.Date(2023, 1, 15, 12, 0, 0, 0, time.UTC),
 					Type:            "CREDIT",
 					Description:     "Test Transaction 1",
@@ -47,7 +47,7 @@ func TestDBReconResultRepository_StoreSummary(t *testing.T) {
 			UnmatchedBank: []model.BankStatement{
 				{
 					ID:        "bs-1",
-					Amount:    200.25,
+					Amount:    money("200.25"),
 					Date:      time.Date(2023, 1, 20, 0, 0, 0, 0, time.UTC),
 					Reference: "REF123",
 					BankName:  "Test Bank",
@@ -58,6 +58,11 @@ func TestDBReconResultRepository_StoreSummary(t *testing.T) {
 		// Setup expectations
 		mock.ExpectBegin()
 
+		// Results of an earlier run are replaced
+		mock.ExpectExec("DELETE FROM recon_summary").
+			WithArgs(taskID).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
 		// Summary insert - using a simpler pattern match
 		mock.ExpectExec("INSERT INTO recon_summary").
 			WillReturnResult(sqlmock.NewResult(1, 1))
@@ -66,8 +71,9 @@ func TestDBReconResultRepository_StoreSummary(t *testing.T) {
 		mock.ExpectExec("INSERT INTO unmatched_transactions").
 			WillReturnResult(sqlmock.NewResult(1, 1))
 
-		// Unmatched bank statements insert
+		// Unmatched bank statements insert, keeping the ID of the statement
 		mock.ExpectExec("INSERT INTO unmatched_bank_statements").
+			WithArgs(taskID, "bs-1", "200.25", "", time.Date(2023, 1, 20, 0, 0, 0, 0, time.UTC), "REF123", "Test Bank").
 			WillReturnResult(sqlmock.NewResult(1, 1))
 
 		mock.ExpectCommit()
@@ -85,7 +91,7 @@ func TestDBReconResultRepository_StoreSummary(t *testing.T) {
 		summary := model.ReconciliationSummary{
 			TaskID:            taskID,
 			TotalMatched:      10,
-			TotalDiscrepancy:  0,
+			TotalDiscrepancy:  money("0.00"),
 			TotalTransaction:  10,
 			UnmatchedInternal: []model.Transaction{},
 			UnmatchedBank:     []model.BankStatement{},
@@ -94,6 +100,11 @@ func TestDBReconResultRepository_StoreSummary(t *testing.T) {
 		// Setup expectations
 		mock.ExpectBegin()
 
+		// Results of an earlier run are replaced
+		mock.ExpectExec("DELETE FROM recon_summary").
+			WithArgs(taskID).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
 		// Summary insert
 		mock.ExpectExec("INSERT INTO recon_summary").
 			WillReturnResult(sqlmock.NewResult(1, 1))
@@ -108,6 +119,108 @@ func TestDBReconResultRepository_StoreSummary(t *testing.T) {
 		assert.NoError(t, mock.ExpectationsWereMet())
 	})
 
+	t.Run("Successfully store summary with aggregate matches", func(t *testing.T) {
+		// Create test data with one many-to-one group, one-to-one matches are not grouped
+		summary := model.ReconciliationSummary{
+			TaskID:           taskID,
+			TotalMatched:     1,
+			TotalTransaction: 1,
+			Matches: []model.Match{
+				{
+					Rule: "aggregate",
+					Type: model.MatchManyToOne,
+					Transactions: []model.Transaction{
+						{ID: "tx1", Amount: money("40.00"), Type: "CREDIT", TransactionTime: time.Date(2023, 1, 10, 0, 0, 0, 0, time.UTC)},
+						{ID: "tx2", Amount: money("60.00"), Type: "CREDIT", TransactionTime: time.Date(2023, 1, 11, 0, 0, 0, 0, time.UTC)},
+					},
+					BankStatements: []model.BankStatement{
+						{ID: "bs-1", Amount: money("100.00"), Date: time.Date(2023, 1, 12, 0, 0, 0, 0, time.UTC)},
+					},
+				},
+				{
+					Rule:           "amount_date",
+					Type:           model.MatchOneToOne,
+					Transactions:   []model.Transaction{{ID: "tx3", Amount: money("10.00"), Type: "CREDIT"}},
+					BankStatements: []model.BankStatement{{ID: "bs-2", Amount: money("10.00")}},
+				},
+			},
+		}
+
+		// Setup expectations
+		mock.ExpectBegin()
+
+		// Results of an earlier run are replaced
+		mock.ExpectExec("DELETE FROM recon_summary").
+			WithArgs(taskID).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		mock.ExpectExec("INSERT INTO recon_summary").
+			WillReturnResult(sqlmock.NewResult(1, 1))
+
+		// Group insert
+		mock.ExpectExec("INSERT INTO aggregate_matches").
+			WithArgs(sqlmock.AnyArg(), taskID, model.MatchManyToOne, "aggregate", "100.00", "").
+			WillReturnResult(sqlmock.NewResult(1, 1))
+
+		// Items insert, two transactions and one bank statement
+		mock.ExpectExec("INSERT INTO aggregate_match_items").
+			WillReturnResult(sqlmock.NewResult(1, 3))
+
+		// Two pairs from the group plus the one-to-one match
+		mock.ExpectExec("INSERT INTO matched_pairs").
+			WillReturnResult(sqlmock.NewResult(1, 3))
+
+		mock.ExpectCommit()
+
+		// Execute
+		err := repo.StoreSummary(ctx, summary, startDate, endDate)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Error inserting matched pairs", func(t *testing.T) {
+		// Create test data with a single one-to-one match
+		summary := model.ReconciliationSummary{
+			TaskID:       taskID,
+			TotalMatched: 1,
+			Matches: []model.Match{
+				{
+					Rule:           "exact_reference",
+					Type:           model.MatchOneToOne,
+					Transactions:   []model.Transaction{{ID: "tx1", Amount: money("10.00"), Type: "CREDIT"}},
+					BankStatements: []model.BankStatement{{ID: "bs-1", Amount: money("10.00"), Reference: "tx1"}},
+				},
+			},
+		}
+
+		// Setup expectations
+		mock.ExpectBegin()
+
+		// Results of an earlier run are replaced
+		mock.ExpectExec("DELETE FROM recon_summary").
+			WithArgs(taskID).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		mock.ExpectExec("INSERT INTO recon_summary").
+			WillReturnResult(sqlmock.NewResult(1, 1))
+
+		// Matched pairs insert fails
+		mock.ExpectExec("INSERT INTO matched_pairs").
+			WillReturnError(errors.New("matched pairs insert error"))
+
+		mock.ExpectRollback()
+
+		// Execute
+		err := repo.StoreSummary(ctx, summary, startDate, endDate)
+
+		// Assert
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "matched pairs insert error")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
 	t.Run("Error beginning transaction", func(t *testing.T) {
 		// Setup expectations - transaction will fail to begin
 		mock.ExpectBegin().WillReturnError(errors.New("db connection error"))
@@ -116,7 +229,7 @@ func TestDBReconResultRepository_StoreSummary(t *testing.T) {
 		summary := model.ReconciliationSummary{
 			TaskID:           taskID,
 			TotalMatched:     10,
-			TotalDiscrepancy: 150.75,
+			TotalDiscrepancy: money("150.75"),
 		}
 
 		// Execute
@@ -128,17 +241,48 @@ func TestDBReconResultRepository_StoreSummary(t *testing.T) {
 		assert.NoError(t, mock.ExpectationsWereMet())
 	})
 
+	t.Run("Error deleting previous results", func(t *testing.T) {
+		// Create test data
+		summary := model.ReconciliationSummary{
+			TaskID:           taskID,
+			TotalMatched:     10,
+			TotalDiscrepancy: money("150.75"),
+		}
+
+		// Setup expectations
+		mock.ExpectBegin()
+
+		mock.ExpectExec("DELETE FROM recon_summary").
+			WithArgs(taskID).
+			WillReturnError(errors.New("delete error"))
+
+		mock.ExpectRollback()
+
+		// Execute
+		err := repo.StoreSummary(ctx, summary, startDate, endDate)
+
+		// Assert
+		assert.Error(t, err)
+		assert.Equal(t, "delete error", err.Error())
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
 	t.Run("Error inserting summary", func(t *testing.T) {
 		// Create test data
 		summary := model.ReconciliationSummary{
 			TaskID:           taskID,
 			TotalMatched:     10,
-			TotalDiscrepancy: 150.75,
+			TotalDiscrepancy: money("150.75"),
 		}
 
 		// Setup expectations
 		mock.ExpectBegin()
 
+		// Results of an earlier run are replaced
+		mock.ExpectExec("DELETE FROM recon_summary").
+			WithArgs(taskID).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
 		// Summary insert fails
 		mock.ExpectExec("INSERT INTO recon_summary").
 			WillReturnError(errors.New("insert error"))
@@ -159,11 +303,11 @@ func TestDBReconResultRepository_StoreSummary(t *testing.T) {
 		summary := model.ReconciliationSummary{
 			TaskID:           taskID,
 			TotalMatched:     10,
-			TotalDiscrepancy: 150.75,
+			TotalDiscrepancy: money("150.75"),
 			UnmatchedInternal: []model.Transaction{
 				{
 					ID:              "tx1",
-					Amount:          100.50,
+					Amount:          money("100.50"),
 					TransactionTime: time.Now(),
 				},
 			},
@@ -172,6 +316,11 @@ func TestDBReconResultRepository_StoreSummary(t *testing.T) {
 		// Setup expectations
 		mock.ExpectBegin()
 
+		// Results of an earlier run are replaced
+		mock.ExpectExec("DELETE FROM recon_summary").
+			WithArgs(taskID).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
 		// Summary insert succeeds
 		mock.ExpectExec("INSERT INTO recon_summary").
 			WillReturnResult(sqlmock.NewResult(1, 1))
@@ -196,12 +345,12 @@ func TestDBReconResultRepository_StoreSummary(t *testing.T) {
 		summary := model.ReconciliationSummary{
 			TaskID:            taskID,
 			TotalMatched:      10,
-			TotalDiscrepancy:  150.75,
+			TotalDiscrepancy:  money("150.75"),
 			UnmatchedInternal: []model.Transaction{},
 			UnmatchedBank: []model.BankStatement{
 				{
 					ID:     "bs2",
-					Amount: 200.25,
+					Amount: money("200.25"),
 					Date:   time.Date(2023, 1, 20, 0, 0, 0, 0, time.UTC),
 				},
 			},
@@ -210,6 +359,11 @@ func TestDBReconResultRepository_StoreSummary(t *testing.T) {
 		// Setup expectations
 		mock.ExpectBegin()
 
+		// Results of an earlier run are replaced
+		mock.ExpectExec("DELETE FROM recon_summary").
+			WithArgs(taskID).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
 		// Summary insert succeeds
 		mock.ExpectExec("INSERT INTO recon_summary").
 			WillReturnResult(sqlmock.NewResult(1, 1))
@@ -234,7 +388,7 @@ func TestDBReconResultRepository_StoreSummary(t *testing.T) {
 		summary := model.ReconciliationSummary{
 			TaskID:            taskID,
 			TotalMatched:      10,
-			TotalDiscrepancy:  123.45, // Specific value to test
+			TotalDiscrepancy:  money("123.45"), // Specific value to test
 			TotalTransaction:  15,
 			UnmatchedInternal: []model.Transaction{},
 			UnmatchedBank:     []model.BankStatement{},
@@ -243,6 +397,11 @@ func TestDBReconResultRepository_StoreSummary(t *testing.T) {
 		// Setup expectations
 		mock.ExpectBegin()
 
+		// Results of an earlier run are replaced
+		mock.ExpectExec("DELETE FROM recon_summary").
+			WithArgs(taskID).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
 		// Use a more flexible match for the SQL, just verify it contains the table name
 		mock.ExpectExec("INSERT INTO recon_summary").
 			WillReturnResult(sqlmock.NewResult(1, 1))
@@ -262,7 +421,7 @@ func TestDBReconResultRepository_StoreSummary(t *testing.T) {
 		summary := model.ReconciliationSummary{
 			TaskID:           taskID,
 			TotalMatched:     10,
-			TotalDiscrepancy: 150.75,
+			TotalDiscrepancy: money("150.75"),
 			TotalTransaction: 1500,
 		}
 
@@ -270,7 +429,7 @@ func TestDBReconResultRepository_StoreSummary(t *testing.T) {
 		for i := 0; i < 1500; i++ {
 			summary.UnmatchedInternal = append(summary.UnmatchedInternal, model.Transaction{
 				ID:              fmt.Sprintf("tx%d", i),
-				Amount:          float64(i) + 0.5,
+				Amount:          model.NewMoney(int64(i)*100+50, ""),
 				TransactionTime: time.Now().Add(time.Duration(i) * time.Hour),
 				Type:            "CREDIT",
 				Description:     fmt.Sprintf("Test Transaction %d", i),
@@ -280,6 +439,11 @@ func TestDBReconResultRepository_StoreSummary(t *testing.T) {
 		// Setup expectations
 		mock.ExpectBegin()
 
+		// Results of an earlier run are replaced
+		mock.ExpectExec("DELETE FROM recon_summary").
+			WithArgs(taskID).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
 		// Summary insert
 		mock.ExpectExec("INSERT INTO recon_summary").
 			WillReturnResult(sqlmock.NewResult(1, 1))
@@ -302,3 +466,8 @@ func TestDBReconResultRepository_StoreSummary(t *testing.T) {
 		assert.NoError(t, mock.ExpectationsWereMet())
 	})
 }
+
+// money parses a test amount without a currency
+func money(amount string) model.Money {
+	return model.MustParseMoney(amount, "")
+}
@@ -0,0 +1,105 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/aferryc/yars/model"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+)
+
+func NewDBReconTaskRepository(db *sqlx.DB) *DBReconTaskRepository {
+	return &DBReconTaskRepository{
+		db: db,
+	}
+}
+
+type DBReconTaskRepository struct {
+	db *sqlx.DB
+}
+
+type ReconTask struct {
+	TaskID       string         `db:"id"`
+	Status       string         `db:"status"`
+	ErrorMessage sql.NullString `db:"error_message"`
+	CreatedAt    time.Time      `db:"created_at"`
+	UpdatedAt    time.Time      `db:"updated_at"`
+	FinishedAt   sql.NullTime   `db:"finished_at"`
+}
+
+// Create stores a new task in the CREATED state
+func (r *DBReconTaskRepository) Create(ctx context.Context, taskID string) error {
+	_, err := conn(ctx, r.db).ExecContext(ctx, `
+		INSERT INTO recon_task (id, status, created_at, updated_at)
+		VALUES ($1, $2, NOW(), NOW())`, taskID, model.TaskCreated)
+	if err != nil {
+		return errors.Wrap(err, "[CreateTask] error creating task")
+	}
+	return nil
+}
+
+// UpdateStatus moves the task to status. The current status is checked in the
+// same statement so concurrent consumers cannot apply conflicting transitions.
+func (r *DBReconTaskRepository) UpdateStatus(ctx context.Context, taskID string, status model.TaskStatus, message string) error {
+	previous := make([]string, 0, len(status.PreviousStates()))
+	for _, state := range status.PreviousStates() {
+		previous = append(previous, string(state))
+	}
+
+	result, err := conn(ctx, r.db).ExecContext(ctx, `
+		UPDATE recon_task SET
+			status = $2,
+			error_message = NULLIF($3, ''),
+			updated_at = NOW(),
+			finished_at = CASE WHEN $4 THEN NOW() ELSE NULL END
+		WHERE id = $1 AND status = ANY($5)`,
+		taskID, status, message, status.IsFinished(), pq.Array(previous))
+	if err != nil {
+		return errors.Wrap(err, "[UpdateTaskStatus] error updating task status")
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return errors.Wrap(err, "[UpdateTaskStatus] error reading affected rows")
+	}
+	if affected > 0 {
+		return nil
+	}
+
+	// Nothing was updated, either the task does not exist or it is in a
+	// state the transition is not allowed from
+	current, err := r.Get(ctx, taskID)
+	if err != nil {
+		return err
+	}
+	return errors.Wrapf(model.ErrInvalidTaskTransition, "[UpdateTaskStatus] task %s from %s to %s", taskID, current.Status, status)
+}
+
+// Get returns the task, or model.ErrTaskNotFound
+func (r *DBReconTaskRepository) Get(ctx context.Context, taskID string) (model.ReconTask, error) {
+	var record ReconTask
+	err := conn(ctx, r.db).GetContext(ctx, &record, `
+		SELECT id, status, error_message, created_at, updated_at, finished_at
+		FROM recon_task WHERE id = $1`, taskID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return model.ReconTask{}, model.ErrTaskNotFound
+		}
+		return model.ReconTask{}, errors.Wrap(err, "[GetTask] error fetching task")
+	}
+
+	task := model.ReconTask{
+		TaskID:    record.TaskID,
+		Status:    model.TaskStatus(record.Status),
+		Error:     record.ErrorMessage.String,
+		CreatedAt: record.CreatedAt,
+		UpdatedAt: record.UpdatedAt,
+	}
+	if record.FinishedAt.Valid {
+		task.FinishedAt = &record.FinishedAt.Time
+	}
+	return task, nil
+}
@@ -0,0 +1,122 @@
+package postgres_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/aferryc/yars/model"
+	"github.com/aferryc/yars/repository/postgres"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDBReconTaskRepository_UpdateStatus(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+	repo := postgres.NewDBReconTaskRepository(sqlxDB)
+
+	ctx := context.Background()
+	taskID := "test-task-id"
+	taskColumns := []string{"id", "status", "error_message", "created_at", "updated_at", "finished_at"}
+	now := time.Date(2023, 1, 15, 12, 0, 0, 0, time.UTC)
+
+	t.Run("Allowed transition", func(t *testing.T) {
+		// Setup expectations
+		mock.ExpectExec("UPDATE recon_task SET").
+			WithArgs(taskID, model.TaskCompiled, "", false, pq.Array([]string{"COMPILING"})).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		// Execute
+		err := repo.UpdateStatus(ctx, taskID, model.TaskCompiled, "")
+
+		// Assert
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Failure records the message", func(t *testing.T) {
+		// Setup expectations
+		mock.ExpectExec("UPDATE recon_task SET").
+			WithArgs(taskID, model.TaskFailed, "download failed", true, sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		// Execute
+		err := repo.UpdateStatus(ctx, taskID, model.TaskFailed, "download failed")
+
+		// Assert
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Transition not allowed", func(t *testing.T) {
+		// Setup expectations
+		mock.ExpectExec("UPDATE recon_task SET").
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery("SELECT (.+) FROM recon_task").
+			WithArgs(taskID).
+			WillReturnRows(sqlmock.NewRows(taskColumns).AddRow(taskID, "COMPLETED", nil, now, now, now))
+
+		// Execute
+		err := repo.UpdateStatus(ctx, taskID, model.TaskCompiling, "")
+
+		// Assert
+		assert.True(t, errors.Is(err, model.ErrInvalidTaskTransition))
+		assert.Contains(t, err.Error(), "from COMPLETED to COMPILING")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Unknown task", func(t *testing.T) {
+		// Setup expectations
+		mock.ExpectExec("UPDATE recon_task SET").
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery("SELECT (.+) FROM recon_task").
+			WithArgs(taskID).
+			WillReturnError(sql.ErrNoRows)
+
+		// Execute
+		err := repo.UpdateStatus(ctx, taskID, model.TaskUploaded, "")
+
+		// Assert
+		assert.True(t, errors.Is(err, model.ErrTaskNotFound))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestDBReconTaskRepository_Get(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+	repo := postgres.NewDBReconTaskRepository(sqlxDB)
+
+	createdAt := time.Date(2023, 1, 15, 12, 0, 0, 0, time.UTC)
+	finishedAt := createdAt.Add(time.Minute)
+
+	// Setup expectations
+	mock.ExpectQuery("SELECT (.+) FROM recon_task WHERE id = \\$1").
+		WithArgs("test-task-id").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "status", "error_message", "created_at", "updated_at", "finished_at"}).
+			AddRow("test-task-id", "FAILED", "download failed", createdAt, finishedAt, finishedAt))
+
+	// Execute
+	task, err := repo.Get(context.Background(), "test-task-id")
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, model.TaskFailed, task.Status)
+	assert.Equal(t, "download failed", task.Error)
+	assert.Equal(t, createdAt, task.CreatedAt)
+	require.NotNil(t, task.FinishedAt)
+	assert.Equal(t, finishedAt, *task.FinishedAt)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,48 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/aferryc/yars/model"
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+)
+
+func NewDBStatementRepository(db *sqlx.DB) *DBStatementRepository {
+	return &DBStatementRepository{
+		db: db,
+	}
+}
+
+type DBStatementRepository struct {
+	db *sqlx.DB
+}
+
+// SaveBalances stores the opening and closing balances of the statements,
+// replacing those already saved for the same task, bank, account, reference
+// and number. The entries of the statements are not saved.
+func (r *DBStatementRepository) SaveBalances(ctx context.Context, statements []model.Statement) error {
+	return NewDBTransactor(r.db).WithinTx(ctx, func(ctx context.Context) error {
+		for _, statement := range statements {
+			_, err := conn(ctx, r.db).ExecContext(ctx, `
+				INSERT INTO statement_balances (
+					task_id, bank, account, reference, number, currency,
+					opening_date, opening_balance, closing_date, closing_balance
+				) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+				ON CONFLICT (task_id, bank, account, reference, number) DO UPDATE SET
+					currency = EXCLUDED.currency,
+					opening_date = EXCLUDED.opening_date,
+					opening_balance = EXCLUDED.opening_balance,
+					closing_date = EXCLUDED.closing_date,
+					closing_balance = EXCLUDED.closing_balance`,
+				statement.TaskID, statement.BankName, statement.Account, statement.Reference, statement.Number,
+				statement.OpeningBalance.Amount.Currency(),
+				statement.OpeningBalance.Date, statement.OpeningBalance.Amount,
+				statement.ClosingBalance.Date, statement.ClosingBalance.Amount)
+			if err != nil {
+				return errors.Wrapf(err, "[SaveStatementBalances] error saving balances of statement %s", statement.Reference)
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,68 @@
+package postgres_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/aferryc/yars/model"
+	"github.com/aferryc/yars/repository/postgres"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDBStatementRepository_SaveBalances(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+	repo := postgres.NewDBStatementRepository(sqlxDB)
+
+	ctx := context.Background()
+	opening := time.Date(2023, 1, 14, 0, 0, 0, 0, time.UTC)
+	closing := time.Date(2023, 1, 16, 0, 0, 0, 0, time.UTC)
+	statements := []model.Statement{{
+		Reference:      "STMT-1",
+		Account:        "NL91ABNA0417164300",
+		Number:         "00042/1",
+		OpeningBalance: model.Balance{Date: opening, Amount: model.MustParseMoney("1234.56", "EUR")},
+		ClosingBalance: model.Balance{Date: closing, Amount: model.MustParseMoney("-754.81", "EUR")},
+		TaskID:         "task-1",
+		BankName:       "TestBank",
+	}}
+
+	t.Run("Upserts the balances", func(t *testing.T) {
+		// Setup expectations
+		mock.ExpectBegin()
+		mock.ExpectExec("INSERT INTO statement_balances .* ON CONFLICT \\(task_id, bank, account, reference, number\\)").
+			WithArgs("task-1", "TestBank", "NL91ABNA0417164300", "STMT-1", "00042/1", "EUR",
+				opening, "1234.56", closing, "-754.81").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		// Execute
+		err := repo.SaveBalances(ctx, statements)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Rolls back on error", func(t *testing.T) {
+		// Setup expectations
+		mock.ExpectBegin()
+		mock.ExpectExec("INSERT INTO statement_balances").WillReturnError(errors.New("database error"))
+		mock.ExpectRollback()
+
+		// Execute
+		err := repo.SaveBalances(ctx, statements)
+
+		// Assert
+		assert.ErrorContains(t, err, "database error")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
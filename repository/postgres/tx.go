@@ -0,0 +1,63 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type txKey struct{}
+
+func NewDBTransactor(db *sqlx.DB) *DBTransactor {
+	return &DBTransactor{
+		db: db,
+	}
+}
+
+// DBTransactor runs functions in a database transaction. The repositories of
+// this package run their statements in the transaction of the context they
+// are given, so writes of several repositories are committed together.
+type DBTransactor struct {
+	db *sqlx.DB
+}
+
+// WithinTx runs fn in a transaction that is committed when fn returns nil and
+// rolled back otherwise. Called within a transaction, fn joins it.
+func (t *DBTransactor) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := txFromContext(ctx); ok {
+		return fn(ctx)
+	}
+
+	tx, err := t.db.BeginTxx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return err
+	}
+
+	if err := fn(context.WithValue(ctx, txKey{}, tx)); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func txFromContext(ctx context.Context) (*sqlx.Tx, bool) {
+	tx, ok := ctx.Value(txKey{}).(*sqlx.Tx)
+	return tx, ok
+}
+
+// querier is implemented by both sqlx.DB and sqlx.Tx
+type querier interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	GetContext(ctx context.Context, dest any, query string, args ...any) error
+	SelectContext(ctx context.Context, dest any, query string, args ...any) error
+}
+
+// conn returns the transaction of ctx, or db outside of a transaction
+func conn(ctx context.Context, db *sqlx.DB) querier {
+	if tx, ok := txFromContext(ctx); ok {
+		return tx
+	}
+	return db
+}
@@ -0,0 +1,154 @@
+package rangereader
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/aferryc/yars/model"
+)
+
+const (
+	// DefaultMaxResumes is how many times a read is resumed after the
+	// connection drops before the error is returned
+	DefaultMaxResumes = 3
+	// ResumeBackoff is the wait before the first resume, growing with every
+	// resume after it
+	ResumeBackoff = 500 * time.Millisecond
+)
+
+// ErrObjectChanged is returned when the object is replaced while being read,
+// so the rest of it cannot be resumed
+var ErrObjectChanged = errors.New("object changed while reading")
+
+// NewRequestFunc builds the GET request for the object. It is called for the
+// first read and for every resume, so a presigned URL can be signed again.
+type NewRequestFunc func(ctx context.Context) (*http.Request, error)
+
+// Reader streams an object over HTTP. When the connection drops, it resumes
+// with a range request from the first byte not read yet, failing with
+// ErrObjectChanged if the object was replaced in the meantime.
+type Reader struct {
+	ctx        context.Context
+	client     *http.Client
+	newRequest NewRequestFunc
+	maxResumes int
+
+	body    io.ReadCloser
+	offset  int64
+	etag    string
+	resumes int
+}
+
+// New sends the first request, so a missing object fails here rather than on
+// the first read
+func New(ctx context.Context, client *http.Client, newRequest NewRequestFunc, maxResumes int) (*Reader, error) {
+	r := &Reader{
+		ctx:        ctx,
+		client:     client,
+		newRequest: newRequest,
+		maxResumes: maxResumes,
+	}
+	if err := r.open(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *Reader) Read(p []byte) (int, error) {
+	for {
+		n, err := r.body.Read(p)
+		r.offset += int64(n)
+		if err == nil || errors.Is(err, io.EOF) {
+			return n, err
+		}
+		if n > 0 {
+			// Return what was read, the next call hits the error again
+			return n, nil
+		}
+		if ctxErr := r.ctx.Err(); ctxErr != nil {
+			return 0, ctxErr
+		}
+		if r.resumes >= r.maxResumes {
+			return 0, fmt.Errorf("error reading object after %d resumes: %w", r.resumes, err)
+		}
+
+		r.resumes++
+		log.Printf("Error reading object at byte %d, resuming (%d/%d): %v", r.offset, r.resumes, r.maxResumes, err)
+		r.body.Close()
+		if err := r.wait(); err != nil {
+			return 0, err
+		}
+		if err := r.open(); err != nil {
+			return 0, err
+		}
+	}
+}
+
+func (r *Reader) Close() error {
+	return r.body.Close()
+}
+
+// open requests the object from the current offset
+func (r *Reader) open() error {
+	req, err := r.newRequest(r.ctx)
+	if err != nil {
+		return fmt.Errorf("error creating request: %w", err)
+	}
+	if r.offset > 0 {
+		req.Header.Set("Range", "bytes="+strconv.FormatInt(r.offset, 10)+"-")
+		if r.etag != "" {
+			req.Header.Set("If-Match", r.etag)
+		}
+	}
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("error downloading file: %w", err)
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusOK && r.offset == 0:
+		r.etag = resp.Header.Get("ETag")
+	case resp.StatusCode == http.StatusPartialContent && r.offset > 0:
+	case resp.StatusCode == http.StatusOK:
+		// The range was ignored, skip what was already read
+		if r.etag != "" && resp.Header.Get("ETag") != r.etag {
+			resp.Body.Close()
+			return ErrObjectChanged
+		}
+		if _, err := io.CopyN(io.Discard, resp.Body, r.offset); err != nil {
+			resp.Body.Close()
+			return fmt.Errorf("error skipping to byte %d: %w", r.offset, err)
+		}
+	case resp.StatusCode == http.StatusNotFound:
+		resp.Body.Close()
+		return model.ErrObjectNotFound
+	case resp.StatusCode == http.StatusPreconditionFailed:
+		resp.Body.Close()
+		return ErrObjectChanged
+	default:
+		resp.Body.Close()
+		return fmt.Errorf("download failed with status code: %d", resp.StatusCode)
+	}
+
+	r.body = resp.Body
+	return nil
+}
+
+func (r *Reader) wait() error {
+	timer := time.NewTimer(ResumeBackoff * time.Duration(r.resumes))
+	defer timer.Stop()
+
+	select {
+	case <-r.ctx.Done():
+		return r.ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,147 @@
+package rangereader_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/aferryc/yars/repository/rangereader"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const content = "id,amount,date\n101,500.25,2023-01-15\n102,750.50,2023-01-16\n"
+
+// flakyServer serves content, dropping the connection after dropAfter bytes
+// for the first drops requests
+type flakyServer struct {
+	mu        sync.Mutex
+	drops     int
+	dropAfter int
+	etag      string
+	ranges    []string
+	ifMatches []string
+}
+
+func (s *flakyServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	s.ranges = append(s.ranges, r.Header.Get("Range"))
+	s.ifMatches = append(s.ifMatches, r.Header.Get("If-Match"))
+	drop := s.drops > 0
+	s.drops--
+	etag := s.etag
+	s.mu.Unlock()
+
+	if match := r.Header.Get("If-Match"); match != "" && match != etag {
+		w.WriteHeader(http.StatusPreconditionFailed)
+		return
+	}
+
+	body := content
+	status := http.StatusOK
+	if rangeHeader := r.Header.Get("Range"); rangeHeader != "" {
+		offset, _ := strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(rangeHeader, "bytes="), "-"))
+		body = content[offset:]
+		status = http.StatusPartialContent
+	}
+
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+	w.WriteHeader(status)
+	if !drop {
+		_, _ = io.WriteString(w, body)
+		return
+	}
+
+	// Send part of the body, then close the connection mid-response
+	_, _ = io.WriteString(w, body[:s.dropAfter])
+	w.(http.Flusher).Flush()
+	conn, _, err := w.(http.Hijacker).Hijack()
+	if err == nil {
+		conn.Close()
+	}
+}
+
+func newRequest(url string) rangereader.NewRequestFunc {
+	return func(ctx context.Context) (*http.Request, error) {
+		return http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	}
+}
+
+func TestReader(t *testing.T) {
+	t.Run("Resumes from the last byte read", func(t *testing.T) {
+		// Setup
+		handler := &flakyServer{drops: 2, dropAfter: 10, etag: `"v1"`}
+		server := httptest.NewServer(handler)
+		defer server.Close()
+
+		// Execute
+		reader, err := rangereader.New(context.Background(), server.Client(), newRequest(server.URL), 3)
+		require.NoError(t, err)
+		defer reader.Close()
+		read, err := io.ReadAll(reader)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, content, string(read))
+		assert.Equal(t, []string{"", "bytes=10-", "bytes=20-"}, handler.ranges)
+		assert.Equal(t, []string{"", `"v1"`, `"v1"`}, handler.ifMatches)
+	})
+
+	t.Run("Gives up after the maximum number of resumes", func(t *testing.T) {
+		// Setup
+		handler := &flakyServer{drops: 3, dropAfter: 5, etag: `"v1"`}
+		server := httptest.NewServer(handler)
+		defer server.Close()
+
+		// Execute
+		reader, err := rangereader.New(context.Background(), server.Client(), newRequest(server.URL), 1)
+		require.NoError(t, err)
+		defer reader.Close()
+		_, err = io.ReadAll(reader)
+
+		// Assert
+		assert.ErrorContains(t, err, "after 1 resumes")
+		assert.Len(t, handler.ranges, 2)
+	})
+
+	t.Run("Fails when the object changed", func(t *testing.T) {
+		// Setup
+		handler := &flakyServer{drops: 1, dropAfter: 10, etag: `"v1"`}
+		server := httptest.NewServer(handler)
+		defer server.Close()
+
+		reader, err := rangereader.New(context.Background(), server.Client(), newRequest(server.URL), 3)
+		require.NoError(t, err)
+		defer reader.Close()
+		handler.mu.Lock()
+		handler.etag = `"v2"`
+		handler.mu.Unlock()
+
+		// Execute
+		_, err = io.ReadAll(reader)
+
+		// Assert
+		assert.ErrorIs(t, err, rangereader.ErrObjectChanged)
+	})
+
+	t.Run("Unexpected status", func(t *testing.T) {
+		// Setup
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusForbidden)
+		}))
+		defer server.Close()
+
+		// Execute
+		reader, err := rangereader.New(context.Background(), server.Client(), newRequest(server.URL), 3)
+
+		// Assert
+		assert.ErrorContains(t, err, "403")
+		assert.Nil(t, reader)
+	})
+}
@@ -2,7 +2,7 @@ package repository
 
 import (
 	"context"
-	"os"
+	"io"
 	"time"
 
 	"github.com/aferryc/yars/model"
@@ -10,26 +10,50 @@ import (
 )
 
 type BankStatementRepository interface {
-	FetchAll(start, end time.Time) (model.BankStatementList, error)
+	// FetchAll returns the statements ingested by the task for the bank
+	// between start and end
+	FetchAll(taskID, bank string, start, end time.Time) (model.BankStatementList, error)
 	Save(statement model.BankStatement) error
-	FindByID(id int) (model.BankStatement, error)
+	// SaveBatch saves the statements in bulk, replacing any already saved
+	// for the same task, bank and ID
+	SaveBatch(ctx context.Context, statements []model.BankStatement) error
+}
+
+// StatementRepository stores the balances of the statements of bank files, to
+// verify the entries ingested from them. The entries themselves are saved with
+// the BankStatementRepository.
+type StatementRepository interface {
+	SaveBalances(ctx context.Context, statements []model.Statement) error
 }
 
 // InternalTransactionRepository defines the interface for internal transaction data access.
 type InternalTransactionRepository interface {
-	FetchAll(start, end time.Time) (model.TransactionList, error)
+	// FetchAll returns the transactions ingested by the task for the bank
+	// between start and end
+	FetchAll(taskID, bank string, start, end time.Time) (model.TransactionList, error)
 	Save(transaction model.Transaction) error
+	// SaveBatch saves the transactions in bulk, replacing any already saved
+	// for the same task, bank and ID
+	SaveBatch(ctx context.Context, transactions []model.Transaction) error
 	FindByID(id string) (model.Transaction, error)
 }
 
-type GCSRepository interface {
+// StorageRepository stores the uploaded files, in GCS, S3 or a local directory
+type StorageRepository interface {
 	GenerateUploadURL(objectName string, contentType string, expires time.Time) (string, error)
 	GenerateDownloadURL(objectName string) (string, error)
-	DownloadFromBucket(ctx context.Context, objectName string) (*os.File, error)
+	// UploadMethod is the HTTP method clients upload with to the URLs of
+	// GenerateUploadURL
+	UploadMethod() string
+	// NewReader streams the object, the caller closes it
+	NewReader(ctx context.Context, objectName string) (io.ReadCloser, error)
 }
 
 type KafkaRepository interface {
 	Publish(ctx context.Context, topic string, key string, message any) error
+	// PublishEvent publishes event as JSON keyed by its task, with the
+	// envelope in the message headers
+	PublishEvent(ctx context.Context, topic string, envelope model.EventEnvelope, event any) error
 	Close() error
 }
 
@@ -37,5 +61,49 @@ type ReconResultRepository interface {
 	StoreSummary(ctx context.Context, summary model.ReconciliationSummary, startDate, endDate time.Time) error
 	GetUnmatchedTransactions(ctx context.Context, taskID string, limit, offset int) ([]postgres.UnmatchedTransaction, int, error)
 	GetUnmatchedBankStatements(ctx context.Context, taskID string, limit, offset int) ([]postgres.UnmatchedBankStatement, int, error)
+	GetMatchedPairs(ctx context.Context, taskID string, limit, offset int) ([]postgres.MatchedPair, int, error)
+	GetAggregateMatches(ctx context.Context, taskID string, limit, offset int) ([]postgres.AggregateMatch, int, error)
 	ListSummaries(ctx context.Context, limit, offset int) ([]postgres.ReconSummary, int, error)
 }
+
+// FXRateRepository stores exchange rates by currency pair and date.
+type FXRateRepository interface {
+	SaveRates(ctx context.Context, rates []model.FXRate) error
+	FetchRates(ctx context.Context, start, end time.Time) ([]model.FXRate, error)
+	GetRate(ctx context.Context, baseCurrency, quoteCurrency string, date time.Time) (model.FXRate, error)
+}
+
+// IngestionProfileRepository stores the ingestion profiles by bank name. Get
+// returns model.ErrIngestionProfileNotFound for a bank without a profile.
+type IngestionProfileRepository interface {
+	Save(ctx context.Context, profile model.IngestionProfile) error
+	Get(ctx context.Context, bankName string) (model.IngestionProfile, error)
+	List(ctx context.Context) ([]model.IngestionProfile, error)
+	Delete(ctx context.Context, bankName string) error
+}
+
+// ReconTaskRepository tracks the status of reconciliation tasks. UpdateStatus
+// only applies transitions allowed by the task state machine and returns
+// model.ErrInvalidTaskTransition otherwise.
+type ReconTaskRepository interface {
+	Create(ctx context.Context, taskID string) error
+	UpdateStatus(ctx context.Context, taskID string, status model.TaskStatus, message string) error
+	Get(ctx context.Context, taskID string) (model.ReconTask, error)
+}
+
+// Transactor runs fn in a database transaction, committed when fn returns nil
+// and rolled back otherwise. Repositories given the context passed to fn take
+// part in the transaction.
+type Transactor interface {
+	WithinTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// OutboxRepository stores the events to publish in the database, so they are
+// committed together with the data they describe, see Transactor.
+type OutboxRepository interface {
+	Add(ctx context.Context, event model.OutboxEvent) error
+	// FetchUnsent locks and returns up to limit unpublished events, oldest
+	// first. It is meant to run within a transaction, see Transactor.
+	FetchUnsent(ctx context.Context, limit int) ([]model.OutboxEvent, error)
+	MarkSent(ctx context.Context, ids []int64) error
+}
@@ -0,0 +1,233 @@
+package s3
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aferryc/yars/internal/config"
+	"github.com/aferryc/yars/repository/rangereader"
+)
+
+const (
+	signingAlgorithm  = "AWS4-HMAC-SHA256"
+	service           = "s3"
+	unsignedPayload   = "UNSIGNED-PAYLOAD"
+	amzDateFormat     = "20060102T150405Z"
+	scopeDateFormat   = "20060102"
+	maxPresignExpiry  = 7 * 24 * time.Hour
+	downloadURLExpiry = 15 * time.Minute
+)
+
+// S3Repo stores objects in an S3-compatible bucket, such as AWS S3 or MinIO.
+// Requests are signed with AWS Signature Version 4.
+type S3Repo struct {
+	bucketName string
+	cfg        config.S3Config
+	// endpoint is used by the services, publicEndpoint by the clients of the
+	// presigned URLs
+	endpoint       *url.URL
+	publicEndpoint *url.URL
+	client         *http.Client
+}
+
+func NewS3Repository(bucketName string, cfg config.S3Config) (*S3Repo, error) {
+	if bucketName == "" {
+		return nil, errors.New("bucket name is required")
+	}
+	if cfg.Region == "" {
+		return nil, errors.New("region is required")
+	}
+	if cfg.AccessKeyID == "" || cfg.SecretAccessKey == "" {
+		return nil, errors.New("access key ID and secret access key are required")
+	}
+
+	endpoint := cfg.Endpoint
+	if endpoint == "" {
+		endpoint = fmt.Sprintf("https://s3.%s.amazonaws.com", cfg.Region)
+	}
+	publicEndpoint := cfg.PublicEndpoint
+	if publicEndpoint == "" {
+		publicEndpoint = endpoint
+	}
+
+	endpointURL, err := parseEndpoint(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	publicEndpointURL, err := parseEndpoint(publicEndpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	return &S3Repo{
+		bucketName:     bucketName,
+		cfg:            cfg,
+		endpoint:       endpointURL,
+		publicEndpoint: publicEndpointURL,
+		client:         http.DefaultClient,
+	}, nil
+}
+
+func (s *S3Repo) GenerateUploadURL(objectName string, contentType string, expires time.Time) (string, error) {
+	now := time.Now()
+	return s.presign(s.publicEndpoint, http.MethodPut, objectName, now, expires.Sub(now))
+}
+
+func (s *S3Repo) GenerateDownloadURL(objectName string) (string, error) {
+	return s.presign(s.publicEndpoint, http.MethodGet, objectName, time.Now(), downloadURLExpiry)
+}
+
+// UploadMethod is PUT, the method upload URLs are presigned for
+func (s *S3Repo) UploadMethod() string {
+	return http.MethodPut
+}
+
+// PresignURL returns a URL allowing method on the object for expiry from
+// signedAt, without credentials
+func (s *S3Repo) PresignURL(method, objectName string, signedAt time.Time, expiry time.Duration) (string, error) {
+	return s.presign(s.publicEndpoint, method, objectName, signedAt, expiry)
+}
+
+// NewReader streams the object, resuming with range requests when the
+// connection drops. Every request is presigned again, so a long read outlives
+// the expiry of the URLs.
+func (s *S3Repo) NewReader(ctx context.Context, objectName string) (io.ReadCloser, error) {
+	reader, err := rangereader.New(ctx, s.client, func(ctx context.Context) (*http.Request, error) {
+		downloadURL, err := s.presign(s.endpoint, http.MethodGet, objectName, time.Now(), downloadURLExpiry)
+		if err != nil {
+			return nil, err
+		}
+		return http.NewRequestWithContext(ctx, http.MethodGet, downloadURL, nil)
+	}, rangereader.DefaultMaxResumes)
+	if err != nil {
+		return nil, err
+	}
+	return reader, nil
+}
+
+// presign builds a query-string authenticated URL, signing only the host
+// header so clients may send any other header, such as Content-Type
+func (s *S3Repo) presign(endpoint *url.URL, method, objectName string, signedAt time.Time, expiry time.Duration) (string, error) {
+	if objectName == "" {
+		return "", errors.New("object name is required")
+	}
+	if expiry < time.Second || expiry > maxPresignExpiry {
+		return "", fmt.Errorf("presigned URL expiry must be between 1s and %s, got %s", maxPresignExpiry, expiry)
+	}
+
+	u := *endpoint
+	if s.cfg.UsePathStyle {
+		u.Path = "/" + s.bucketName + "/" + objectName
+	} else {
+		u.Host = s.bucketName + "." + u.Host
+		u.Path = "/" + objectName
+	}
+
+	signedAt = signedAt.UTC()
+	scope := strings.Join([]string{signedAt.Format(scopeDateFormat), s.cfg.Region, service, "aws4_request"}, "/")
+
+	query := map[string]string{
+		"X-Amz-Algorithm":     signingAlgorithm,
+		"X-Amz-Credential":    s.cfg.AccessKeyID + "/" + scope,
+		"X-Amz-Date":          signedAt.Format(amzDateFormat),
+		"X-Amz-Expires":       strconv.FormatInt(int64(expiry/time.Second), 10),
+		"X-Amz-SignedHeaders": "host",
+	}
+	if s.cfg.SessionToken != "" {
+		query["X-Amz-Security-Token"] = s.cfg.SessionToken
+	}
+	canonicalQuery := canonicalQueryString(query)
+	canonicalURI := uriEncode(u.Path, false)
+
+	canonicalRequest := strings.Join([]string{
+		method,
+		canonicalURI,
+		canonicalQuery,
+		"host:" + u.Host + "\n",
+		"host",
+		unsignedPayload,
+	}, "\n")
+	stringToSign := strings.Join([]string{
+		signingAlgorithm,
+		signedAt.Format(amzDateFormat),
+		scope,
+		hashHex(canonicalRequest),
+	}, "\n")
+	signature := hex.EncodeToString(hmacSHA256(s.signingKey(signedAt), stringToSign))
+
+	return u.Scheme + "://" + u.Host + canonicalURI + "?" + canonicalQuery + "&X-Amz-Signature=" + signature, nil
+}
+
+func (s *S3Repo) signingKey(signedAt time.Time) []byte {
+	key := hmacSHA256([]byte("AWS4"+s.cfg.SecretAccessKey), signedAt.Format(scopeDateFormat))
+	key = hmacSHA256(key, s.cfg.Region)
+	key = hmacSHA256(key, service)
+	return hmacSHA256(key, "aws4_request")
+}
+
+func parseEndpoint(endpoint string) (*url.URL, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("invalid S3 endpoint %q: %w", endpoint, err)
+	}
+	if u.Scheme == "" || u.Host == "" {
+		return nil, fmt.Errorf("invalid S3 endpoint %q: scheme and host are required", endpoint)
+	}
+	u.Path = ""
+	return u, nil
+}
+
+func canonicalQueryString(query map[string]string) string {
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, 0, len(keys))
+	for _, key := range keys {
+		pairs = append(pairs, uriEncode(key, true)+"="+uriEncode(query[key], true))
+	}
+	return strings.Join(pairs, "&")
+}
+
+// uriEncode percent-encodes every byte except the unreserved characters, and
+// the slashes unless encodeSlash is set, as SigV4 requires
+func uriEncode(value string, encodeSlash bool) string {
+	var b strings.Builder
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		switch {
+		case c >= 'A' && c <= 'Z', c >= 'a' && c <= 'z', c >= '0' && c <= '9',
+			c == '-', c == '_', c == '.', c == '~':
+			b.WriteByte(c)
+		case c == '/' && !encodeSlash:
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+func hashHex(data string) string {
+	sum := sha256.Sum256([]byte(data))
+	return hex.EncodeToString(sum[:])
+}
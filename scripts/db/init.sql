@@ -1,27 +1,63 @@
 GRANT ALL PRIVILEGES ON DATABASE yars TO postgres;
 
-CREATE TABLE IF NOT EXISTS transactions (
+CREATE TABLE IF NOT EXISTS recon_task (
     id VARCHAR(255) PRIMARY KEY,
-    amount DECIMAL(15, 2) NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    error_message TEXT,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    finished_at TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS transactions (
+    id VARCHAR(255) NOT NULL,
+    task_id VARCHAR(255) NOT NULL,
+    bank VARCHAR(100) NOT NULL,
+    amount DECIMAL(18, 3) NOT NULL,
+    currency VARCHAR(3) NOT NULL DEFAULT '',
     type VARCHAR(50) NOT NULL,
     transaction_time TIMESTAMP NOT NULL,
     description TEXT,
-    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (task_id, bank, id)
 );
 
 CREATE TABLE IF NOT EXISTS bank_statements (
-    id SERIAL PRIMARY KEY,
-    amount DECIMAL(15, 2) NOT NULL,
+    id VARCHAR(255) NOT NULL,
+    task_id VARCHAR(255) NOT NULL,
+    amount DECIMAL(18, 3) NOT NULL,
+    currency VARCHAR(3) NOT NULL DEFAULT '',
     date TIMESTAMP NOT NULL,
     reference VARCHAR(255),
+    narrative TEXT,
+    end_to_end_id VARCHAR(255),
+    counterparty VARCHAR(255),
     bank VARCHAR(100) NOT NULL,
-    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (task_id, bank, id)
+);
+
+CREATE TABLE IF NOT EXISTS statement_balances (
+    task_id VARCHAR(255) NOT NULL,
+    bank VARCHAR(100) NOT NULL,
+    account VARCHAR(100) NOT NULL,
+    reference VARCHAR(255) NOT NULL,
+    number VARCHAR(50) NOT NULL DEFAULT '',
+    currency VARCHAR(3) NOT NULL DEFAULT '',
+    opening_date TIMESTAMP NOT NULL,
+    opening_balance DECIMAL(18, 3) NOT NULL,
+    closing_date TIMESTAMP NOT NULL,
+    closing_balance DECIMAL(18, 3) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (task_id, bank, account, reference, number)
 );
 
 CREATE TABLE IF NOT EXISTS recon_summary (
     id VARCHAR(255) PRIMARY KEY,
     matched INTEGER NOT NULL,
-    discrepancy DECIMAL(15, 2) NOT NULL,
+    discrepancy DECIMAL(18, 3) NOT NULL,
+    currency VARCHAR(3) NOT NULL DEFAULT '',
+    missing_rates TEXT[],
     total_transaction INTEGER NOT NULL,
     total_unmatched_bank INTEGER NOT NULL,
     total_unmatched_internal INTEGER NOT NULL,
@@ -32,34 +68,111 @@ CREATE TABLE IF NOT EXISTS recon_summary (
 );
 
 CREATE TABLE IF NOT EXISTS unmatched_transactions (
-    id VARCHAR(255) PRIMARY KEY,
-    task_id VARCHAR(255) NOT NULL REFERENCES recon_summary(id),
-    amount DECIMAL(15, 2) NOT NULL,
+    id VARCHAR(255) NOT NULL,
+    task_id VARCHAR(255) NOT NULL REFERENCES recon_summary(id) ON DELETE CASCADE,
+    amount DECIMAL(18, 3) NOT NULL,
+    currency VARCHAR(3) NOT NULL DEFAULT '',
     transaction_time TIMESTAMP NOT NULL,
     type VARCHAR(50) NOT NULL,
     description TEXT,
-    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (task_id, id)
 );
 
 CREATE TABLE IF NOT EXISTS unmatched_bank_statements (
     id SERIAL PRIMARY KEY,
-    task_id VARCHAR(255) NOT NULL REFERENCES recon_summary(id),
-    amount DECIMAL(15, 2) NOT NULL,
+    task_id VARCHAR(255) NOT NULL REFERENCES recon_summary(id) ON DELETE CASCADE,
+    bank_statement_id VARCHAR(255) NOT NULL,
+    amount DECIMAL(18, 3) NOT NULL,
+    currency VARCHAR(3) NOT NULL DEFAULT '',
     date TIMESTAMP NOT NULL,
     reference VARCHAR(255),
     bank_name VARCHAR(100) NOT NULL,
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
 );
 
+CREATE TABLE IF NOT EXISTS aggregate_matches (
+    id VARCHAR(255) PRIMARY KEY,
+    task_id VARCHAR(255) NOT NULL REFERENCES recon_summary(id) ON DELETE CASCADE,
+    match_type VARCHAR(50) NOT NULL,
+    rule VARCHAR(100) NOT NULL,
+    amount DECIMAL(18, 3) NOT NULL,
+    currency VARCHAR(3) NOT NULL DEFAULT '',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS aggregate_match_items (
+    id SERIAL PRIMARY KEY,
+    aggregate_match_id VARCHAR(255) NOT NULL REFERENCES aggregate_matches(id) ON DELETE CASCADE,
+    record_type VARCHAR(50) NOT NULL,
+    record_id VARCHAR(255) NOT NULL,
+    amount DECIMAL(18, 3) NOT NULL,
+    currency VARCHAR(3) NOT NULL DEFAULT '',
+    date TIMESTAMP NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS matched_pairs (
+    id SERIAL PRIMARY KEY,
+    task_id VARCHAR(255) NOT NULL REFERENCES recon_summary(id) ON DELETE CASCADE,
+    rule VARCHAR(100) NOT NULL,
+    match_type VARCHAR(50) NOT NULL,
+    aggregate_match_id VARCHAR(255) REFERENCES aggregate_matches(id) ON DELETE CASCADE,
+    transaction_id VARCHAR(255) NOT NULL,
+    transaction_amount DECIMAL(18, 3) NOT NULL,
+    transaction_currency VARCHAR(3) NOT NULL DEFAULT '',
+    transaction_time TIMESTAMP NOT NULL,
+    bank_statement_id VARCHAR(255) NOT NULL,
+    bank_amount DECIMAL(18, 3) NOT NULL,
+    bank_currency VARCHAR(3) NOT NULL DEFAULT '',
+    bank_date TIMESTAMP NOT NULL,
+    bank_reference VARCHAR(255),
+    amount_difference DECIMAL(18, 3),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS fx_rates (
+    base_currency VARCHAR(3) NOT NULL,
+    quote_currency VARCHAR(3) NOT NULL,
+    rate_date DATE NOT NULL,
+    rate DECIMAL(20, 10) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (base_currency, quote_currency, rate_date)
+);
+
+CREATE TABLE IF NOT EXISTS ingestion_profiles (
+    bank_name VARCHAR(255) PRIMARY KEY,
+    transaction_format JSON,
+    bank_statement_format JSON,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS outbox_events (
+    id BIGSERIAL PRIMARY KEY,
+    topic VARCHAR(255) NOT NULL,
+    event_id VARCHAR(255) NOT NULL UNIQUE,
+    event_type VARCHAR(100) NOT NULL,
+    schema_version INT NOT NULL,
+    task_id VARCHAR(255) NOT NULL,
+    trace_parent VARCHAR(55),
+    payload JSON NOT NULL,
+    occurred_at TIMESTAMP NOT NULL,
+    sent_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_recon_task_status ON recon_task(status);
+
 CREATE INDEX IF NOT EXISTS idx_bank_statements_date ON bank_statements(date);
 CREATE INDEX IF NOT EXISTS idx_bank_statements_amount ON bank_statements(amount);
 CREATE INDEX IF NOT EXISTS idx_bank_statements_bank ON bank_statements(bank);
-CREATE UNIQUE INDEX IF NOT EXISTS idx_bank_statements_id_date_bank ON bank_statements(id, date, bank);
+CREATE INDEX IF NOT EXISTS idx_bank_statements_task_bank_date ON bank_statements(task_id, bank, date);
 
 CREATE INDEX IF NOT EXISTS idx_transactions_transaction_time ON transactions(transaction_time);
 CREATE INDEX IF NOT EXISTS idx_transactions_amount ON transactions(amount);
 CREATE INDEX IF NOT EXISTS idx_transactions_type ON transactions(type);
-CREATE UNIQUE INDEX IF NOT EXISTS idx_transactions_id_transaction_time ON transactions(id, transaction_time);
+CREATE INDEX IF NOT EXISTS idx_transactions_task_bank_time ON transactions(task_id, bank, transaction_time);
 
 CREATE INDEX IF NOT EXISTS idx_recon_summary_date_range ON recon_summary(start_date, end_date);
 CREATE INDEX IF NOT EXISTS idx_recon_summary_created_at ON recon_summary(created_at);
@@ -76,4 +189,16 @@ CREATE INDEX IF NOT EXISTS idx_unmatched_bank_statements_task_date ON unmatched_
 CREATE INDEX IF NOT EXISTS idx_unmatched_bank_statements_bank_name ON unmatched_bank_statements(bank_name);
 
 CREATE INDEX IF NOT EXISTS idx_unmatched_txn_task_time_desc ON unmatched_transactions(task_id, transaction_time DESC);
-CREATE INDEX IF NOT EXISTS idx_unmatched_bank_task_date_desc ON unmatched_bank_statements(task_id, date DESC);
\ No newline at end of file
+CREATE INDEX IF NOT EXISTS idx_unmatched_bank_task_date_desc ON unmatched_bank_statements(task_id, date DESC);
+
+CREATE INDEX IF NOT EXISTS idx_aggregate_matches_task_id ON aggregate_matches(task_id);
+CREATE INDEX IF NOT EXISTS idx_aggregate_match_items_match_id ON aggregate_match_items(aggregate_match_id);
+
+CREATE INDEX IF NOT EXISTS idx_matched_pairs_task_id ON matched_pairs(task_id);
+CREATE INDEX IF NOT EXISTS idx_matched_pairs_task_time_desc ON matched_pairs(task_id, transaction_time DESC);
+CREATE INDEX IF NOT EXISTS idx_matched_pairs_transaction_id ON matched_pairs(transaction_id);
+CREATE INDEX IF NOT EXISTS idx_matched_pairs_bank_statement_id ON matched_pairs(bank_statement_id);
+
+CREATE INDEX IF NOT EXISTS idx_fx_rates_rate_date ON fx_rates(rate_date);
+
+CREATE INDEX IF NOT EXISTS idx_outbox_events_unsent ON outbox_events(id) WHERE sent_at IS NULL;
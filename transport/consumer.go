@@ -8,14 +8,17 @@ import (
 	"time"
 
 	"github.com/aferryc/yars/internal/config"
+	"github.com/aferryc/yars/model"
 	"github.com/twmb/franz-go/pkg/kgo"
 )
 
+const commitTimeout = 10 * time.Second
+
 // Consumer handles the consumption of Kafka messages
 type Consumer struct {
 	client  *kgo.Client
 	topic   string
-	handler EventHandler
+	handler *RetryHandler
 	cfg     *config.KafkaConfig
 }
 
@@ -27,11 +30,16 @@ type ConsumerConfig struct {
 	Handler  EventHandler
 }
 
+// EventHandler processes a message. ctx is cancelled once the consumer gives
+// up waiting for the message during shutdown.
 type EventHandler interface {
-	ProcessEvent([]byte) error
+	ProcessEvent(ctx context.Context, msg model.Message) error
 }
 
-// NewConsumer creates a new Kafka consumer
+// NewConsumer creates a new Kafka consumer. Offsets are committed only once a
+// record is handled, so a record is redelivered if the consumer stops before.
+// Rebalances wait until the records of a poll are handled and committed, so
+// that the partitions committed to are still owned.
 func NewConsumer(cfg *config.KafkaConfig, topic string, handler EventHandler) (*Consumer, error) {
 	opts := []kgo.Opt{
 		kgo.SeedBrokers(cfg.BrokerList...),
@@ -41,7 +49,8 @@ func NewConsumer(cfg *config.KafkaConfig, topic string, handler EventHandler) (*
 		kgo.FetchMinBytes(1e3), // 1KB
 		kgo.FetchMaxBytes(1e6), // 1MB
 		kgo.FetchMaxWait(5 * time.Second),
-		kgo.AutoCommitInterval(5 * time.Second),
+		kgo.DisableAutoCommit(),
+		kgo.BlockRebalanceOnPoll(),
 	}
 
 	client, err := kgo.NewClient(opts...)
@@ -52,17 +61,30 @@ func NewConsumer(cfg *config.KafkaConfig, topic string, handler EventHandler) (*
 	return &Consumer{
 		client:  client,
 		topic:   topic,
-		handler: handler,
+		handler: NewRetryHandler(handler, client, cfg.Retry),
 		cfg:     cfg,
 	}, nil
 }
 
-// Start begins consuming messages
+// Start consumes messages until ctx is cancelled or the client is closed. It
+// then stops polling and lets the record in flight finish for up to the
+// configured drain timeout before cancelling its handler, and returns once it
+// is committed. Records fetched but not started are redelivered.
+//
+// A record that could not be handled, even dead-lettered, is left uncommitted
+// and fetched again after the maximum retry backoff, together with the
+// records after it in its partition.
 func (c *Consumer) Start(ctx context.Context) error {
 	log.Printf("Starting consumer for topic: %s", c.topic)
 
+	handlerCtx, stopDrain := drainContext(ctx, c.cfg.DrainTimeout, c.topic)
+	defer stopDrain()
+
 	for {
 		fetches := c.client.PollFetches(ctx)
+		if ctx.Err() != nil {
+			return nil
+		}
 		if fetches.IsClientClosed() {
 			return errors.New("client closed")
 		}
@@ -75,29 +97,75 @@ func (c *Consumer) Start(ctx context.Context) error {
 		}
 
 		// Process all fetched records
-		fetches.EachPartition(func(p kgo.FetchTopicPartition) {
-			p.EachRecord(func(record *kgo.Record) {
-				// Process each record
-				log.Printf("Received message: partition=%d offset=%d", record.Partition, record.Offset)
-
-				// Process the event
-				if err := c.handler.ProcessEvent(record.Value); err != nil {
-					log.Printf("Error processing event: %v", err)
-					// Depending on your strategy, you might want to:
-					// - Skip this message and continue
-					// - Retry a certain number of times
-					// - Send to a dead-letter queue
-					// - Pause consumption and alert
-				}
-			})
-		})
+		failed := make(map[int32]bool)
+		for iter := fetches.RecordIter(); !iter.Done() && ctx.Err() == nil; {
+			record := iter.Next()
+			// The records after one that failed wait for it to be handled
+			if failed[record.Partition] {
+				continue
+			}
+			log.Printf("Received message: partition=%d offset=%d", record.Partition, record.Offset)
+
+			// Process the event, dead-lettering it once retries run out
+			if err := c.handler.Handle(handlerCtx, record); err != nil {
+				log.Printf("Error handling record at partition=%d offset=%d, fetching it again: %v", record.Partition, record.Offset, err)
+				failed[record.Partition] = true
+				c.rewind(record)
+				continue
+			}
+
+			c.commit(handlerCtx, record)
+		}
+		c.client.AllowRebalance()
+
+		if len(failed) > 0 {
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-time.After(c.cfg.Retry.MaxBackoff):
+			}
+		}
+	}
+}
+
+// rewind fetches the record again on the next poll
+func (c *Consumer) rewind(record *kgo.Record) {
+	c.client.SetOffsets(map[string]map[int32]kgo.EpochOffset{
+		record.Topic: {record.Partition: {Epoch: record.LeaderEpoch, Offset: record.Offset}},
+	})
+}
+
+// drainContext returns the context to handle records with. It is cancelled
+// once timeout passed after ctx is cancelled, or when stop is called.
+func drainContext(ctx context.Context, timeout time.Duration, topic string) (handlerCtx context.Context, stop func()) {
+	handlerCtx, cancelHandler := context.WithCancel(context.WithoutCancel(ctx))
+	stopDrain := context.AfterFunc(ctx, func() {
+		log.Printf("Draining consumer for topic: %s", topic)
+		time.AfterFunc(timeout, cancelHandler)
+	})
+	return handlerCtx, func() {
+		stopDrain()
+		cancelHandler()
+	}
+}
+
+// commit marks the record as handled. The commit still runs when ctx is
+// cancelled, since the record was handled. A failed commit is only logged:
+// the record is then redelivered, which the handlers are idempotent to.
+func (c *Consumer) commit(ctx context.Context, record *kgo.Record) {
+	commitCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), commitTimeout)
+	defer cancel()
+
+	if err := c.client.CommitRecords(commitCtx, record); err != nil {
+		log.Printf("Error committing offset at partition=%d offset=%d, the record will be redelivered: %v", record.Partition, record.Offset, err)
 	}
 }
 
-// Close properly shuts down the consumer
+// Close properly shuts down the consumer, letting the group rebalance the
+// partitions it owned
 func (c *Consumer) Close() {
 	if c.client != nil {
-		c.client.Close()
+		c.client.CloseAllowingRebalance()
 		log.Println("Kafka consumer closed")
 	}
 }
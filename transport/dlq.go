@@ -0,0 +1,126 @@
+package transport
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/twmb/franz-go/pkg/kgo"
+)
+
+const deadLetterSuffix = ".dlq"
+
+// Headers added to a record sent to the dead-letter topic
+const (
+	HeaderDLQError             = "dlq-error"
+	HeaderDLQAttempts          = "dlq-attempts"
+	HeaderDLQOriginalTopic     = "dlq-original-topic"
+	HeaderDLQOriginalPartition = "dlq-original-partition"
+	HeaderDLQOriginalOffset    = "dlq-original-offset"
+)
+
+const deadLetterHeaderPrefix = "dlq-"
+
+// DeadLetterTopic returns the topic records of topic are sent to once every
+// attempt to process them failed
+func DeadLetterTopic(topic string) string {
+	return topic + deadLetterSuffix
+}
+
+// newDeadLetterRecord copies record for the dead-letter topic, recording why
+// and where it failed in its headers
+func newDeadLetterRecord(record *kgo.Record, cause error, attempts int) *kgo.Record {
+	headers := make([]kgo.RecordHeader, 0, len(record.Headers)+5)
+	headers = append(headers, record.Headers...)
+	headers = append(headers,
+		kgo.RecordHeader{Key: HeaderDLQError, Value: []byte(cause.Error())},
+		kgo.RecordHeader{Key: HeaderDLQAttempts, Value: []byte(strconv.Itoa(attempts))},
+		kgo.RecordHeader{Key: HeaderDLQOriginalTopic, Value: []byte(record.Topic)},
+		kgo.RecordHeader{Key: HeaderDLQOriginalPartition, Value: []byte(strconv.FormatInt(int64(record.Partition), 10))},
+		kgo.RecordHeader{Key: HeaderDLQOriginalOffset, Value: []byte(strconv.FormatInt(record.Offset, 10))},
+	)
+
+	return &kgo.Record{
+		Topic:   DeadLetterTopic(record.Topic),
+		Key:     record.Key,
+		Value:   record.Value,
+		Headers: headers,
+	}
+}
+
+// ReplayRecord turns a dead-letter record back into a record for the topic it
+// originally failed on, without the dead-letter headers
+func ReplayRecord(record *kgo.Record) (*kgo.Record, error) {
+	var topic string
+	headers := make([]kgo.RecordHeader, 0, len(record.Headers))
+	for _, header := range record.Headers {
+		if header.Key == HeaderDLQOriginalTopic {
+			topic = string(header.Value)
+		}
+		if strings.HasPrefix(header.Key, deadLetterHeaderPrefix) {
+			continue
+		}
+		headers = append(headers, header)
+	}
+	if topic == "" {
+		return nil, fmt.Errorf("record at %s/%d/%d has no %s header", record.Topic, record.Partition, record.Offset, HeaderDLQOriginalTopic)
+	}
+
+	return &kgo.Record{
+		Topic:   topic,
+		Key:     record.Key,
+		Value:   record.Value,
+		Headers: headers,
+	}, nil
+}
+
+// ReplayDeadLetters publishes the records of the dead-letter topics the client
+// consumes back onto their source topics, committing them once published. It
+// returns once no record arrived for idleTimeout.
+func ReplayDeadLetters(ctx context.Context, client *kgo.Client, idleTimeout time.Duration) (int, error) {
+	var replayed int
+	for {
+		pollCtx, cancel := context.WithTimeout(ctx, idleTimeout)
+		fetches := client.PollFetches(pollCtx)
+		cancel()
+
+		if ctx.Err() != nil {
+			return replayed, ctx.Err()
+		}
+		if fetches.IsClientClosed() {
+			return replayed, errors.New("client closed")
+		}
+		for _, fetchErr := range fetches.Errors() {
+			if !errors.Is(fetchErr.Err, context.DeadlineExceeded) {
+				log.Printf("Error polling: %v", fetchErr.Err)
+			}
+		}
+
+		records := fetches.Records()
+		if len(records) == 0 {
+			return replayed, nil
+		}
+
+		replays := make([]*kgo.Record, len(records))
+		for i, record := range records {
+			replay, err := ReplayRecord(record)
+			if err != nil {
+				return replayed, err
+			}
+			replays[i] = replay
+		}
+
+		if err := client.ProduceSync(ctx, replays...).FirstErr(); err != nil {
+			return replayed, fmt.Errorf("failed to replay records: %w", err)
+		}
+		if err := client.CommitRecords(ctx, records...); err != nil {
+			return replayed, fmt.Errorf("failed to commit replayed records: %w", err)
+		}
+		replayed += len(records)
+		log.Printf("Replayed %d records", replayed)
+	}
+}
@@ -0,0 +1,54 @@
+package transport_test
+
+import (
+	"testing"
+
+	"github.com/aferryc/yars/transport"
+	"github.com/stretchr/testify/assert"
+	"github.com/twmb/franz-go/pkg/kgo"
+)
+
+func TestDeadLetterTopic(t *testing.T) {
+	assert.Equal(t, "compiler-events.dlq", transport.DeadLetterTopic("compiler-events"))
+}
+
+func TestReplayRecord(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		// Setup
+		record := &kgo.Record{
+			Topic: "compiler-events.dlq",
+			Key:   []byte("task-1"),
+			Value: []byte(`{"taskID":"task-1"}`),
+			Headers: []kgo.RecordHeader{
+				{Key: "trace-id", Value: []byte("abc")},
+				{Key: transport.HeaderDLQError, Value: []byte("db down")},
+				{Key: transport.HeaderDLQAttempts, Value: []byte("3")},
+				{Key: transport.HeaderDLQOriginalTopic, Value: []byte("compiler-events")},
+				{Key: transport.HeaderDLQOriginalPartition, Value: []byte("2")},
+				{Key: transport.HeaderDLQOriginalOffset, Value: []byte("42")},
+			},
+		}
+
+		// Execute
+		replay, err := transport.ReplayRecord(record)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, "compiler-events", replay.Topic)
+		assert.Equal(t, record.Key, replay.Key)
+		assert.Equal(t, record.Value, replay.Value)
+		assert.Equal(t, []kgo.RecordHeader{{Key: "trace-id", Value: []byte("abc")}}, replay.Headers)
+	})
+
+	t.Run("Missing original topic", func(t *testing.T) {
+		// Setup
+		record := &kgo.Record{Topic: "compiler-events.dlq", Value: []byte("{}")}
+
+		// Execute
+		replay, err := transport.ReplayRecord(record)
+
+		// Assert
+		assert.Error(t, err)
+		assert.Nil(t, replay)
+	})
+}
@@ -1,6 +1,7 @@
 package transport
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 	"time"
@@ -13,12 +14,16 @@ import (
 type Handler struct {
 	reconManagerUC *usecase.ReconManager
 	listUC         *usecase.ListUsecase
+	fxRateUC       *usecase.FXRateUsecase
+	profileUC      *usecase.IngestionProfileUsecase
 }
 
-func NewHandler(reconManagerUC *usecase.ReconManager, listUC *usecase.ListUsecase) *Handler {
+func NewHandler(reconManagerUC *usecase.ReconManager, listUC *usecase.ListUsecase, fxRateUC *usecase.FXRateUsecase, profileUC *usecase.IngestionProfileUsecase) *Handler {
 	return &Handler{
 		reconManagerUC: reconManagerUC,
 		listUC:         listUC,
+		fxRateUC:       fxRateUC,
+		profileUC:      profileUC,
 	}
 }
 
@@ -65,7 +70,7 @@ func (h *Handler) HandleReconManagerInitCompilation(c *gin.Context) {
 
 	err := h.reconManagerUC.InitiateCompilation(c.Request.Context(), req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
+		c.JSON(taskErrorStatus(err), gin.H{
 			"error": err.Error(),
 		})
 		return
@@ -76,6 +81,30 @@ func (h *Handler) HandleReconManagerInitCompilation(c *gin.Context) {
 	})
 }
 
+func (h *Handler) HandleGetTaskStatus(c *gin.Context) {
+	status, err := h.reconManagerUC.GetTaskStatus(c.Request.Context(), c.Param("task_id"))
+	if err != nil {
+		c.JSON(taskErrorStatus(err), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, status)
+}
+
+// taskErrorStatus maps task lifecycle errors to HTTP status codes
+func taskErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, model.ErrTaskNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, model.ErrInvalidTaskTransition):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
 func (h *Handler) HandleListReconSummary(c *gin.Context) {
 	limit, err := strconv.Atoi(c.Query("limit"))
 	if err != nil {
@@ -155,3 +184,185 @@ func (h *Handler) HandleListUnmatchedTransactions(c *gin.Context) {
 
 	c.JSON(http.StatusOK, unmatchedTrx)
 }
+
+func (h *Handler) HandleListAggregateMatches(c *gin.Context) {
+	taskID := c.Param("task_id")
+	limit, err := strconv.Atoi(c.Query("limit"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid limit parameter",
+		})
+		return
+	}
+	offset, err := strconv.Atoi(c.Query("offset"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid offset parameter",
+		})
+		return
+	}
+	aggregateMatches, err := h.listUC.ListAggregateMatches(c.Request.Context(), taskID, limit, offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, aggregateMatches)
+}
+
+func (h *Handler) HandleListMatchedPairs(c *gin.Context) {
+	taskID := c.Param("task_id")
+	limit, err := strconv.Atoi(c.Query("limit"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid limit parameter",
+		})
+		return
+	}
+	offset, err := strconv.Atoi(c.Query("offset"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid offset parameter",
+		})
+		return
+	}
+	matchedPairs, err := h.listUC.ListMatchedPairs(c.Request.Context(), taskID, limit, offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, matchedPairs)
+}
+
+func (h *Handler) HandleImportFXRates(c *gin.Context) {
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Missing file parameter",
+		})
+		return
+	}
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	defer file.Close()
+
+	imported, err := h.fxRateUC.ImportCSV(c.Request.Context(), file)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, model.ErrInvalidFXRate) {
+			status = http.StatusBadRequest
+		}
+		c.JSON(status, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, imported)
+}
+
+func (h *Handler) HandleGetFXRate(c *gin.Context) {
+	date := time.Now().UTC()
+	if dateStr := c.Query("date"); dateStr != "" {
+		parsed, err := time.Parse("2006-01-02", dateStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid date parameter",
+			})
+			return
+		}
+		date = parsed
+	}
+
+	rate, err := h.fxRateUC.GetRate(c.Request.Context(), c.Query("base"), c.Query("quote"), date)
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, model.ErrInvalidFXRate):
+			status = http.StatusBadRequest
+		case errors.Is(err, model.ErrFXRateNotFound):
+			status = http.StatusNotFound
+		}
+		c.JSON(status, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, rate)
+}
+
+func (h *Handler) HandleSaveIngestionProfile(c *gin.Context) {
+	var profile model.IngestionProfile
+	if err := c.ShouldBindJSON(&profile); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid request body",
+		})
+		return
+	}
+
+	saved, err := h.profileUC.Save(c.Request.Context(), c.Param("bank_name"), profile)
+	if err != nil {
+		c.JSON(ingestionProfileErrorStatus(err), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, saved)
+}
+
+func (h *Handler) HandleGetIngestionProfile(c *gin.Context) {
+	profile, err := h.profileUC.Get(c.Request.Context(), c.Param("bank_name"))
+	if err != nil {
+		c.JSON(ingestionProfileErrorStatus(err), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, profile)
+}
+
+func (h *Handler) HandleListIngestionProfiles(c *gin.Context) {
+	profiles, err := h.profileUC.List(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, profiles)
+}
+
+func (h *Handler) HandleDeleteIngestionProfile(c *gin.Context) {
+	if err := h.profileUC.Delete(c.Request.Context(), c.Param("bank_name")); err != nil {
+		c.JSON(ingestionProfileErrorStatus(err), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func ingestionProfileErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, model.ErrInvalidIngestionProfile):
+		return http.StatusBadRequest
+	case errors.Is(err, model.ErrIngestionProfileNotFound):
+		return http.StatusNotFound
+	}
+	return http.StatusInternalServerError
+}
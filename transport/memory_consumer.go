@@ -0,0 +1,67 @@
+package transport
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/aferryc/yars/internal/config"
+	"github.com/twmb/franz-go/pkg/kgo"
+)
+
+// RecordSource hands out the records of a topic one at a time, each record to
+// a single caller. *memory.Broker implements it.
+type RecordSource interface {
+	RecordProducer
+	Next(ctx context.Context, topic string) (*kgo.Record, error)
+}
+
+// MemoryConsumer consumes a topic of an in-process broker, standing in for
+// Consumer when running without Kafka. Records are retried and dead-lettered
+// to the broker the same way.
+type MemoryConsumer struct {
+	source  RecordSource
+	topic   string
+	handler *RetryHandler
+	cfg     *config.KafkaConfig
+}
+
+// NewMemoryConsumer creates a consumer of topic on source
+func NewMemoryConsumer(cfg *config.KafkaConfig, source RecordSource, topic string, handler EventHandler) *MemoryConsumer {
+	return &MemoryConsumer{
+		source:  source,
+		topic:   topic,
+		handler: NewRetryHandler(handler, source, cfg.Retry),
+		cfg:     cfg,
+	}
+}
+
+// Start consumes records until ctx is cancelled, letting the record in flight
+// finish for up to the configured drain timeout like Consumer.Start. Records
+// are removed from the broker as they are read, so a record that could not be
+// handled is lost.
+func (c *MemoryConsumer) Start(ctx context.Context) error {
+	log.Printf("Starting in-memory consumer for topic: %s", c.topic)
+
+	handlerCtx, stopDrain := drainContext(ctx, c.cfg.DrainTimeout, c.topic)
+	defer stopDrain()
+
+	for {
+		record, err := c.source.Next(ctx, c.topic)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return fmt.Errorf("failed to read topic %s: %w", c.topic, err)
+		}
+		log.Printf("Received message: topic=%s offset=%d", record.Topic, record.Offset)
+
+		if err := c.handler.Handle(handlerCtx, record); err != nil {
+			return fmt.Errorf("failed to handle record at topic=%s offset=%d: %w", record.Topic, record.Offset, err)
+		}
+	}
+}
+
+// Close does nothing, the broker is closed by its owner. It is there so
+// MemoryConsumer can be used like Consumer.
+func (c *MemoryConsumer) Close() {}
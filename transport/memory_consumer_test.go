@@ -0,0 +1,98 @@
+package transport_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aferryc/yars/internal/config"
+	"github.com/aferryc/yars/model"
+	"github.com/aferryc/yars/repository/memory"
+	"github.com/aferryc/yars/transport"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/twmb/franz-go/pkg/kgo"
+)
+
+// channelHandler passes the messages it processes to the test goroutine
+type channelHandler struct {
+	err      error
+	messages chan model.Message
+}
+
+func (h *channelHandler) ProcessEvent(_ context.Context, msg model.Message) error {
+	h.messages <- msg
+	return h.err
+}
+
+func TestMemoryConsumer_Start(t *testing.T) {
+	cfg := &config.KafkaConfig{
+		Retry:        config.RetryConfig{MaxAttempts: 2, InitialBackoff: time.Millisecond},
+		DrainTimeout: time.Second,
+	}
+
+	t.Run("Handles records in order", func(t *testing.T) {
+		// Setup
+		broker := memory.NewBroker()
+		defer broker.Close()
+		handler := &channelHandler{messages: make(chan model.Message, 2)}
+		consumer := transport.NewMemoryConsumer(cfg, broker, "events", handler)
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan error)
+
+		// Execute
+		go func() { done <- consumer.Start(ctx) }()
+		broker.ProduceSync(ctx,
+			&kgo.Record{Topic: "events", Value: []byte("first"), Headers: []kgo.RecordHeader{{Key: "event_type", Value: []byte("test")}}},
+			&kgo.Record{Topic: "events", Value: []byte("second")})
+		first := <-handler.messages
+		second := <-handler.messages
+		cancel()
+
+		// Assert
+		assert.NoError(t, <-done)
+		assert.Equal(t, "first", string(first.Value))
+		assert.Equal(t, map[string]string{"event_type": "test"}, first.Headers)
+		assert.Equal(t, "second", string(second.Value))
+	})
+
+	t.Run("Dead-letters to the broker", func(t *testing.T) {
+		// Setup
+		broker := memory.NewBroker()
+		defer broker.Close()
+		handler := &channelHandler{err: errors.New("db down"), messages: make(chan model.Message, 2)}
+		consumer := transport.NewMemoryConsumer(cfg, broker, "events", handler)
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		done := make(chan error)
+
+		// Execute
+		go func() { done <- consumer.Start(ctx) }()
+		broker.ProduceSync(ctx, &kgo.Record{Topic: "events", Value: []byte("failing")})
+		dead, err := broker.Next(ctx, "events.dlq")
+		cancel()
+
+		// Assert
+		require.NoError(t, err)
+		assert.NoError(t, <-done)
+		assert.Len(t, handler.messages, 2)
+		assert.Equal(t, "failing", string(dead.Value))
+		assert.Equal(t, "db down", headerValue(dead, transport.HeaderDLQError))
+		assert.Equal(t, "events", headerValue(dead, transport.HeaderDLQOriginalTopic))
+	})
+
+	t.Run("Closed broker stops the consumer", func(t *testing.T) {
+		// Setup
+		broker := memory.NewBroker()
+		consumer := transport.NewMemoryConsumer(cfg, broker, "events", &channelHandler{})
+		done := make(chan error)
+
+		// Execute
+		go func() { done <- consumer.Start(context.Background()) }()
+		broker.Close()
+
+		// Assert
+		assert.ErrorIs(t, <-done, memory.ErrBrokerClosed)
+	})
+}
@@ -0,0 +1,113 @@
+package transport
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/aferryc/yars/internal/config"
+	"github.com/aferryc/yars/model"
+	"github.com/twmb/franz-go/pkg/kgo"
+)
+
+// RecordProducer publishes records synchronously, *kgo.Client implements it
+type RecordProducer interface {
+	ProduceSync(ctx context.Context, rs ...*kgo.Record) kgo.ProduceResults
+}
+
+// RetryHandler processes a record with an EventHandler, retrying with backoff
+// and sending the record to its dead-letter topic once every attempt failed
+type RetryHandler struct {
+	handler  EventHandler
+	producer RecordProducer
+	cfg      config.RetryConfig
+}
+
+// NewRetryHandler creates a new RetryHandler
+func NewRetryHandler(handler EventHandler, producer RecordProducer, cfg config.RetryConfig) *RetryHandler {
+	return &RetryHandler{
+		handler:  handler,
+		producer: producer,
+		cfg:      cfg,
+	}
+}
+
+// Handle processes the record. It returns nil once the record is processed or
+// dead-lettered, and an error when the record should not be considered
+// handled, e.g. because ctx was cancelled during a backoff.
+func (h *RetryHandler) Handle(ctx context.Context, record *kgo.Record) error {
+	maxAttempts := max(h.cfg.MaxAttempts, 1)
+
+	var err error
+	for attempt := 1; attempt <= maxAttempts; attempt++ {
+		if err = h.handler.ProcessEvent(ctx, newMessage(record)); err == nil {
+			return nil
+		}
+		log.Printf("Error processing event: partition=%d offset=%d attempt=%d/%d: %v",
+			record.Partition, record.Offset, attempt, maxAttempts, err)
+
+		// A record interrupted by shutdown is left to be redelivered
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		if attempt == maxAttempts {
+			break
+		}
+		if err := sleep(ctx, h.backoff(attempt)); err != nil {
+			return err
+		}
+	}
+
+	dead := newDeadLetterRecord(record, err, maxAttempts)
+	if err := h.producer.ProduceSync(ctx, dead).FirstErr(); err != nil {
+		return fmt.Errorf("failed to publish to %s: %w", dead.Topic, err)
+	}
+	log.Printf("Sent event to %s: partition=%d offset=%d", dead.Topic, record.Partition, record.Offset)
+
+	return nil
+}
+
+// newMessage converts the record for the handler
+func newMessage(record *kgo.Record) model.Message {
+	headers := make(map[string]string, len(record.Headers))
+	for _, header := range record.Headers {
+		headers[header.Key] = string(header.Value)
+	}
+
+	return model.Message{
+		Topic:   record.Topic,
+		Key:     record.Key,
+		Value:   record.Value,
+		Headers: headers,
+	}
+}
+
+// backoff returns the wait after the given failed attempt
+func (h *RetryHandler) backoff(attempt int) time.Duration {
+	wait := h.cfg.InitialBackoff
+	for i := 1; i < attempt; i++ {
+		wait *= 2
+		if h.cfg.MaxBackoff > 0 && wait >= h.cfg.MaxBackoff {
+			return h.cfg.MaxBackoff
+		}
+	}
+	return wait
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
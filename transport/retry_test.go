@@ -0,0 +1,183 @@
+package transport_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aferryc/yars/internal/config"
+	"github.com/aferryc/yars/model"
+	"github.com/aferryc/yars/transport"
+	"github.com/stretchr/testify/assert"
+	"github.com/twmb/franz-go/pkg/kgo"
+)
+
+type stubHandler struct {
+	errs     []error
+	calls    int
+	messages []model.Message
+}
+
+func (h *stubHandler) ProcessEvent(_ context.Context, msg model.Message) error {
+	h.calls++
+	h.messages = append(h.messages, msg)
+	if h.calls <= len(h.errs) {
+		return h.errs[h.calls-1]
+	}
+	return nil
+}
+
+type stubProducer struct {
+	err     error
+	records []*kgo.Record
+}
+
+func (p *stubProducer) ProduceSync(_ context.Context, rs ...*kgo.Record) kgo.ProduceResults {
+	p.records = append(p.records, rs...)
+	results := make(kgo.ProduceResults, 0, len(rs))
+	for _, r := range rs {
+		results = append(results, kgo.ProduceResult{Record: r, Err: p.err})
+	}
+	return results
+}
+
+func headerValue(record *kgo.Record, key string) string {
+	for _, header := range record.Headers {
+		if header.Key == key {
+			return string(header.Value)
+		}
+	}
+	return ""
+}
+
+func TestRetryHandler_Handle(t *testing.T) {
+	retryCfg := config.RetryConfig{
+		MaxAttempts:    3,
+		InitialBackoff: time.Millisecond,
+		MaxBackoff:     2 * time.Millisecond,
+	}
+	newRecord := func() *kgo.Record {
+		return &kgo.Record{
+			Topic:     "compiler-events",
+			Partition: 2,
+			Offset:    42,
+			Key:       []byte("task-1"),
+			Value:     []byte(`{"taskID":"task-1"}`),
+			Headers:   []kgo.RecordHeader{{Key: "trace-id", Value: []byte("abc")}},
+		}
+	}
+
+	t.Run("Processed on first attempt", func(t *testing.T) {
+		// Setup
+		handler := &stubHandler{}
+		producer := &stubProducer{}
+		h := transport.NewRetryHandler(handler, producer, retryCfg)
+
+		// Execute
+		err := h.Handle(context.Background(), newRecord())
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, 1, handler.calls)
+		assert.Empty(t, producer.records)
+		assert.Equal(t, model.Message{
+			Topic:   "compiler-events",
+			Key:     []byte("task-1"),
+			Value:   []byte(`{"taskID":"task-1"}`),
+			Headers: map[string]string{"trace-id": "abc"},
+		}, handler.messages[0])
+	})
+
+	t.Run("Processed after retry", func(t *testing.T) {
+		// Setup
+		handler := &stubHandler{errs: []error{errors.New("db down")}}
+		producer := &stubProducer{}
+		h := transport.NewRetryHandler(handler, producer, retryCfg)
+
+		// Execute
+		err := h.Handle(context.Background(), newRecord())
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, 2, handler.calls)
+		assert.Empty(t, producer.records)
+	})
+
+	t.Run("Dead-lettered after last attempt", func(t *testing.T) {
+		// Setup
+		failure := errors.New("db down")
+		handler := &stubHandler{errs: []error{failure, failure, failure}}
+		producer := &stubProducer{}
+		h := transport.NewRetryHandler(handler, producer, retryCfg)
+
+		// Execute
+		err := h.Handle(context.Background(), newRecord())
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, 3, handler.calls)
+		if assert.Len(t, producer.records, 1) {
+			dead := producer.records[0]
+			assert.Equal(t, "compiler-events.dlq", dead.Topic)
+			assert.Equal(t, []byte("task-1"), dead.Key)
+			assert.Equal(t, []byte(`{"taskID":"task-1"}`), dead.Value)
+			assert.Equal(t, "abc", headerValue(dead, "trace-id"))
+			assert.Equal(t, "db down", headerValue(dead, transport.HeaderDLQError))
+			assert.Equal(t, "3", headerValue(dead, transport.HeaderDLQAttempts))
+			assert.Equal(t, "compiler-events", headerValue(dead, transport.HeaderDLQOriginalTopic))
+			assert.Equal(t, "2", headerValue(dead, transport.HeaderDLQOriginalPartition))
+			assert.Equal(t, "42", headerValue(dead, transport.HeaderDLQOriginalOffset))
+		}
+	})
+
+	t.Run("Single attempt when retries are disabled", func(t *testing.T) {
+		// Setup
+		handler := &stubHandler{errs: []error{errors.New("invalid event")}}
+		producer := &stubProducer{}
+		h := transport.NewRetryHandler(handler, producer, config.RetryConfig{})
+
+		// Execute
+		err := h.Handle(context.Background(), newRecord())
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, 1, handler.calls)
+		assert.Len(t, producer.records, 1)
+	})
+
+	t.Run("Error publishing to dead-letter topic", func(t *testing.T) {
+		// Setup
+		failure := errors.New("db down")
+		handler := &stubHandler{errs: []error{failure, failure, failure}}
+		producer := &stubProducer{err: errors.New("broker unavailable")}
+		h := transport.NewRetryHandler(handler, producer, retryCfg)
+
+		// Execute
+		err := h.Handle(context.Background(), newRecord())
+
+		// Assert
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "compiler-events.dlq")
+	})
+
+	t.Run("Context cancelled during backoff", func(t *testing.T) {
+		// Setup
+		handler := &stubHandler{errs: []error{errors.New("db down")}}
+		producer := &stubProducer{}
+		h := transport.NewRetryHandler(handler, producer, config.RetryConfig{
+			MaxAttempts:    3,
+			InitialBackoff: time.Hour,
+		})
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		// Execute
+		err := h.Handle(ctx, newRecord())
+
+		// Assert
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Equal(t, 1, handler.calls)
+		assert.Empty(t, producer.records)
+	})
+}
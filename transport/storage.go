@@ -0,0 +1,107 @@
+package transport
+
+import (
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/aferryc/yars/model"
+	"github.com/aferryc/yars/repository/local"
+	"github.com/gin-gonic/gin"
+)
+
+// ObjectStore stores the objects behind signed URLs, *local.LocalStorage
+// implements it
+type ObjectStore interface {
+	VerifyURL(operation, objectName, expires, signature string) error
+	Write(objectName string, r io.Reader) error
+	Open(objectName string) (*os.File, error)
+}
+
+// StorageHandler serves the signed upload and download URLs of the local
+// storage backend
+type StorageHandler struct {
+	store ObjectStore
+}
+
+func NewStorageHandler(store ObjectStore) *StorageHandler {
+	return &StorageHandler{
+		store: store,
+	}
+}
+
+// HandleUpload stores the request body as the object. Both PUT and POST are
+// accepted, like signed GCS URLs and the GCS emulator.
+func (h *StorageHandler) HandleUpload(c *gin.Context) {
+	objectName, ok := h.verify(c, local.OperationUpload)
+	if !ok {
+		return
+	}
+	clearDeadlines(c)
+
+	if err := h.store.Write(objectName, c.Request.Body); err != nil {
+		log.Printf("Error storing object %s: %v", objectName, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to store object"})
+		return
+	}
+	c.Status(http.StatusOK)
+}
+
+// HandleDownload serves the object
+func (h *StorageHandler) HandleDownload(c *gin.Context) {
+	objectName, ok := h.verify(c, local.OperationDownload)
+	if !ok {
+		return
+	}
+	clearDeadlines(c)
+
+	file, err := h.store.Open(objectName)
+	if errors.Is(err, model.ErrObjectNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "object not found"})
+		return
+	}
+	if err != nil {
+		log.Printf("Error opening object %s: %v", objectName, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to open object"})
+		return
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to open object"})
+		return
+	}
+	http.ServeContent(c.Writer, c.Request, objectName, info.ModTime(), file)
+}
+
+// verify checks the signed URL of the request, responding with an error when
+// it cannot be used for operation
+func (h *StorageHandler) verify(c *gin.Context, operation string) (string, bool) {
+	objectName := strings.TrimPrefix(c.Param("object"), "/")
+	err := h.store.VerifyURL(operation, objectName, c.Query("expires"), c.Query("signature"))
+	switch {
+	case err == nil:
+		return objectName, true
+	case errors.Is(err, model.ErrSignedURLExpired):
+		c.JSON(http.StatusForbidden, gin.H{"error": "signed url expired"})
+	default:
+		c.JSON(http.StatusForbidden, gin.H{"error": "invalid signed url"})
+	}
+	return "", false
+}
+
+// clearDeadlines lifts the read and write timeouts of the server for the
+// request, as transferring a large file may take longer than any other request
+func clearDeadlines(c *gin.Context) {
+	controller := http.NewResponseController(c.Writer)
+	for _, set := range []func(time.Time) error{controller.SetReadDeadline, controller.SetWriteDeadline} {
+		if err := set(time.Time{}); err != nil && !errors.Is(err, http.ErrNotSupported) {
+			log.Printf("Error clearing deadline of %s: %v", c.Request.URL.Path, err)
+		}
+	}
+}
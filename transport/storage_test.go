@@ -0,0 +1,152 @@
+package transport_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aferryc/yars/repository/local"
+	"github.com/aferryc/yars/transport"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newStorageRouter(t *testing.T) (*gin.Engine, *local.LocalStorage) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	storage, err := local.NewLocalStorage(t.TempDir(), "http://localhost:8080", []byte("secret"))
+	require.NoError(t, err)
+
+	handler := transport.NewStorageHandler(storage)
+	router := gin.New()
+	router.PUT("/storage/*object", handler.HandleUpload)
+	router.POST("/storage/*object", handler.HandleUpload)
+	router.GET("/storage/*object", handler.HandleDownload)
+	return router, storage
+}
+
+func serve(router *gin.Engine, method, url, body string) *httptest.ResponseRecorder {
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(method, url, strings.NewReader(body)))
+	return recorder
+}
+
+func TestStorageHandler(t *testing.T) {
+	t.Run("Uploads and downloads through signed URLs", func(t *testing.T) {
+		// Setup
+		router, storage := newStorageRouter(t)
+		uploadURL, err := storage.GenerateUploadURL("uploads/bank.csv", "text/csv", time.Now().Add(time.Minute))
+		require.NoError(t, err)
+		downloadURL, err := storage.GenerateDownloadURL("uploads/bank.csv")
+		require.NoError(t, err)
+
+		// Execute
+		upload := serve(router, http.MethodPost, uploadURL, "id,amount")
+		download := serve(router, http.MethodGet, downloadURL, "")
+
+		// Assert
+		assert.Equal(t, http.StatusOK, upload.Code)
+		assert.Equal(t, http.StatusOK, download.Code)
+		assert.Equal(t, "id,amount", download.Body.String())
+	})
+
+	t.Run("Transfers files slower than the server timeouts", func(t *testing.T) {
+		// Setup
+		router, storage := newStorageRouter(t)
+		server := httptest.NewUnstartedServer(router)
+		server.Config.ReadTimeout = 100 * time.Millisecond
+		server.Config.WriteTimeout = 100 * time.Millisecond
+		server.Start()
+		defer server.Close()
+
+		uploadURL, err := storage.GenerateUploadURL("uploads/bank.csv", "text/csv", time.Now().Add(time.Minute))
+		require.NoError(t, err)
+		target, err := url.Parse(uploadURL)
+		require.NoError(t, err)
+		target.Scheme, target.Host = "http", strings.TrimPrefix(server.URL, "http://")
+
+		body, writer := io.Pipe()
+		go func() {
+			for _, chunk := range []string{"id,", "amount\n", "tx1,100.00\n"} {
+				time.Sleep(100 * time.Millisecond)
+				_, _ = writer.Write([]byte(chunk))
+			}
+			writer.Close()
+		}()
+		request, err := http.NewRequest(http.MethodPut, target.String(), body)
+		require.NoError(t, err)
+
+		// Execute
+		response, err := http.DefaultClient.Do(request)
+
+		// Assert
+		require.NoError(t, err)
+		defer response.Body.Close()
+		assert.Equal(t, http.StatusOK, response.StatusCode)
+
+		file, err := storage.Open("uploads/bank.csv")
+		require.NoError(t, err)
+		defer file.Close()
+		content, err := io.ReadAll(file)
+		require.NoError(t, err)
+		assert.Equal(t, "id,amount\ntx1,100.00\n", string(content))
+	})
+
+	t.Run("Rejects an upload URL used for a download", func(t *testing.T) {
+		// Setup
+		router, storage := newStorageRouter(t)
+		uploadURL, err := storage.GenerateUploadURL("uploads/bank.csv", "text/csv", time.Now().Add(time.Minute))
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, serve(router, http.MethodPut, uploadURL, "id,amount").Code)
+
+		// Execute
+		recorder := serve(router, http.MethodGet, uploadURL, "")
+
+		// Assert
+		assert.Equal(t, http.StatusForbidden, recorder.Code)
+	})
+
+	t.Run("Rejects an upload to another object", func(t *testing.T) {
+		// Setup
+		router, storage := newStorageRouter(t)
+		uploadURL, err := storage.GenerateUploadURL("uploads/bank.csv", "text/csv", time.Now().Add(time.Minute))
+		require.NoError(t, err)
+
+		// Execute
+		recorder := serve(router, http.MethodPut, strings.Replace(uploadURL, "bank.csv", "other.csv", 1), "id,amount")
+
+		// Assert
+		assert.Equal(t, http.StatusForbidden, recorder.Code)
+	})
+
+	t.Run("Rejects an expired URL", func(t *testing.T) {
+		// Setup
+		router, storage := newStorageRouter(t)
+		uploadURL, err := storage.GenerateUploadURL("uploads/bank.csv", "text/csv", time.Now().Add(-time.Minute))
+		require.NoError(t, err)
+
+		// Execute
+		recorder := serve(router, http.MethodPut, uploadURL, "id,amount")
+
+		// Assert
+		assert.Equal(t, http.StatusForbidden, recorder.Code)
+	})
+
+	t.Run("Missing object", func(t *testing.T) {
+		// Setup
+		router, storage := newStorageRouter(t)
+		downloadURL, err := storage.GenerateDownloadURL("uploads/missing.csv")
+		require.NoError(t, err)
+
+		// Execute
+		recorder := serve(router, http.MethodGet, downloadURL, "")
+
+		// Assert
+		assert.Equal(t, http.StatusNotFound, recorder.Code)
+	})
+}
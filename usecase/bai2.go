@@ -0,0 +1,568 @@
+package usecase
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aferryc/yars/model"
+	"github.com/pkg/errors"
+)
+
+// BAI2Reader streams the detail records of a BAI2 cash management file. The
+// control totals and record counts of every trailer are checked, and the rows
+// of an account are only returned once its trailer checks out.
+type BAI2Reader struct {
+	scanner *bufio.Scanner
+	line    int
+	// peeked is a line read ahead to find the 88 continuations of a record
+	peeked    string
+	hasPeeked bool
+
+	started bool
+	ended   bool
+	fileID  string
+	// records counts the physical records of the file, total sums the control
+	// totals of its groups
+	records int
+	total   int64
+	groups  int
+	group   *bai2Group
+	account *bai2Account
+
+	statements []model.Statement
+	// queue holds the rows of the last account not returned yet
+	queue []model.BankStatement
+	// entries counts the detail records read so far, numbering the row IDs
+	entries int
+}
+
+type bai2Group struct {
+	asOf     string
+	date     time.Time
+	currency string
+	records  int
+	total    int64
+	accounts int
+}
+
+type bai2Account struct {
+	number   string
+	currency string
+	records  int
+	total    int64
+	rows     []model.BankStatement
+	opening  *model.Money
+	closing  *model.Money
+}
+
+// bai2Record is a logical record, its first physical record followed by its
+// 88 continuations
+type bai2Record struct {
+	code     string
+	segments []string
+	line     int
+}
+
+// bai2Fields reads the comma separated fields of a record across its
+// continuations
+type bai2Fields struct {
+	segments []string
+	segment  int
+	pos      int
+}
+
+// NewBAI2Reader creates a new BAI2Reader reading r
+func NewBAI2Reader(r io.Reader) *BAI2Reader {
+	return &BAI2Reader{
+		scanner: bufio.NewScanner(r),
+	}
+}
+
+// Next returns the next row, or io.EOF after the 99 file trailer. Each 16
+// record becomes a row, credited for type codes 100 to 399 and debited for 400
+// to 699, on the as-of date of its group. Details with other type codes are
+// skipped. Rows take the ID <file ID>/<number>, numbered across the file.
+func (r *BAI2Reader) Next() (model.BankStatement, error) {
+	for len(r.queue) == 0 {
+		record, err := r.readRecord()
+		if err == io.EOF {
+			if !r.ended {
+				return model.BankStatement{}, errors.New("[BAI2Reader] file ends before its 99 trailer")
+			}
+			return model.BankStatement{}, io.EOF
+		}
+		if err != nil {
+			return model.BankStatement{}, err
+		}
+		if err := r.handleRecord(record); err != nil {
+			return model.BankStatement{}, errors.Wrapf(err, "[BAI2Reader] line %d", record.line)
+		}
+	}
+
+	row := r.queue[0]
+	r.queue = r.queue[1:]
+	return row, nil
+}
+
+// Statements returns the accounts read so far holding an opening (010) and a
+// closing (015) ledger balance, as statements without entries
+func (r *BAI2Reader) Statements() []model.Statement {
+	return r.statements
+}
+
+// handleRecord checks the record is in its place, counts it in the file, group
+// and account it belongs to and parses it
+func (r *BAI2Reader) handleRecord(record bai2Record) error {
+	if r.ended {
+		return errors.Errorf("record %s after the 99 trailer", record.code)
+	}
+	if !r.started && record.code != "01" {
+		return errors.Errorf("file starts with record %s instead of 01", record.code)
+	}
+
+	fields := &bai2Fields{segments: record.segments}
+	switch record.code {
+	case "01":
+		if r.started {
+			return errors.New("second 01 file header")
+		}
+		r.started = true
+		r.count(record)
+		return r.fileHeader(fields)
+
+	case "02":
+		if r.group != nil {
+			return errors.New("02 group header inside a group")
+		}
+		r.group = &bai2Group{}
+		r.count(record)
+		return r.groupHeader(fields)
+
+	case "03":
+		if r.group == nil || r.account != nil {
+			return errors.New("03 account identifier outside a group")
+		}
+		r.account = &bai2Account{}
+		r.count(record)
+		return r.accountIdentifier(fields)
+
+	case "16":
+		if r.account == nil {
+			return errors.New("16 transaction detail outside an account")
+		}
+		r.count(record)
+		return r.transactionDetail(fields)
+
+	case "49":
+		if r.account == nil {
+			return errors.New("49 account trailer outside an account")
+		}
+		r.count(record)
+		return r.accountTrailer(fields)
+
+	case "98":
+		if r.group == nil || r.account != nil {
+			return errors.New("98 group trailer outside a group")
+		}
+		r.count(record)
+		return r.groupTrailer(fields)
+
+	case "99":
+		if r.group != nil {
+			return errors.New("99 file trailer inside a group")
+		}
+		r.count(record)
+		return r.fileTrailer(fields)
+
+	case "88":
+		return errors.New("88 continuation without a record to continue")
+	}
+	return errors.Errorf("unknown record type %q", record.code)
+}
+
+// count adds the physical records of record to the file, group and account
+// open around it
+func (r *BAI2Reader) count(record bai2Record) {
+	records := len(record.segments)
+	r.records += records
+	if r.group != nil {
+		r.group.records += records
+	}
+	if r.account != nil {
+		r.account.records += records
+	}
+}
+
+// fileHeader parses 01,sender,receiver,creation date,creation time,file ID,
+// record length,block size,version
+func (r *BAI2Reader) fileHeader(fields *bai2Fields) error {
+	fields.skip(4)
+	r.fileID = strings.TrimSpace(fields.next())
+	fields.skip(2)
+	version := strings.TrimSpace(fields.next())
+
+	if r.fileID == "" {
+		return errors.New("missing file ID")
+	}
+	if version != "2" {
+		return errors.Errorf("unsupported BAI version %q", version)
+	}
+	return nil
+}
+
+// groupHeader parses 02,receiver,originator,status,as-of date,as-of time,
+// currency,as-of date modifier
+func (r *BAI2Reader) groupHeader(fields *bai2Fields) error {
+	fields.skip(3)
+	r.group.asOf = strings.TrimSpace(fields.next())
+	fields.skip(1)
+	currency, err := parseCurrency(fields.next())
+	if err != nil {
+		return err
+	}
+
+	date, err := time.Parse("060102", r.group.asOf)
+	if err != nil {
+		return errors.Wrap(err, "invalid as-of date")
+	}
+	r.group.date = date
+	// Amounts are in US dollars unless the group says otherwise
+	r.group.currency = currency
+	if r.group.currency == "" {
+		r.group.currency = "USD"
+	}
+	return nil
+}
+
+// accountIdentifier parses 03,account,currency followed by the type code,
+// amount, item count and funds type of each summary and status
+func (r *BAI2Reader) accountIdentifier(fields *bai2Fields) error {
+	r.account.number = strings.TrimSpace(fields.next())
+	currency, err := parseCurrency(fields.next())
+	if err != nil {
+		return err
+	}
+	r.account.currency = currency
+	if r.account.currency == "" {
+		r.account.currency = r.group.currency
+	}
+
+	for fields.more() {
+		code, value := strings.TrimSpace(fields.next()), strings.TrimSpace(fields.next())
+		fields.skip(1)
+		if err := fields.skipFundsType(); err != nil {
+			return errors.Wrapf(err, "type code %s", code)
+		}
+		if code == "" || value == "" {
+			continue
+		}
+
+		amount, err := parseBAI2Amount(value)
+		if err != nil {
+			return errors.Wrapf(err, "type code %s", code)
+		}
+		r.account.total += amount
+
+		balance := model.NewMoney(amount, r.account.currency)
+		switch code {
+		case "010":
+			r.account.opening = &balance
+		case "015":
+			r.account.closing = &balance
+		}
+	}
+	return nil
+}
+
+// transactionDetail parses 16,type code,amount,funds type,bank reference,
+// customer reference,text. The text runs to the end of the record, its
+// continuations on new lines.
+func (r *BAI2Reader) transactionDetail(fields *bai2Fields) error {
+	code := strings.TrimSpace(fields.next())
+	value := strings.TrimSpace(fields.next())
+	if err := fields.skipFundsType(); err != nil {
+		return err
+	}
+	bankReference := strings.TrimSpace(fields.next())
+	customerReference := strings.TrimSpace(fields.next())
+	text := fields.rest()
+
+	// Skipped details still count in the control total
+	var amount int64
+	if value != "" {
+		var err error
+		if amount, err = parseBAI2Amount(value); err != nil {
+			return err
+		}
+		r.account.total += amount
+	}
+
+	// 890 carries information without an amount, not a transaction
+	if code == "890" {
+		return nil
+	}
+
+	credit, known, err := bai2Direction(code)
+	if err != nil {
+		return err
+	}
+	if !known {
+		log.Printf("Skipping BAI2 detail %q of account %s: type code %s is neither a credit nor a debit", bankReference, r.account.number, code)
+		return nil
+	}
+	if value == "" {
+		return errors.Errorf("missing amount for type code %s", code)
+	}
+	if amount < 0 {
+		return errors.Errorf("negative amount %s, the type code gives the sign", value)
+	}
+
+	if !credit {
+		amount = -amount
+	}
+	reference := customerReference
+	if reference == "" || reference == "0" {
+		reference = bankReference
+	}
+
+	r.entries++
+	r.account.rows = append(r.account.rows, model.BankStatement{
+		ID:        fmt.Sprintf("%s/%d", r.fileID, r.entries),
+		Amount:    model.NewMoney(amount, r.account.currency),
+		Date:      r.group.date,
+		Reference: reference,
+		Narrative: text,
+	})
+	return nil
+}
+
+// accountTrailer parses 49,control total,record count and releases the rows of
+// the account once both match
+func (r *BAI2Reader) accountTrailer(fields *bai2Fields) error {
+	if err := checkBAI2Total(fields.next(), r.account.total); err != nil {
+		return errors.Wrapf(err, "account %s", r.account.number)
+	}
+	if err := checkBAI2Count(fields.next(), r.account.records, "records"); err != nil {
+		return errors.Wrapf(err, "account %s", r.account.number)
+	}
+
+	if r.account.opening != nil && r.account.closing != nil {
+		r.statements = append(r.statements, model.Statement{
+			Reference:      r.fileID,
+			Account:        r.account.number,
+			Number:         r.group.asOf,
+			OpeningBalance: model.Balance{Date: r.group.date, Amount: *r.account.opening},
+			ClosingBalance: model.Balance{Date: r.group.date, Amount: *r.account.closing},
+		})
+	}
+
+	r.queue = r.account.rows
+	r.group.total += r.account.total
+	r.group.accounts++
+	r.account = nil
+	return nil
+}
+
+// groupTrailer parses 98,control total,account count,record count
+func (r *BAI2Reader) groupTrailer(fields *bai2Fields) error {
+	if err := checkBAI2Total(fields.next(), r.group.total); err != nil {
+		return errors.Wrapf(err, "group %s", r.group.asOf)
+	}
+	if err := checkBAI2Count(fields.next(), r.group.accounts, "accounts"); err != nil {
+		return errors.Wrapf(err, "group %s", r.group.asOf)
+	}
+	if err := checkBAI2Count(fields.next(), r.group.records, "records"); err != nil {
+		return errors.Wrapf(err, "group %s", r.group.asOf)
+	}
+
+	r.total += r.group.total
+	r.groups++
+	r.group = nil
+	return nil
+}
+
+// fileTrailer parses 99,control total,group count,record count
+func (r *BAI2Reader) fileTrailer(fields *bai2Fields) error {
+	if err := checkBAI2Total(fields.next(), r.total); err != nil {
+		return errors.Wrap(err, "file")
+	}
+	if err := checkBAI2Count(fields.next(), r.groups, "groups"); err != nil {
+		return errors.Wrap(err, "file")
+	}
+	if err := checkBAI2Count(fields.next(), r.records, "records"); err != nil {
+		return errors.Wrap(err, "file")
+	}
+
+	r.ended = true
+	return nil
+}
+
+// checkBAI2Total compares the control total of a trailer, the sum of the
+// amounts it covers, to the one computed
+func checkBAI2Total(value string, total int64) error {
+	expected, err := parseBAI2Amount(strings.TrimSpace(value))
+	if err != nil {
+		return errors.Wrap(err, "invalid control total")
+	}
+	if expected != total {
+		return errors.Errorf("control total %d does not match the computed %d", expected, total)
+	}
+	return nil
+}
+
+// checkBAI2Count compares a count of a trailer to the one computed
+func checkBAI2Count(value string, count int, what string) error {
+	expected, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil {
+		return errors.Errorf("invalid number of %s %q", what, value)
+	}
+	if expected != count {
+		return errors.Errorf("number of %s %d does not match the %d read", what, expected, count)
+	}
+	return nil
+}
+
+// readRecord returns the next record with its 88 continuations
+func (r *BAI2Reader) readRecord() (bai2Record, error) {
+	line, ok := r.readLine()
+	if !ok {
+		if err := r.scanner.Err(); err != nil {
+			return bai2Record{}, errors.Wrap(err, "[BAI2Reader] error reading file")
+		}
+		return bai2Record{}, io.EOF
+	}
+
+	code, content, _ := strings.Cut(line, ",")
+	record := bai2Record{
+		code:     strings.TrimSpace(code),
+		segments: []string{strings.TrimSuffix(content, "/")},
+		line:     r.line,
+	}
+	if record.code == "88" {
+		return record, nil
+	}
+
+	for {
+		next, ok := r.readLine()
+		if !ok {
+			break
+		}
+		code, content, _ := strings.Cut(next, ",")
+		if strings.TrimSpace(code) != "88" {
+			r.peeked, r.hasPeeked = next, true
+			break
+		}
+		record.segments = append(record.segments, strings.TrimSuffix(content, "/"))
+	}
+	return record, nil
+}
+
+// readLine returns the next line holding data, without the padding of fixed
+// length records
+func (r *BAI2Reader) readLine() (string, bool) {
+	if r.hasPeeked {
+		r.hasPeeked = false
+		return r.peeked, true
+	}
+	for r.scanner.Scan() {
+		r.line++
+		line := strings.TrimRight(r.scanner.Text(), " \r")
+		if r.line == 1 {
+			line = strings.TrimPrefix(line, "\ufeff")
+		}
+		if line != "" {
+			return line, true
+		}
+	}
+	return "", false
+}
+
+// next returns the next field, empty once the record is exhausted. The end of
+// a physical record also ends a field.
+func (f *bai2Fields) next() string {
+	if !f.more() {
+		return ""
+	}
+	segment := f.segments[f.segment][f.pos:]
+	if i := strings.IndexByte(segment, ','); i >= 0 {
+		f.pos += i + 1
+		return segment[:i]
+	}
+	f.segment++
+	f.pos = 0
+	return segment
+}
+
+func (f *bai2Fields) skip(n int) {
+	for i := 0; i < n; i++ {
+		f.next()
+	}
+}
+
+func (f *bai2Fields) more() bool {
+	return f.segment < len(f.segments)
+}
+
+// rest returns the remainder of the record, each continuation on a new line
+func (f *bai2Fields) rest() string {
+	if !f.more() {
+		return ""
+	}
+	lines := append([]string{f.segments[f.segment][f.pos:]}, f.segments[f.segment+1:]...)
+	f.segment = len(f.segments)
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+// skipFundsType skips a funds type and the availability fields following it:
+// three amounts for S, a value date and time for V and a count of day and
+// amount pairs for D
+func (f *bai2Fields) skipFundsType() error {
+	fundsType := strings.ToUpper(strings.TrimSpace(f.next()))
+	switch fundsType {
+	case "", "0", "1", "2", "Z":
+	case "S":
+		f.skip(3)
+	case "V":
+		f.skip(2)
+	case "D":
+		count, err := strconv.Atoi(strings.TrimSpace(f.next()))
+		if err != nil || count < 0 {
+			return errors.New("invalid number of distributed availabilities")
+		}
+		f.skip(2 * count)
+	default:
+		return errors.Errorf("invalid funds type %q", fundsType)
+	}
+	return nil
+}
+
+// bai2Direction tells whether a detail type code is a credit (100 to 399) or a
+// debit (400 to 699). Loan (7xx) and bank specific (9xx) codes are not known
+// to be either.
+func bai2Direction(code string) (credit, known bool, err error) {
+	value, err := strconv.Atoi(code)
+	if err != nil || len(code) != 3 {
+		return false, false, errors.Errorf("invalid type code %q", code)
+	}
+	switch {
+	case value >= 100 && value <= 399:
+		return true, true, nil
+	case value >= 400 && value <= 699:
+		return false, true, nil
+	}
+	return false, false, nil
+}
+
+// parseBAI2Amount parses an amount in minor units, optionally signed
+func parseBAI2Amount(value string) (int64, error) {
+	digits := strings.TrimPrefix(strings.TrimPrefix(value, "+"), "-")
+	if digits == "" || !model.IsDigits(digits) {
+		return 0, errors.Errorf("invalid amount %q", value)
+	}
+	return strconv.ParseInt(value, 10, 64)
+}
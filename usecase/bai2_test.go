@@ -0,0 +1,176 @@
+package usecase_test
+
+import (
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aferryc/yars/model"
+	"github.com/aferryc/yars/usecase"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const bai2File = `01,BANKUS33,CUSTOMER,230115,0200,FILE-1,80,,2/
+02,CUSTOMER,BANKUS33,1,230114,,USD,2/
+03,0975312468,USD,010,100000,,,015,150000,,/
+88,040,90000,,/
+16,195,75000,0,BANKREF1,TX123,WIRE FROM ACME CORP/
+88,INVOICE 42
+16,475,25000,S,10000,15000,0,BANKREF2,0,CHECK PAID/
+16,890,,,BANKREF3,,MEMO/
+49,440000,7/
+03,0975312469,,010,5000,,/
+16,399,1250,V,230115,1200,,TX789,/
+49,6250,3/
+98,446250,2,12/
+99,446250,1,14/
+`
+
+func TestBAI2Reader(t *testing.T) {
+	t.Run("Reads the details and balances of every account", func(t *testing.T) {
+		// Setup
+		reader := usecase.NewBAI2Reader(strings.NewReader(bai2File))
+
+		// Execute
+		var rows []model.BankStatement
+		for {
+			row, err := reader.Next()
+			if err == io.EOF {
+				break
+			}
+			require.NoError(t, err)
+			rows = append(rows, row)
+		}
+
+		// Assert
+		date := time.Date(2023, 1, 14, 0, 0, 0, 0, time.UTC)
+		assert.Equal(t, []model.BankStatement{
+			{
+				ID:        "FILE-1/1",
+				Amount:    model.MustParseMoney("750.00", "USD"),
+				Date:      date,
+				Reference: "TX123",
+				Narrative: "WIRE FROM ACME CORP\nINVOICE 42",
+			},
+			{
+				// Without a customer reference the bank's is used
+				ID:        "FILE-1/2",
+				Amount:    model.MustParseMoney("-250.00", "USD"),
+				Date:      date,
+				Reference: "BANKREF2",
+				Narrative: "CHECK PAID",
+			},
+			{
+				// The 890 memo is not a transaction
+				ID:        "FILE-1/3",
+				Amount:    model.MustParseMoney("12.50", "USD"),
+				Date:      date,
+				Reference: "TX789",
+			},
+		}, rows)
+
+		// Only the first account has both ledger balances
+		assert.Equal(t, []model.Statement{
+			{
+				Reference:      "FILE-1",
+				Account:        "0975312468",
+				Number:         "230114",
+				OpeningBalance: model.Balance{Date: date, Amount: model.MustParseMoney("1000.00", "USD")},
+				ClosingBalance: model.Balance{Date: date, Amount: model.MustParseMoney("1500.00", "USD")},
+			},
+		}, reader.Statements())
+	})
+
+	t.Run("Skips details neither a credit nor a debit", func(t *testing.T) {
+		// Setup
+		file := strings.Replace(bai2File, "16,399,", "16,920,", 1)
+		reader := usecase.NewBAI2Reader(strings.NewReader(file))
+
+		// Execute
+		var ids []string
+		for {
+			row, err := reader.Next()
+			if err == io.EOF {
+				break
+			}
+			require.NoError(t, err)
+			ids = append(ids, row.ID)
+		}
+
+		// Assert
+		// The skipped amount still counts in the control totals
+		assert.Equal(t, []string{"FILE-1/1", "FILE-1/2"}, ids)
+	})
+
+	tests := []struct {
+		name        string
+		old, new    string
+		expectedErr string
+	}{
+		{
+			name:        "Account control total mismatch",
+			old:         "49,440000,7/",
+			new:         "49,440001,7/",
+			expectedErr: "line 9: account 0975312468: control total 440001 does not match the computed 440000",
+		},
+		{
+			name:        "Account record count mismatch",
+			old:         "49,6250,3/",
+			new:         "49,6250,2/",
+			expectedErr: "number of records 2 does not match the 3 read",
+		},
+		{
+			name:        "Group account count mismatch",
+			old:         "98,446250,2,12/",
+			new:         "98,446250,3,12/",
+			expectedErr: "number of accounts 3 does not match the 2 read",
+		},
+		{
+			name:        "File record count mismatch",
+			old:         "99,446250,1,14/",
+			new:         "99,446250,1,13/",
+			expectedErr: "number of records 13 does not match the 14 read",
+		},
+		{
+			name:        "Missing file trailer",
+			old:         "99,446250,1,14/",
+			new:         "",
+			expectedErr: "file ends before its 99 trailer",
+		},
+		{
+			name:        "Invalid type code",
+			old:         "16,399,",
+			new:         "16,39X,",
+			expectedErr: "invalid type code",
+		},
+		{
+			name:        "Unsupported version",
+			old:         "80,,2/",
+			new:         "80,,1/",
+			expectedErr: "unsupported BAI version",
+		},
+		{
+			name:        "Detail outside an account",
+			old:         "49,6250,3/\n",
+			new:         "49,6250,3/\n16,195,100,0,,,/\n",
+			expectedErr: "16 transaction detail outside an account",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Setup
+			reader := usecase.NewBAI2Reader(strings.NewReader(strings.Replace(bai2File, tt.old, tt.new, 1)))
+
+			// Execute
+			var err error
+			for err == nil {
+				_, err = reader.Next()
+			}
+
+			// Assert
+			assert.ErrorContains(t, err, tt.expectedErr)
+		})
+	}
+}
@@ -0,0 +1,358 @@
+package usecase
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/aferryc/yars/model"
+	"github.com/pkg/errors"
+)
+
+// CamtReader streams the entries of ISO 20022 camt.053 statements and camt.054
+// debit/credit notifications, whatever their version. Entries are decoded one
+// at a time, so files larger than memory can be read.
+type CamtReader struct {
+	decoder *xml.Decoder
+	// path holds the elements open around the current token
+	path []string
+	// statement is the statement or notification being read
+	statement  *model.Statement
+	hasOpening bool
+	hasClosing bool
+	statements []model.Statement
+	// queue holds the rows of a batched entry not returned yet
+	queue []model.BankStatement
+	// entries counts the entries read so far, numbering the row IDs
+	entries int
+}
+
+type camtAmount struct {
+	Value    string `xml:",chardata"`
+	Currency string `xml:"Ccy,attr"`
+}
+
+type camtDate struct {
+	Date     string `xml:"Dt"`
+	DateTime string `xml:"DtTm"`
+}
+
+type camtParty struct {
+	Name      string `xml:"Nm"`
+	PartyName string `xml:"Pty>Nm"`
+}
+
+type camtBalance struct {
+	Type      string     `xml:"Tp>CdOrPrtry>Cd"`
+	Amount    camtAmount `xml:"Amt"`
+	CdtDbtInd string     `xml:"CdtDbtInd"`
+	Date      camtDate   `xml:"Dt"`
+}
+
+type camtAccount struct {
+	IBAN     string `xml:"Id>IBAN"`
+	Other    string `xml:"Id>Othr>Id"`
+	Currency string `xml:"Ccy"`
+}
+
+type camtEntry struct {
+	EntryRef     string          `xml:"NtryRef"`
+	Amount       camtAmount      `xml:"Amt"`
+	CdtDbtInd    string          `xml:"CdtDbtInd"`
+	BookingDate  camtDate        `xml:"BookgDt"`
+	ValueDate    camtDate        `xml:"ValDt"`
+	ServicerRef  string          `xml:"AcctSvcrRef"`
+	AddtlInfo    string          `xml:"AddtlNtryInf"`
+	Transactions []camtTxDetails `xml:"NtryDtls>TxDtls"`
+}
+
+type camtTxDetails struct {
+	EndToEndID   string     `xml:"Refs>EndToEndId"`
+	ServicerRef  string     `xml:"Refs>AcctSvcrRef"`
+	Amount       camtAmount `xml:"Amt"`
+	TxAmount     camtAmount `xml:"AmtDtls>TxAmt>Amt"`
+	CdtDbtInd    string     `xml:"CdtDbtInd"`
+	Debtor       camtParty  `xml:"RltdPties>Dbtr"`
+	Creditor     camtParty  `xml:"RltdPties>Cdtr"`
+	Unstructured []string   `xml:"RmtInf>Ustrd"`
+	CreditorRef  []string   `xml:"RmtInf>Strd>CdtrRefInf>Ref"`
+	AddtlInfo    string     `xml:"AddtlTxInf"`
+}
+
+// NewCamtReader creates a new CamtReader reading r
+func NewCamtReader(r io.Reader) *CamtReader {
+	return &CamtReader{
+		decoder: xml.NewDecoder(r),
+	}
+}
+
+// Next returns the next row, or io.EOF after the last one. An entry becomes
+// one row, or one row per transaction when it batches several with their own
+// amounts. Rows take the ID <statement ID>/<entry number>, followed by
+// /<transaction number> for the transactions of a batch.
+func (r *CamtReader) Next() (model.BankStatement, error) {
+	for len(r.queue) == 0 {
+		token, err := r.decoder.Token()
+		if err == io.EOF {
+			return model.BankStatement{}, io.EOF
+		}
+		if err != nil {
+			return model.BankStatement{}, errors.Wrap(err, "[CamtReader] error reading file")
+		}
+
+		switch element := token.(type) {
+		case xml.StartElement:
+			if err := r.startElement(element); err != nil {
+				return model.BankStatement{}, err
+			}
+		case xml.EndElement:
+			r.path = r.path[:len(r.path)-1]
+			if name := element.Name.Local; (name == "Stmt" || name == "Ntfctn") && r.statement != nil {
+				if r.hasOpening && r.hasClosing {
+					r.statements = append(r.statements, *r.statement)
+				}
+				r.statement = nil
+			}
+		}
+	}
+
+	row := r.queue[0]
+	r.queue = r.queue[1:]
+	return row, nil
+}
+
+// Statements returns the statements read so far holding an opening and a
+// closing balance, without their entries. Notifications have no balances.
+func (r *CamtReader) Statements() []model.Statement {
+	return r.statements
+}
+
+// startElement handles the elements of a statement, decoding the balances and
+// entries whole and descending into the others
+func (r *CamtReader) startElement(element xml.StartElement) error {
+	name := element.Name.Local
+	inStatement := r.statement != nil && len(r.path) > 0 &&
+		(r.path[len(r.path)-1] == "Stmt" || r.path[len(r.path)-1] == "Ntfctn")
+
+	switch {
+	case (name == "Stmt" || name == "Ntfctn") && r.statement == nil:
+		r.statement = &model.Statement{}
+		r.hasOpening, r.hasClosing = false, false
+
+	case inStatement && name == "Id":
+		var id string
+		if err := r.decoder.DecodeElement(&id, &element); err != nil {
+			return errors.Wrap(err, "[CamtReader] invalid statement ID")
+		}
+		r.statement.Reference = strings.TrimSpace(id)
+		return nil
+
+	case inStatement && name == "ElctrncSeqNb":
+		var number string
+		if err := r.decoder.DecodeElement(&number, &element); err != nil {
+			return errors.Wrap(err, "[CamtReader] invalid statement number")
+		}
+		r.statement.Number = strings.TrimSpace(number)
+		return nil
+
+	case inStatement && name == "Acct":
+		var account camtAccount
+		if err := r.decoder.DecodeElement(&account, &element); err != nil {
+			return errors.Wrap(err, "[CamtReader] invalid account")
+		}
+		r.statement.Account = account.IBAN
+		if r.statement.Account == "" {
+			r.statement.Account = account.Other
+		}
+		return nil
+
+	case inStatement && name == "Bal":
+		var balance camtBalance
+		if err := r.decoder.DecodeElement(&balance, &element); err != nil {
+			return errors.Wrap(err, "[CamtReader] invalid balance")
+		}
+		return r.addBalance(balance)
+
+	case inStatement && name == "Ntry":
+		var entry camtEntry
+		if err := r.decoder.DecodeElement(&entry, &element); err != nil {
+			return errors.Wrap(err, "[CamtReader] invalid entry")
+		}
+		r.entries++
+		rows, err := r.entryRows(entry)
+		if err != nil {
+			return errors.Wrapf(err, "[CamtReader] entry %d of %s", r.entries, r.statement.Reference)
+		}
+		r.queue = rows
+		return nil
+	}
+
+	r.path = append(r.path, name)
+	return nil
+}
+
+// addBalance keeps the opening (OPBD, or PRCD, the previous closing) and
+// closing (CLBD) booked balances of the statement
+func (r *CamtReader) addBalance(balance camtBalance) error {
+	if balance.Type != "OPBD" && balance.Type != "PRCD" && balance.Type != "CLBD" {
+		return nil
+	}
+
+	amount, err := parseCamtAmount(balance.Amount, balance.CdtDbtInd)
+	if err != nil {
+		return errors.Wrapf(err, "[CamtReader] invalid %s balance", balance.Type)
+	}
+	date, err := parseCamtDate(balance.Date)
+	if err != nil {
+		return errors.Wrapf(err, "[CamtReader] invalid %s balance", balance.Type)
+	}
+
+	switch {
+	case balance.Type == "CLBD":
+		r.statement.ClosingBalance = model.Balance{Date: date, Amount: amount}
+		r.hasClosing = true
+	case balance.Type == "OPBD" || !r.hasOpening:
+		r.statement.OpeningBalance = model.Balance{Date: date, Amount: amount}
+		r.hasOpening = true
+	}
+	return nil
+}
+
+// entryRows maps an entry to its rows. The transactions of a batch are only
+// split into rows when each has its own amount, otherwise the entry is kept as
+// one row with the details of its first transaction.
+func (r *CamtReader) entryRows(entry camtEntry) ([]model.BankStatement, error) {
+	date, err := parseCamtDate(entry.BookingDate)
+	if err != nil {
+		date, err = parseCamtDate(entry.ValueDate)
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid booking date")
+	}
+
+	id := fmt.Sprintf("%s/%d", r.statement.Reference, r.entries)
+	split := len(entry.Transactions) > 1
+	for _, tx := range entry.Transactions {
+		if tx.Amount.Value == "" && tx.TxAmount.Value == "" {
+			split = false
+		}
+	}
+
+	if !split {
+		amount, err := parseCamtAmount(entry.Amount, entry.CdtDbtInd)
+		if err != nil {
+			return nil, err
+		}
+		var tx camtTxDetails
+		if len(entry.Transactions) > 0 {
+			tx = entry.Transactions[0]
+		}
+		row := camtRow(id, amount, date, entry, tx)
+		return []model.BankStatement{row}, nil
+	}
+
+	rows := make([]model.BankStatement, 0, len(entry.Transactions))
+	for i, tx := range entry.Transactions {
+		value := tx.Amount
+		if value.Value == "" {
+			value = tx.TxAmount
+		}
+		indicator := tx.CdtDbtInd
+		if indicator == "" {
+			indicator = entry.CdtDbtInd
+		}
+		amount, err := parseCamtAmount(value, indicator)
+		if err != nil {
+			return nil, errors.Wrapf(err, "transaction %d", i+1)
+		}
+		rows = append(rows, camtRow(fmt.Sprintf("%s/%d", id, i+1), amount, date, entry, tx))
+	}
+	return rows, nil
+}
+
+// camtRow fills a row with the references, counterparty and remittance
+// information of the transaction, falling back on those of the entry
+func camtRow(id string, amount model.Money, date time.Time, entry camtEntry, tx camtTxDetails) model.BankStatement {
+	endToEndID := strings.TrimSpace(tx.EndToEndID)
+	if endToEndID == "NOTPROVIDED" {
+		endToEndID = ""
+	}
+
+	// The end-to-end ID is set by the payer, so it is the most likely to
+	// carry the ID of the internal transaction
+	reference := endToEndID
+	candidates := append(append([]string{}, tx.CreditorRef...), tx.ServicerRef, entry.ServicerRef, entry.EntryRef)
+	for _, candidate := range candidates {
+		if reference != "" {
+			break
+		}
+		reference = strings.TrimSpace(candidate)
+	}
+
+	// The counterparty of money received is the debtor, of money paid the
+	// creditor
+	party := tx.Creditor
+	if amount.Sign() > 0 {
+		party = tx.Debtor
+	}
+	counterparty := strings.TrimSpace(party.Name)
+	if counterparty == "" {
+		counterparty = strings.TrimSpace(party.PartyName)
+	}
+
+	narrative := strings.TrimSpace(strings.Join(tx.Unstructured, "\n"))
+	for _, candidate := range []string{tx.AddtlInfo, entry.AddtlInfo} {
+		if narrative != "" {
+			break
+		}
+		narrative = strings.TrimSpace(candidate)
+	}
+
+	return model.BankStatement{
+		ID:           id,
+		Amount:       amount,
+		Date:         date,
+		Reference:    reference,
+		Narrative:    narrative,
+		EndToEndID:   endToEndID,
+		Counterparty: counterparty,
+	}
+}
+
+// parseCamtAmount parses an amount and its currency, negative for a DBIT
+// indicator
+func parseCamtAmount(amount camtAmount, indicator string) (model.Money, error) {
+	currency, err := parseCurrency(amount.Currency)
+	if err != nil {
+		return model.Money{}, err
+	}
+	money, err := model.ParseMoney(amount.Value, currency)
+	if err != nil {
+		return model.Money{}, err
+	}
+
+	switch strings.TrimSpace(indicator) {
+	case "CRDT":
+		return money, nil
+	case "DBIT":
+		return money.Neg(), nil
+	}
+	return model.Money{}, errors.Errorf("invalid credit/debit indicator %q", indicator)
+}
+
+// parseCamtDate parses a date or a date and time, the local date of the
+// latter being kept
+func parseCamtDate(date camtDate) (time.Time, error) {
+	if value := strings.TrimSpace(date.Date); value != "" {
+		return time.Parse("2006-01-02", value)
+	}
+	value := strings.TrimSpace(date.DateTime)
+	for _, layout := range []string{time.RFC3339Nano, "2006-01-02T15:04:05.999999999"} {
+		if parsed, err := time.Parse(layout, value); err == nil {
+			return time.Date(parsed.Year(), parsed.Month(), parsed.Day(), 0, 0, 0, 0, time.UTC), nil
+		}
+	}
+	return time.Time{}, errors.Errorf("invalid date %q", value)
+}
@@ -0,0 +1,234 @@
+package usecase_test
+
+import (
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aferryc/yars/model"
+	"github.com/aferryc/yars/usecase"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const camt053File = `<?xml version="1.0" encoding="UTF-8"?>
+<Document xmlns="urn:iso:std:iso:20022:tech:xsd:camt.053.001.02">
+  <BkToCstmrStmt>
+    <GrpHdr>
+      <MsgId>MSG-1</MsgId>
+      <CreDtTm>2023-01-16T18:00:00Z</CreDtTm>
+    </GrpHdr>
+    <Stmt>
+      <Id>STMT-1</Id>
+      <ElctrncSeqNb>42</ElctrncSeqNb>
+      <Acct>
+        <Id><IBAN>NL91ABNA0417164300</IBAN></Id>
+        <Ccy>EUR</Ccy>
+      </Acct>
+      <Bal>
+        <Tp><CdOrPrtry><Cd>PRCD</Cd></CdOrPrtry></Tp>
+        <Amt Ccy="EUR">1234.56</Amt>
+        <CdtDbtInd>CRDT</CdtDbtInd>
+        <Dt><Dt>2023-01-14</Dt></Dt>
+      </Bal>
+      <Bal>
+        <Tp><CdOrPrtry><Cd>CLBD</Cd></CdOrPrtry></Tp>
+        <Amt Ccy="EUR">734.81</Amt>
+        <CdtDbtInd>CRDT</CdtDbtInd>
+        <Dt><Dt>2023-01-16</Dt></Dt>
+      </Bal>
+      <Ntry>
+        <NtryRef>E1</NtryRef>
+        <Amt Ccy="EUR">500.25</Amt>
+        <CdtDbtInd>CRDT</CdtDbtInd>
+        <BookgDt><Dt>2023-01-15</Dt></BookgDt>
+        <AcctSvcrRef>BANKREF1</AcctSvcrRef>
+        <NtryDtls>
+          <TxDtls>
+            <Refs><EndToEndId>TX123</EndToEndId></Refs>
+            <RltdPties>
+              <Dbtr><Nm>ACME CORP</Nm></Dbtr>
+              <Cdtr><Nm>OURSELVES</Nm></Cdtr>
+            </RltdPties>
+            <RmtInf>
+              <Ustrd>INVOICE 42</Ustrd>
+              <Ustrd>INVOICE 43</Ustrd>
+            </RmtInf>
+          </TxDtls>
+        </NtryDtls>
+      </Ntry>
+      <Ntry>
+        <Amt Ccy="EUR">1000.00</Amt>
+        <CdtDbtInd>DBIT</CdtDbtInd>
+        <BookgDt><DtTm>2023-01-16T23:30:00+02:00</DtTm></BookgDt>
+        <AcctSvcrRef>BATCH-1</AcctSvcrRef>
+        <NtryDtls>
+          <TxDtls>
+            <Refs><EndToEndId>TX456</EndToEndId></Refs>
+            <AmtDtls><TxAmt><Amt Ccy="EUR">600.00</Amt></TxAmt></AmtDtls>
+            <RltdPties><Cdtr><Pty><Nm>SUPPLIER A</Nm></Pty></Cdtr></RltdPties>
+          </TxDtls>
+          <TxDtls>
+            <Refs><EndToEndId>NOTPROVIDED</EndToEndId><AcctSvcrRef>BANKREF3</AcctSvcrRef></Refs>
+            <Amt Ccy="EUR">400.00</Amt>
+            <CdtDbtInd>DBIT</CdtDbtInd>
+            <RltdPties><Cdtr><Nm>SUPPLIER B</Nm></Cdtr></RltdPties>
+            <AddtlTxInf>SALARY</AddtlTxInf>
+          </TxDtls>
+        </NtryDtls>
+      </Ntry>
+    </Stmt>
+  </BkToCstmrStmt>
+</Document>`
+
+const camt054File = `<?xml version="1.0" encoding="UTF-8"?>
+<Document xmlns="urn:iso:std:iso:20022:tech:xsd:camt.054.001.08">
+  <BkToCstmrDbtCdtNtfctn>
+    <Ntfctn>
+      <Id>NTF-1</Id>
+      <Acct><Id><Othr><Id>0417164300</Id></Othr></Id></Acct>
+      <Ntry>
+        <Amt Ccy="USD">300.00</Amt>
+        <CdtDbtInd>CRDT</CdtDbtInd>
+        <ValDt><Dt>2023-02-01</Dt></ValDt>
+        <AddtlNtryInf>COLLECTION</AddtlNtryInf>
+        <NtryDtls>
+          <TxDtls>
+            <Refs><EndToEndId>TX1</EndToEndId></Refs>
+            <RmtInf><Strd><CdtrRefInf><Ref>RF18539007547034</Ref></CdtrRefInf></Strd></RmtInf>
+          </TxDtls>
+          <TxDtls>
+            <Refs><EndToEndId>TX2</EndToEndId></Refs>
+          </TxDtls>
+        </NtryDtls>
+      </Ntry>
+    </Ntfctn>
+  </BkToCstmrDbtCdtNtfctn>
+</Document>`
+
+func readCamt(t *testing.T, reader *usecase.CamtReader) []model.BankStatement {
+	var rows []model.BankStatement
+	for {
+		row, err := reader.Next()
+		if err == io.EOF {
+			return rows
+		}
+		require.NoError(t, err)
+		rows = append(rows, row)
+	}
+}
+
+func TestCamtReader(t *testing.T) {
+	t.Run("Reads the entries and balances of a camt.053 statement", func(t *testing.T) {
+		// Setup
+		reader := usecase.NewCamtReader(strings.NewReader(camt053File))
+
+		// Execute
+		rows := readCamt(t, reader)
+
+		// Assert
+		assert.Equal(t, []model.BankStatement{
+			{
+				ID:           "STMT-1/1",
+				Amount:       model.MustParseMoney("500.25", "EUR"),
+				Date:         time.Date(2023, 1, 15, 0, 0, 0, 0, time.UTC),
+				Reference:    "TX123",
+				Narrative:    "INVOICE 42\nINVOICE 43",
+				EndToEndID:   "TX123",
+				Counterparty: "ACME CORP",
+			},
+			{
+				// The transactions of the batch are split, on the local
+				// booking date
+				ID:           "STMT-1/2/1",
+				Amount:       model.MustParseMoney("-600.00", "EUR"),
+				Date:         time.Date(2023, 1, 16, 0, 0, 0, 0, time.UTC),
+				Reference:    "TX456",
+				EndToEndID:   "TX456",
+				Counterparty: "SUPPLIER A",
+			},
+			{
+				ID:           "STMT-1/2/2",
+				Amount:       model.MustParseMoney("-400.00", "EUR"),
+				Date:         time.Date(2023, 1, 16, 0, 0, 0, 0, time.UTC),
+				Reference:    "BANKREF3",
+				Narrative:    "SALARY",
+				Counterparty: "SUPPLIER B",
+			},
+		}, rows)
+
+		assert.Equal(t, []model.Statement{
+			{
+				Reference: "STMT-1",
+				Account:   "NL91ABNA0417164300",
+				Number:    "42",
+				OpeningBalance: model.Balance{
+					Date:   time.Date(2023, 1, 14, 0, 0, 0, 0, time.UTC),
+					Amount: model.MustParseMoney("1234.56", "EUR"),
+				},
+				ClosingBalance: model.Balance{
+					Date:   time.Date(2023, 1, 16, 0, 0, 0, 0, time.UTC),
+					Amount: model.MustParseMoney("734.81", "EUR"),
+				},
+			},
+		}, reader.Statements())
+	})
+
+	t.Run("Keeps a batch without transaction amounts as one entry", func(t *testing.T) {
+		// Setup
+		reader := usecase.NewCamtReader(strings.NewReader(camt054File))
+
+		// Execute
+		rows := readCamt(t, reader)
+
+		// Assert
+		assert.Equal(t, []model.BankStatement{
+			{
+				ID:         "NTF-1/1",
+				Amount:     model.MustParseMoney("300.00", "USD"),
+				Date:       time.Date(2023, 2, 1, 0, 0, 0, 0, time.UTC),
+				Reference:  "TX1",
+				Narrative:  "COLLECTION",
+				EndToEndID: "TX1",
+			},
+		}, rows)
+		// Notifications have no balances
+		assert.Empty(t, reader.Statements())
+	})
+
+	tests := []struct {
+		name        string
+		entry       string
+		expectedErr string
+	}{
+		{
+			name:        "Unknown credit/debit indicator",
+			entry:       `<Amt Ccy="EUR">1.00</Amt><CdtDbtInd>X</CdtDbtInd><BookgDt><Dt>2023-01-15</Dt></BookgDt>`,
+			expectedErr: "invalid credit/debit indicator",
+		},
+		{
+			name:        "Missing booking date",
+			entry:       `<Amt Ccy="EUR">1.00</Amt><CdtDbtInd>CRDT</CdtDbtInd>`,
+			expectedErr: "invalid booking date",
+		},
+		{
+			name:        "Invalid amount",
+			entry:       `<Amt Ccy="EUR">abc</Amt><CdtDbtInd>CRDT</CdtDbtInd><BookgDt><Dt>2023-01-15</Dt></BookgDt>`,
+			expectedErr: "entry 1 of STMT-1",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Setup
+			file := "<Document><BkToCstmrStmt><Stmt><Id>STMT-1</Id><Ntry>" + tt.entry + "</Ntry></Stmt></BkToCstmrStmt></Document>"
+			reader := usecase.NewCamtReader(strings.NewReader(file))
+
+			// Execute
+			_, err := reader.Next()
+
+			// Assert
+			assert.ErrorContains(t, err, tt.expectedErr)
+		})
+	}
+}
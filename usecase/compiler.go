@@ -1,14 +1,14 @@
 package usecase
 
 import (
+	"bufio"
+	"bytes"
 	"context"
 	"encoding/csv"
 	"io"
 	"log"
-	"os"
-	"strconv"
 	"strings"
-	"time"
+	"unicode/utf8"
 
 	"github.com/pkg/errors"
 
@@ -22,10 +22,14 @@ import (
 // FileCompiler implements the CompilerUseCase
 type FileCompiler struct {
 	bucketName      string
-	storageRepo     repository.GCSRepository
+	storageRepo     repository.StorageRepository
 	bankStmtRepo    repository.BankStatementRepository
 	transactionRepo repository.InternalTransactionRepository
-	kafkaRepo       repository.KafkaRepository
+	transactor      repository.Transactor
+	outboxRepo      repository.OutboxRepository
+	taskRepo        repository.ReconTaskRepository
+	profileRepo     repository.IngestionProfileRepository
+	statementRepo   repository.StatementRepository
 	batchSize       int
 	cfg             *config.Config
 }
@@ -33,96 +37,249 @@ type FileCompiler struct {
 // NewFileCompiler creates a new FileCompiler instance
 func NewFileCompiler(
 	cfg *config.Config,
-	storageRepo repository.GCSRepository,
+	storageRepo repository.StorageRepository,
 	bankStmtRepo repository.BankStatementRepository,
 	transactionRepo repository.InternalTransactionRepository,
-	kafkaRepo repository.KafkaRepository,
+	transactor repository.Transactor,
+	outboxRepo repository.OutboxRepository,
+	taskRepo repository.ReconTaskRepository,
+	profileRepo repository.IngestionProfileRepository,
+	statementRepo repository.StatementRepository,
 ) *FileCompiler {
 	return &FileCompiler{
 		cfg:             cfg,
 		storageRepo:     storageRepo,
 		bankStmtRepo:    bankStmtRepo,
 		transactionRepo: transactionRepo,
-		kafkaRepo:       kafkaRepo,
+		transactor:      transactor,
+		outboxRepo:      outboxRepo,
+		taskRepo:        taskRepo,
+		profileRepo:     profileRepo,
+		statementRepo:   statementRepo,
 	}
 }
 
-// ProcessFile handles the entire process of downloading, parsing and storing file data
-func (fc *FileCompiler) ProcessEvent(event []byte) error {
-	ctx := context.Background()
-	compilerEvent, err := parseEvent(event)
+// ProcessEvent handles the entire process of downloading, parsing and storing
+// file data. Ingestion stops between batches once ctx is cancelled.
+func (fc *FileCompiler) ProcessEvent(ctx context.Context, msg model.Message) error {
+	envelope, payload, err := eventPayload(msg, model.EventTypeCompilationRequested)
+	if errors.Is(err, errOtherEventType) {
+		return nil
+	}
 	if err != nil {
+		markTaskFailed(ctx, fc.taskRepo, envelope.TaskID, err)
+		return errors.Wrap(err, "[Compiler.ProcessFile] failed to read event")
+	}
+	ctx = model.ContextWithTraceParent(ctx, envelope.TraceParent)
+
+	compilerEvent, err := parseEvent(payload)
+	if err != nil {
+		markTaskFailed(ctx, fc.taskRepo, compilerEvent.TaskID, err)
 		return errors.Wrap(err, "[Compiler.ProcessFile] failed to unmarshal event")
 	}
 
+	// A redelivered event is skipped once the task is compiled, the hand over
+	// to reconciliation was stored along with the status
+	task, err := fc.taskRepo.Get(ctx, compilerEvent.TaskID)
+	if err != nil {
+		return errors.Wrap(err, "[Compiler.ProcessFile] error getting task")
+	}
+	switch task.Status {
+	case model.TaskCompiled, model.TaskReconciling, model.TaskCompleted:
+		log.Printf("Task %s is already compiled, skipping event", compilerEvent.TaskID)
+		return nil
+	}
+
+	if err := fc.taskRepo.UpdateStatus(ctx, compilerEvent.TaskID, model.TaskCompiling, ""); err != nil {
+		return errors.Wrap(err, "[Compiler.ProcessFile] error marking task as compiling")
+	}
+
+	if err := fc.compile(ctx, compilerEvent); err != nil {
+		markTaskFailed(ctx, fc.taskRepo, compilerEvent.TaskID, err)
+		return err
+	}
+
+	return nil
+}
+
+// compile ingests the files of the event and hands the task over to
+// reconciliation. The task is marked as compiled in the same transaction as
+// the reconciliation event is stored in the outbox, so the event is published
+// if and only if the task is compiled.
+func (fc *FileCompiler) compile(ctx context.Context, compilerEvent model.CompilerEvent) error {
+	profile, err := fc.ingestionProfile(ctx, compilerEvent.BankName)
+	if err != nil {
+		return err
+	}
+
 	for _, objectName := range []string{compilerEvent.Transaction, compilerEvent.BankStatement} {
-		if err := fc.processFile(ctx, objectName, compilerEvent.BankName); err != nil {
+		if err := fc.processFile(ctx, objectName, compilerEvent.TaskID, compilerEvent.BankName, profile); err != nil {
 			return errors.Wrap(err, "[Compiler.ProcessFile] error processing file")
 		}
 	}
 
-	err = fc.kafkaRepo.Publish(ctx, fc.cfg.Kafka.Topic.CompilerTopic, compilerEvent.TaskID, model.ReconciliationEvent{
+	envelope := model.NewEventEnvelope(ctx, model.EventTypeReconciliationRequested, compilerEvent.TaskID)
+	event, err := model.NewOutboxEvent(fc.cfg.Kafka.Topic.ReconTopic, envelope, model.ReconciliationEvent{
 		TaskID:    compilerEvent.TaskID,
+		BankName:  compilerEvent.BankName,
 		StartDate: compilerEvent.StartDate,
 		EndDate:   compilerEvent.EndDate,
 	})
 	if err != nil {
-		return errors.Wrap(err, "[Compiler.ProcessFile] error publishing event to Kafka")
+		return errors.Wrap(err, "[Compiler.ProcessFile] error creating reconciliation event")
 	}
 
-	return nil
+	return fc.transactor.WithinTx(ctx, func(ctx context.Context) error {
+		if err := fc.taskRepo.UpdateStatus(ctx, compilerEvent.TaskID, model.TaskCompiled, ""); err != nil {
+			return errors.Wrap(err, "[Compiler.ProcessFile] error marking task as compiled")
+		}
+		if err := fc.outboxRepo.Add(ctx, event); err != nil {
+			return errors.Wrap(err, "[Compiler.ProcessFile] error storing reconciliation event")
+		}
+		return nil
+	})
 }
 
-func (fc *FileCompiler) processFile(ctx context.Context, objectName, bankName string) error {
+// ingestionProfile returns the profile of the bank, the files of a bank without
+// one are read in the default formats
+func (fc *FileCompiler) ingestionProfile(ctx context.Context, bankName string) (model.IngestionProfile, error) {
+	profile, err := fc.profileRepo.Get(ctx, bankName)
+	if errors.Is(err, model.ErrIngestionProfileNotFound) {
+		return model.IngestionProfile{BankName: bankName}, nil
+	}
+	if err != nil {
+		return model.IngestionProfile{}, errors.Wrap(err, "[Compiler.ProcessFile] error getting ingestion profile")
+	}
+	return profile, nil
+}
+
+// processFile ingests one uploaded file, tagging every row with the task and
+// bank it belongs to so reconciliation only sees the data of its own task
+func (fc *FileCompiler) processFile(ctx context.Context, objectName, taskID, bankName string, profile model.IngestionProfile) error {
 	// Check if the objectName is empty
 	// This probably because user only upload other file
 	if objectName == "" {
 		return nil
 	}
 
-	csvReader, tempFile, err := fc.startFileStreamer(ctx, fc.bucketName, objectName)
+	isBankStatement := strings.Contains(objectName, model.BankStatementFile)
+	format := profile.TransactionFormat()
+	if isBankStatement {
+		format = profile.BankStatementFormat()
+	}
+
+	object, err := fc.storageRepo.NewReader(ctx, objectName)
+	if err != nil {
+		return errors.Wrap(err, "[Compiler.ProcessFile] error starting file streamer Bank File")
+	}
+	defer func() {
+		if err := object.Close(); err != nil {
+			log.Printf("Error closing file: %v", err)
+		}
+	}()
+
+	// Bank statements may also be delivered in the formats of the banks
+	var reader io.Reader = object
+	if isBankStatement {
+		buffered := bufio.NewReader(object)
+		switch detectStatementFormat(buffered) {
+		case statementFormatMT940:
+			if err := fc.processEntries(ctx, NewMT940Reader(buffered), statementFormatMT940, taskID, bankName); err != nil {
+				return errors.Wrapf(err, "[Compiler.ProcessFile] error processing %s bank statement file %s", statementFormatMT940, objectName)
+			}
+			return nil
+		case statementFormatCamt:
+			if err := fc.processEntries(ctx, NewCamtReader(buffered), statementFormatCamt, taskID, bankName); err != nil {
+				return errors.Wrapf(err, "[Compiler.ProcessFile] error processing %s bank statement file %s", statementFormatCamt, objectName)
+			}
+			return nil
+		case statementFormatBAI2:
+			if err := fc.processEntries(ctx, NewBAI2Reader(buffered), statementFormatBAI2, taskID, bankName); err != nil {
+				return errors.Wrapf(err, "[Compiler.ProcessFile] error processing %s bank statement file %s", statementFormatBAI2, objectName)
+			}
+			return nil
+		case statementFormatOFX:
+			if err := fc.processEntries(ctx, NewOFXReader(buffered), statementFormatOFX, taskID, bankName); err != nil {
+				return errors.Wrapf(err, "[Compiler.ProcessFile] error processing %s bank statement file %s", statementFormatOFX, objectName)
+			}
+			return nil
+		}
+		reader = buffered
+	}
+
+	csvReader, parser, err := fc.startFileStreamer(reader, format)
 	if err != nil {
 		return errors.Wrap(err, "[Compiler.ProcessFile] error starting file streamer Bank File")
 	}
-	if strings.Contains(objectName, model.BankStatementFile) {
-		err = fc.processBankStatement(csvReader, bankName)
+	if isBankStatement {
+		err = fc.processBankStatement(ctx, csvReader, parser, taskID, bankName)
 	} else {
-		err = fc.processInternalTransactions(csvReader)
+		err = fc.processInternalTransactions(ctx, csvReader, parser, taskID, bankName)
 	}
 	if err != nil {
 		return errors.Wrapf(err, "[Compiler.ProcessFile] error processing internal file %s", objectName)
 	}
-	defer func() {
-		if tempFile == nil {
-			return
-		}
-		if err := tempFile.Close(); err != nil {
-			log.Printf("Error closing file: %v", err)
-		}
-	}()
 
 	return nil
 }
 
-func (fc *FileCompiler) startFileStreamer(ctx context.Context, bucketName string, objectName string) (*csv.Reader, *os.File, error) {
-	tempFile, err := fc.storageRepo.DownloadFromBucket(ctx, objectName)
+// startFileStreamer reads the file as a CSV stream in format, positioned after
+// the header the columns of the returned parser are resolved against
+func (fc *FileCompiler) startFileStreamer(file io.Reader, format model.CSVFormat) (*csv.Reader, *RecordParser, error) {
+	csvReader := csv.NewReader(file)
+	if format.Delimiter != "" {
+		csvReader.Comma, _ = utf8.DecodeRuneInString(format.Delimiter)
+	}
+
+	// Assuming the first line is a header
+	header, err := csvReader.Read()
 	if err != nil {
-		return nil, nil, errors.Wrap(err, "[startFileStreamer] error downloading file from GCS")
+		return nil, nil, errors.Wrap(err, "[startFileStreamer] error reading header")
 	}
 
-	if _, err := tempFile.Seek(0, 0); err != nil {
-		return nil, tempFile, errors.Wrap(err, "[startFileStreamer] error rewinding temp file")
+	parser, err := NewRecordParser(format, header)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "[startFileStreamer] error resolving columns")
 	}
 
-	csvReader := csv.NewReader(tempFile)
+	return csvReader, parser, nil
+}
 
-	// Skip header
-	// Assuming the first line is a header
-	if _, err := csvReader.Read(); err != nil {
-		return csvReader, tempFile, errors.Wrap(err, "[startFileStreamer] error reading header")
+// Formats of the bank statement files
+const (
+	statementFormatCSV   = "csv"
+	statementFormatMT940 = "mt940"
+	statementFormatCamt  = "camt"
+	statementFormatBAI2  = "bai2"
+	statementFormatOFX   = "ofx"
+)
+
+// detectStatementFormat tells the format of a bank statement file from its
+// first bytes, without consuming them. XML files are told apart by the
+// namespace or root element found near their start.
+func detectStatementFormat(file *bufio.Reader) string {
+	start, _ := file.Peek(1024)
+	start = bytes.TrimLeft(bytes.TrimPrefix(start, []byte("\ufeff")), " \t\r\n")
+	switch {
+	case bytes.HasPrefix(start, []byte("{1:")), bytes.HasPrefix(start, []byte(":20:")):
+		return statementFormatMT940
+	case bytes.HasPrefix(start, []byte("01,")):
+		return statementFormatBAI2
+	case bytes.HasPrefix(start, []byte("<")) &&
+		(bytes.Contains(start, []byte("BkToCstmrStmt")) || bytes.Contains(start, []byte("BkToCstmrDbtCdtNtfctn"))):
+		return statementFormatCamt
+	case bytes.HasPrefix(start, []byte("OFXHEADER")),
+		bytes.HasPrefix(start, []byte("<")) && (bytes.Contains(start, []byte("<?OFX")) || bytes.Contains(start, []byte("<OFX>"))):
+		return statementFormatOFX
 	}
+	return statementFormatCSV
+}
 
-	return csvReader, tempFile, nil
+// isParseError tells a malformed row, which is skipped, from a failure to
+// read the file, which would fail every following read too
+func isParseError(err error) bool {
+	var parseErr *csv.ParseError
+	return errors.As(err, &parseErr)
 }
 
 func parseEvent(event []byte) (model.CompilerEvent, error) {
@@ -131,13 +288,17 @@ func parseEvent(event []byte) (model.CompilerEvent, error) {
 	if err != nil {
 		return model.CompilerEvent{}, errors.Wrap(err, "[parseEvent] failed to unmarshal event")
 	}
+	// The event is returned with validation errors so the task can be failed
 	if compilerEvent.BankStatement == "" && compilerEvent.Transaction == "" {
-		return model.CompilerEvent{}, errors.New("[parseEvent] bank statement and transaction is empty")
+		return compilerEvent, errors.New("[parseEvent] bank statement and transaction is empty")
+	}
+	if compilerEvent.TaskID == "" || compilerEvent.BankName == "" {
+		return compilerEvent, errors.New("[parseEvent] taskID and bankName are required")
 	}
 	return compilerEvent, nil
 }
 
-func (fc *FileCompiler) processInternalTransactions(csvReader *csv.Reader) error {
+func (fc *FileCompiler) processInternalTransactions(ctx context.Context, csvReader *csv.Reader, parser *RecordParser, taskID, bankName string) error {
 	var processedCount int
 	var batchSize int = 0
 	var batch []model.Transaction
@@ -148,22 +309,30 @@ func (fc *FileCompiler) processInternalTransactions(csvReader *csv.Reader) error
 			break
 		}
 		if err != nil {
+			if !isParseError(err) {
+				return errors.Wrap(err, "[processInternalTransactions] error reading file")
+			}
 			log.Printf("Error reading CSV record: %v", err)
 			continue
 		}
 
-		transaction, err := ParseTransactionRecord(record)
+		transaction, err := parser.Transaction(record)
 		if err != nil {
 			log.Printf("Error parsing transaction record: %v", err)
 			continue
 		}
+		transaction.TaskID = taskID
+		transaction.BankName = bankName
 
 		// Add to batch
 		batch = append(batch, transaction)
 		batchSize++
 
 		if batchSize >= fc.cfg.App.Compiler.BatchSize {
-			if err := fc.SaveTransactionBatch(batch); err != nil {
+			if err := ctx.Err(); err != nil {
+				return errors.Wrap(err, "[processInternalTransactions] stopped before saving batch")
+			}
+			if err := fc.SaveTransactionBatch(ctx, batch); err != nil {
 				return errors.Wrap(err, "[processInternalTransactions] error saving transaction during batch")
 			}
 			processedCount += batchSize
@@ -173,7 +342,7 @@ func (fc *FileCompiler) processInternalTransactions(csvReader *csv.Reader) error
 	}
 
 	if batchSize > 0 {
-		if err := fc.SaveTransactionBatch(batch); err != nil {
+		if err := fc.SaveTransactionBatch(ctx, batch); err != nil {
 			return errors.Wrap(err, "[processInternalTransactions] error saving transaction batch")
 		}
 		processedCount += batchSize
@@ -183,39 +352,22 @@ func (fc *FileCompiler) processInternalTransactions(csvReader *csv.Reader) error
 	return nil
 }
 
+// ParseTransactionRecord parses a record of a transaction file laid out as
+// model.DefaultTransactionFormat
 func ParseTransactionRecord(record []string) (model.Transaction, error) {
-	if len(record) < 4 {
-		return model.Transaction{}, errors.Wrap(errors.New("invalid record format"), "[parseTransactionRecord] error parsing transaction record")
-	}
-
-	amount, err := strconv.ParseFloat(record[1], 64)
+	parser, err := NewRecordParser(model.DefaultTransactionFormat(), nil)
 	if err != nil {
-		return model.Transaction{}, errors.Wrap(err, "[parseTransactionRecord] error parsing amount")
+		return model.Transaction{}, err
 	}
-
-	txTime, err := time.Parse("2006-01-02T15:04:05Z", record[3])
-	if err != nil {
-		return model.Transaction{}, errors.Wrap(err, "[parseTransactionRecord] error parsing transaction time")
-	}
-	return model.Transaction{
-		ID:              record[0],
-		Amount:          amount,
-		TransactionTime: txTime,
-		Type:            record[2],
-	}, nil
+	return parser.Transaction(record)
 }
 
 // saveTransactionBatch saves a batch of transactions to the database
-func (fc *FileCompiler) SaveTransactionBatch(transactions []model.Transaction) error {
-	for _, tx := range transactions {
-		if err := fc.transactionRepo.Save(tx); err != nil {
-			return err
-		}
-	}
-	return nil
+func (fc *FileCompiler) SaveTransactionBatch(ctx context.Context, transactions []model.Transaction) error {
+	return fc.transactionRepo.SaveBatch(ctx, transactions)
 }
 
-func (fc *FileCompiler) processBankStatement(csvReader *csv.Reader, bankName string) error {
+func (fc *FileCompiler) processBankStatement(ctx context.Context, csvReader *csv.Reader, parser *RecordParser, taskID, bankName string) error {
 	var processedCount int
 	var batchSize int = 0
 	var batch []model.BankStatement
@@ -226,21 +378,29 @@ func (fc *FileCompiler) processBankStatement(csvReader *csv.Reader, bankName str
 			break
 		}
 		if err != nil {
+			if !isParseError(err) {
+				return errors.Wrap(err, "[processBankStatement] error reading file")
+			}
 			log.Printf("Error reading CSV record: %v", err)
 			continue
 		}
 
-		stmt, err := ParseBankStatement(record)
+		stmt, err := parser.BankStatement(record)
 		if err != nil {
 			log.Printf("Error parsing bank statement record: %v", err)
 			continue
 		}
+		stmt.TaskID = taskID
+		stmt.BankName = bankName
 
 		batch = append(batch, stmt)
 		batchSize++
 
-		if batchSize >= 100 {
-			if err := fc.SaveBankStatementBatch(batch); err != nil {
+		if batchSize >= fc.cfg.App.Compiler.BatchSize {
+			if err := ctx.Err(); err != nil {
+				return errors.Wrap(err, "[processBankStatments] stopped before saving batch")
+			}
+			if err := fc.SaveBankStatementBatch(ctx, batch); err != nil {
 				return errors.Wrap(err, "[processBankStatments] error saving transaction inside batch")
 			}
 			processedCount += batchSize
@@ -250,7 +410,7 @@ func (fc *FileCompiler) processBankStatement(csvReader *csv.Reader, bankName str
 	}
 
 	if batchSize > 0 {
-		if err := fc.SaveBankStatementBatch(batch); err != nil {
+		if err := fc.SaveBankStatementBatch(ctx, batch); err != nil {
 			return errors.Wrap(err, "[processBankStatments] error saving transaction batch")
 		}
 		processedCount += batchSize
@@ -260,33 +420,91 @@ func (fc *FileCompiler) processBankStatement(csvReader *csv.Reader, bankName str
 	return nil
 }
 
-func (fc *FileCompiler) SaveBankStatementBatch(statements []model.BankStatement) error {
-	for _, stmt := range statements {
-		if err := fc.bankStmtRepo.Save(stmt); err != nil {
-			return err
+// entryReader streams the entries of a bank statement file, keeping the
+// balances of the statements read
+type entryReader interface {
+	Next() (model.BankStatement, error)
+	Statements() []model.Statement
+}
+
+// processEntries ingests the entries of an MT940, camt, BAI2 or OFX file and
+// keeps the balances of its statements. The file is streamed, so only a batch
+// of entries is held in memory at a time. Unlike CSV rows, a malformed
+// statement fails the file as its balances could no longer be verified.
+func (fc *FileCompiler) processEntries(ctx context.Context, reader entryReader, format, taskID, bankName string) error {
+	var processedCount int
+	var batch []model.BankStatement
+
+	for {
+		entry, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return errors.Wrap(err, "[processEntries] error reading file")
+		}
+
+		entry.TaskID = taskID
+		entry.BankName = bankName
+		batch = append(batch, entry)
+
+		if len(batch) >= fc.cfg.App.Compiler.BatchSize {
+			if err := ctx.Err(); err != nil {
+				return errors.Wrap(err, "[processEntries] stopped before saving batch")
+			}
+			if err := fc.SaveBankStatementBatch(ctx, batch); err != nil {
+				return errors.Wrap(err, "[processEntries] error saving statement entries inside batch")
+			}
+			processedCount += len(batch)
+			batch = nil
 		}
 	}
-	return nil
-}
 
-func ParseBankStatement(record []string) (model.BankStatement, error) {
-	if len(record) < 3 {
-		return model.BankStatement{}, errors.Wrap(errors.New("invalid record format"), "[parseBankStatement] error parsing bank statement record")
+	if len(batch) > 0 {
+		if err := fc.SaveBankStatementBatch(ctx, batch); err != nil {
+			return errors.Wrap(err, "[processEntries] error saving statement entries batch")
+		}
+		processedCount += len(batch)
 	}
 
-	amount, err := strconv.ParseFloat(record[1], 64)
-	if err != nil {
-		return model.BankStatement{}, errors.Wrap(err, "[parseBankStatement] error parsing amount")
+	statements := reader.Statements()
+	for i := range statements {
+		statements[i].TaskID = taskID
+		statements[i].BankName = bankName
+	}
+	if len(statements) > 0 {
+		if err := fc.statementRepo.SaveBalances(ctx, statements); err != nil {
+			return errors.Wrap(err, "[processEntries] error saving statement balances")
+		}
 	}
 
-	date, err := time.Parse("2006-01-02", record[2])
+	log.Printf("Processed %d bank statements from %d %s statements for %s", processedCount, len(statements), format, bankName)
+	return nil
+}
+
+func (fc *FileCompiler) SaveBankStatementBatch(ctx context.Context, statements []model.BankStatement) error {
+	return fc.bankStmtRepo.SaveBatch(ctx, statements)
+}
+
+// ParseBankStatement parses a record of a bank statement file laid out as
+// model.DefaultBankStatementFormat
+func ParseBankStatement(record []string) (model.BankStatement, error) {
+	parser, err := NewRecordParser(model.DefaultBankStatementFormat(), nil)
 	if err != nil {
-		return model.BankStatement{}, errors.Wrap(err, "[parseBankStatement] error parsing date")
+		return model.BankStatement{}, err
 	}
+	return parser.BankStatement(record)
+}
 
-	return model.BankStatement{
-		ID:     record[0],
-		Amount: amount,
-		Date:   date,
-	}, nil
+// parseCurrency validates an ISO 4217 currency code. An empty value is allowed
+// and leaves the currency to the reconciliation's base currency.
+func parseCurrency(value string) (string, error) {
+	currency := strings.ToUpper(strings.TrimSpace(value))
+	if currency == "" {
+		return "", nil
+	}
+	if len(currency) != 3 || strings.Trim(currency, "ABCDEFGHIJKLMNOPQRSTUVWXYZ") != "" {
+		return "", errors.Errorf("invalid currency code %q", value)
+	}
+	return currency, nil
 }
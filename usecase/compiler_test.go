@@ -1,11 +1,16 @@
 package usecase_test
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"testing/iotest"
 	"time"
 
 	"github.com/aferryc/yars/internal/config"
@@ -19,6 +24,9 @@ import (
 const transactionFile = "uploads/test-task-id/transactions.csv"
 const bankStatementFile = "uploads/test-task-id/bank_statement.csv"
 
+const testTraceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+const testTraceParent = "00-" + testTraceID + "-00f067aa0ba902b7-01"
+
 // TestFileCompilerProcessEvent tests the ProcessEvent method of FileCompiler
 func TestFileCompilerProcessEvent(t *testing.T) {
 	// Create a temporary directory for test files
@@ -30,8 +38,12 @@ func TestFileCompilerProcessEvent(t *testing.T) {
 	tests := []struct {
 		name           string
 		event          model.CompilerEvent
+		headers        map[string]string
 		fileContent    string
 		setupMocks     func(*testing.T, *mockFileSetup, string)
+		profile        *model.IngestionProfile
+		currentStatus  model.TaskStatus
+		taskStatuses   []model.TaskStatus
 		expectedError  bool
 		expectedErrMsg string
 	}{
@@ -48,37 +60,322 @@ func TestFileCompilerProcessEvent(t *testing.T) {
 			fileContent: "id,amount,type,timestamp\ntx123,100.50,CREDIT,2023-01-15T14:30:45Z\ntx456,200.75,DEBIT,2023-01-16T10:20:30Z",
 			setupMocks: func(t *testing.T, m *mockFileSetup, filePath string) {
 				// Mock GCS download for both files
-				m.gcsRepo.EXPECT().
-					DownloadFromBucket(gomock.Any(), transactionFile).
-					Return(createTempFileWithContent(t, filePath), nil)
-
-				m.gcsRepo.EXPECT().
-					DownloadFromBucket(gomock.Any(), bankStatementFile).
-					Return(createTempFileWithContent(t, "id,amount,date\n101,500.25,2023-01-15\n102,750.50,2023-01-16"), nil)
-
-				// Expect save calls for each transaction
-				m.txRepo.EXPECT().Save(gomock.Any()).Return(nil).Times(2)
-
-				// Expect save calls for each bank statement
-				m.bankStmtRepo.EXPECT().Save(gomock.Any()).Return(nil).Times(2)
-
-				// Expect Kafka publish call to trigger reconciliation
-				m.kafkaRepo.EXPECT().
-					Publish(
-						gomock.Any(),
-						gomock.Any(),
-						"test-task-id",
-						gomock.Any(),
-					).DoAndReturn(func(ctx any, topic any, key any, event any) error {
-					// Verify the reconciliation event has correct structure
-					reconEvent, ok := event.(model.ReconciliationEvent)
-					assert.True(t, ok)
-					assert.Equal(t, "test-task-id", reconEvent.TaskID)
+				m.storageRepo.EXPECT().
+					NewReader(gomock.Any(), transactionFile).
+					Return(newObjectReader(filePath), nil)
+
+				m.storageRepo.EXPECT().
+					NewReader(gomock.Any(), bankStatementFile).
+					Return(newObjectReader("id,amount,date\n101,500.25,2023-01-15\n102,750.50,2023-01-16"), nil)
+
+				// Expect the transactions saved in one batch, tagged with the task and bank
+				m.txRepo.EXPECT().SaveBatch(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, txs []model.Transaction) error {
+					assert.Len(t, txs, 2)
+					for _, tx := range txs {
+						assert.Equal(t, "test-task-id", tx.TaskID)
+						assert.Equal(t, "TestBank", tx.BankName)
+					}
 					return nil
 				})
+
+				// Expect the bank statements saved in one batch, tagged with the task and bank
+				m.bankStmtRepo.EXPECT().SaveBatch(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, stmts []model.BankStatement) error {
+					assert.Len(t, stmts, 2)
+					for _, stmt := range stmts {
+						assert.Equal(t, "test-task-id", stmt.TaskID)
+						assert.Equal(t, "TestBank", stmt.BankName)
+					}
+					return nil
+				})
+
+				// Expect the reconciliation event to be stored in the outbox
+				m.outboxRepo.EXPECT().
+					Add(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(ctx any, event model.OutboxEvent) error {
+						// Verify the envelope continues the trace of the compiler event
+						assert.Equal(t, "recon-topic", event.Topic)
+						assert.Equal(t, model.EventTypeReconciliationRequested, event.Envelope.EventType)
+						assert.Equal(t, "test-task-id", event.Envelope.TaskID)
+						assert.Equal(t, testTraceID, event.Envelope.TraceParent[3:35])
+
+						// Verify the reconciliation event has correct structure
+						var reconEvent model.ReconciliationEvent
+						assert.NoError(t, json.Unmarshal(event.Payload, &reconEvent))
+						assert.Equal(t, "test-task-id", reconEvent.TaskID)
+						assert.Equal(t, "TestBank", reconEvent.BankName)
+						return nil
+					})
 			},
+			taskStatuses:  []model.TaskStatus{model.TaskCompiling, model.TaskCompiled},
 			expectedError: false,
 		},
+		{
+			name: "Saves rows in batches of the configured size",
+			event: model.CompilerEvent{
+				BankStatement: bankStatementFile,
+				TaskID:        "test-task-id",
+				BankName:      "TestBank",
+			},
+			setupMocks: func(t *testing.T, m *mockFileSetup, filePath string) {
+				content := "id,amount,date\n"
+				for i := 0; i < 25; i++ {
+					content += fmt.Sprintf("bs-%d,100.00,2023-01-15\n", i)
+				}
+				m.storageRepo.EXPECT().
+					NewReader(gomock.Any(), bankStatementFile).
+					Return(newObjectReader(content), nil)
+
+				// Batches of 10, then the remainder
+				var sizes []int
+				m.bankStmtRepo.EXPECT().SaveBatch(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, stmts []model.BankStatement) error {
+					sizes = append(sizes, len(stmts))
+					return nil
+				}).Times(3)
+				m.outboxRepo.EXPECT().Add(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx any, event model.OutboxEvent) error {
+					assert.Equal(t, []int{10, 10, 5}, sizes)
+					return nil
+				})
+			},
+			taskStatuses:  []model.TaskStatus{model.TaskCompiling, model.TaskCompiled},
+			expectedError: false,
+		},
+		{
+			name: "Reads the files with the ingestion profile of the bank",
+			event: model.CompilerEvent{
+				Transaction:   transactionFile,
+				BankStatement: bankStatementFile,
+				TaskID:        "test-task-id",
+				BankName:      "TestBank",
+			},
+			profile: &model.IngestionProfile{
+				BankName: "TestBank",
+				Transactions: &model.CSVFormat{
+					Delimiter: ";",
+					Columns: map[string]model.Column{
+						model.FieldID:              {Name: "Reference"},
+						model.FieldAmount:          {Name: "Amount"},
+						model.FieldIndicator:       {Name: "Dr/Cr"},
+						model.FieldTransactionTime: {Name: "Booked At"},
+					},
+					DateLayouts:        []string{"02.01.2006 15:04"},
+					DecimalSeparator:   ",",
+					ThousandsSeparator: ".",
+					SignConvention:     model.SignConventionIndicator,
+					DebitIndicators:    []string{"DR"},
+				},
+				BankStatements: &model.CSVFormat{
+					Columns: map[string]model.Column{
+						model.FieldID:     model.ColumnIndex(0),
+						model.FieldDate:   model.ColumnIndex(1),
+						model.FieldDebit:  model.ColumnIndex(2),
+						model.FieldCredit: model.ColumnIndex(3),
+					},
+					DateLayouts:    []string{"2006-01-02", "01/02/2006"},
+					SignConvention: model.SignConventionSplit,
+				},
+			},
+			setupMocks: func(t *testing.T, m *mockFileSetup, filePath string) {
+				m.storageRepo.EXPECT().
+					NewReader(gomock.Any(), transactionFile).
+					Return(newObjectReader("\ufeffBooked At;Dr/Cr;Amount;Reference\n"+
+						"15.01.2023 14:30;CR;1.234,50;tx123\n16.01.2023 10:20;dr;200,75;tx456\n"), nil)
+				m.storageRepo.EXPECT().
+					NewReader(gomock.Any(), bankStatementFile).
+					Return(newObjectReader("id,date,debit,credit\n101,2023-01-15,,500.25\n102,01/16/2023,750.50,\n"), nil)
+
+				m.txRepo.EXPECT().SaveBatch(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, txs []model.Transaction) error {
+					assert.Len(t, txs, 2)
+					assert.Equal(t, "tx123", txs[0].ID)
+					assert.Equal(t, money("1234.50"), txs[0].Amount)
+					assert.Equal(t, time.Date(2023, 1, 15, 14, 30, 0, 0, time.UTC), txs[0].TransactionTime)
+					assert.Equal(t, "CREDIT", txs[0].Type)
+					assert.Equal(t, money("200.75"), txs[1].Amount)
+					assert.Equal(t, "DEBIT", txs[1].Type)
+					return nil
+				})
+				m.bankStmtRepo.EXPECT().SaveBatch(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, stmts []model.BankStatement) error {
+					assert.Len(t, stmts, 2)
+					assert.Equal(t, money("500.25"), stmts[0].Amount)
+					assert.Equal(t, money("-750.50"), stmts[1].Amount)
+					assert.Equal(t, time.Date(2023, 1, 16, 0, 0, 0, 0, time.UTC), stmts[1].Date)
+					return nil
+				})
+				m.outboxRepo.EXPECT().Add(gomock.Any(), gomock.Any()).Return(nil)
+			},
+			taskStatuses:  []model.TaskStatus{model.TaskCompiling, model.TaskCompiled},
+			expectedError: false,
+		},
+		{
+			name: "Reads MT940 bank statements and keeps their balances",
+			event: model.CompilerEvent{
+				BankStatement: bankStatementFile,
+				TaskID:        "test-task-id",
+				BankName:      "TestBank",
+			},
+			setupMocks: func(t *testing.T, m *mockFileSetup, filePath string) {
+				m.storageRepo.EXPECT().
+					NewReader(gomock.Any(), bankStatementFile).
+					Return(newObjectReader("{1:F01BANKDEFFAXXX0000000000}{2:O9401200230115BANKDEFFAXXX00000000002301151200N}{4:\r\n"+
+						":20:STMT-1\r\n:25:DE89370400440532013000\r\n:28C:1/1\r\n:60F:C230114EUR1000,00\r\n"+
+						":61:2301150115C500,25NTRFTX123//BANKREF1\r\n:86:PAYMENT TX123\r\n"+
+						":61:2301160116D750,50NTRFNONREF//BANKREF2\r\n:62F:C230116EUR749,75\r\n-}"), nil)
+
+				m.bankStmtRepo.EXPECT().SaveBatch(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, stmts []model.BankStatement) error {
+					assert.Len(t, stmts, 2)
+					assert.Equal(t, "STMT-1/1", stmts[0].ID)
+					assert.Equal(t, model.MustParseMoney("500.25", "EUR"), stmts[0].Amount)
+					assert.Equal(t, "TX123", stmts[0].Reference)
+					assert.Equal(t, "PAYMENT TX123", stmts[0].Narrative)
+					assert.Equal(t, "test-task-id", stmts[0].TaskID)
+					assert.Equal(t, model.MustParseMoney("-750.50", "EUR"), stmts[1].Amount)
+					assert.Equal(t, "BANKREF2", stmts[1].Reference)
+					return nil
+				})
+				m.statementRepo.EXPECT().SaveBalances(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, statements []model.Statement) error {
+					assert.Len(t, statements, 1)
+					assert.Equal(t, "DE89370400440532013000", statements[0].Account)
+					assert.Equal(t, model.MustParseMoney("1000.00", "EUR"), statements[0].OpeningBalance.Amount)
+					assert.Equal(t, model.MustParseMoney("749.75", "EUR"), statements[0].ClosingBalance.Amount)
+					assert.Equal(t, "TestBank", statements[0].BankName)
+					return nil
+				})
+				m.outboxRepo.EXPECT().Add(gomock.Any(), gomock.Any()).Return(nil)
+			},
+			taskStatuses:  []model.TaskStatus{model.TaskCompiling, model.TaskCompiled},
+			expectedError: false,
+		},
+		{
+			name: "Reads camt.053 bank statements and keeps their balances",
+			event: model.CompilerEvent{
+				BankStatement: bankStatementFile,
+				TaskID:        "test-task-id",
+				BankName:      "TestBank",
+			},
+			setupMocks: func(t *testing.T, m *mockFileSetup, filePath string) {
+				m.storageRepo.EXPECT().
+					NewReader(gomock.Any(), bankStatementFile).
+					Return(newObjectReader(`<?xml version="1.0" encoding="UTF-8"?>
+<Document xmlns="urn:iso:std:iso:20022:tech:xsd:camt.053.001.02"><BkToCstmrStmt><Stmt>
+<Id>STMT-1</Id><Acct><Id><IBAN>DE89370400440532013000</IBAN></Id></Acct>
+<Bal><Tp><CdOrPrtry><Cd>OPBD</Cd></CdOrPrtry></Tp><Amt Ccy="EUR">1000.00</Amt><CdtDbtInd>CRDT</CdtDbtInd><Dt><Dt>2023-01-14</Dt></Dt></Bal>
+<Bal><Tp><CdOrPrtry><Cd>CLBD</Cd></CdOrPrtry></Tp><Amt Ccy="EUR">1500.25</Amt><CdtDbtInd>CRDT</CdtDbtInd><Dt><Dt>2023-01-15</Dt></Dt></Bal>
+<Ntry><Amt Ccy="EUR">500.25</Amt><CdtDbtInd>CRDT</CdtDbtInd><BookgDt><Dt>2023-01-15</Dt></BookgDt>
+<NtryDtls><TxDtls><Refs><EndToEndId>TX123</EndToEndId></Refs><RltdPties><Dbtr><Nm>ACME CORP</Nm></Dbtr></RltdPties></TxDtls></NtryDtls></Ntry>
+</Stmt></BkToCstmrStmt></Document>`), nil)
+
+				m.bankStmtRepo.EXPECT().SaveBatch(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, stmts []model.BankStatement) error {
+					assert.Len(t, stmts, 1)
+					assert.Equal(t, "STMT-1/1", stmts[0].ID)
+					assert.Equal(t, model.MustParseMoney("500.25", "EUR"), stmts[0].Amount)
+					assert.Equal(t, "TX123", stmts[0].EndToEndID)
+					assert.Equal(t, "ACME CORP", stmts[0].Counterparty)
+					assert.Equal(t, "test-task-id", stmts[0].TaskID)
+					return nil
+				})
+				m.statementRepo.EXPECT().SaveBalances(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, statements []model.Statement) error {
+					assert.Len(t, statements, 1)
+					assert.Equal(t, "DE89370400440532013000", statements[0].Account)
+					assert.Equal(t, model.MustParseMoney("1500.25", "EUR"), statements[0].ClosingBalance.Amount)
+					assert.Equal(t, "TestBank", statements[0].BankName)
+					return nil
+				})
+				m.outboxRepo.EXPECT().Add(gomock.Any(), gomock.Any()).Return(nil)
+			},
+			taskStatuses:  []model.TaskStatus{model.TaskCompiling, model.TaskCompiled},
+			expectedError: false,
+		},
+		{
+			name: "Reads BAI2 bank statements and keeps their balances",
+			event: model.CompilerEvent{
+				BankStatement: bankStatementFile,
+				TaskID:        "test-task-id",
+				BankName:      "TestBank",
+			},
+			setupMocks: func(t *testing.T, m *mockFileSetup, filePath string) {
+				m.storageRepo.EXPECT().
+					NewReader(gomock.Any(), bankStatementFile).
+					Return(newObjectReader("01,BANKUS33,CUSTOMER,230115,0200,FILE-1,80,,2/\n"+
+						"02,CUSTOMER,BANKUS33,1,230114,,USD,2/\n"+
+						"03,0975312468,USD,010,100000,,,015,150025,,/\n"+
+						"16,195,50025,0,BANKREF1,TX123,WIRE FROM ACME CORP/\n"+
+						"49,300050,3/\n98,300050,1,5/\n99,300050,1,7/\n"), nil)
+
+				m.bankStmtRepo.EXPECT().SaveBatch(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, stmts []model.BankStatement) error {
+					assert.Len(t, stmts, 1)
+					assert.Equal(t, "FILE-1/1", stmts[0].ID)
+					assert.Equal(t, model.MustParseMoney("500.25", "USD"), stmts[0].Amount)
+					assert.Equal(t, "TX123", stmts[0].Reference)
+					assert.Equal(t, "test-task-id", stmts[0].TaskID)
+					return nil
+				})
+				m.statementRepo.EXPECT().SaveBalances(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, statements []model.Statement) error {
+					assert.Len(t, statements, 1)
+					assert.Equal(t, "0975312468", statements[0].Account)
+					assert.Equal(t, model.MustParseMoney("1500.25", "USD"), statements[0].ClosingBalance.Amount)
+					assert.Equal(t, "TestBank", statements[0].BankName)
+					return nil
+				})
+				m.outboxRepo.EXPECT().Add(gomock.Any(), gomock.Any()).Return(nil)
+			},
+			taskStatuses:  []model.TaskStatus{model.TaskCompiling, model.TaskCompiled},
+			expectedError: false,
+		},
+		{
+			name: "Reads OFX bank statements",
+			event: model.CompilerEvent{
+				BankStatement: bankStatementFile,
+				TaskID:        "test-task-id",
+				BankName:      "TestBank",
+			},
+			setupMocks: func(t *testing.T, m *mockFileSetup, filePath string) {
+				m.storageRepo.EXPECT().
+					NewReader(gomock.Any(), bankStatementFile).
+					Return(newObjectReader("OFXHEADER:100\r\nDATA:OFXSGML\r\nVERSION:102\r\n\r\n"+
+						"<OFX><BANKMSGSRSV1><STMTTRNRS><STMTRS><CURDEF>USD<BANKACCTFROM><ACCTID>0975312468</BANKACCTFROM>"+
+						"<BANKTRANLIST><STMTTRN><TRNTYPE>CREDIT<DTPOSTED>20230115<TRNAMT>500.25<FITID>TX123<NAME>ACME CORP</STMTTRN>"+
+						"</BANKTRANLIST></STMTRS></STMTTRNRS></BANKMSGSRSV1></OFX>"), nil)
+
+				m.bankStmtRepo.EXPECT().SaveBatch(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, stmts []model.BankStatement) error {
+					assert.Len(t, stmts, 1)
+					assert.Equal(t, "0975312468/TX123", stmts[0].ID)
+					assert.Equal(t, model.MustParseMoney("500.25", "USD"), stmts[0].Amount)
+					assert.Equal(t, "TX123", stmts[0].Reference)
+					assert.Equal(t, "ACME CORP", stmts[0].Counterparty)
+					assert.Equal(t, "test-task-id", stmts[0].TaskID)
+					return nil
+				})
+				m.outboxRepo.EXPECT().Add(gomock.Any(), gomock.Any()).Return(nil)
+			},
+			taskStatuses:  []model.TaskStatus{model.TaskCompiling, model.TaskCompiled},
+			expectedError: false,
+		},
+		{
+			name: "Column of the ingestion profile missing from the header",
+			event: model.CompilerEvent{
+				Transaction: transactionFile,
+				TaskID:      "test-task-id",
+				BankName:    "TestBank",
+			},
+			profile: &model.IngestionProfile{
+				BankName: "TestBank",
+				Transactions: &model.CSVFormat{
+					Columns: map[string]model.Column{
+						model.FieldID:              {Name: "Reference"},
+						model.FieldAmount:          {Name: "Amount"},
+						model.FieldTransactionTime: {Name: "Booked At"},
+					},
+					DateLayouts: []string{time.RFC3339},
+				},
+			},
+			setupMocks: func(t *testing.T, m *mockFileSetup, filePath string) {
+				m.storageRepo.EXPECT().
+					NewReader(gomock.Any(), transactionFile).
+					Return(newObjectReader("id,amount,type,timestamp\ntx123,100.50,CREDIT,2023-01-15T14:30:45Z"), nil)
+			},
+			taskStatuses:   []model.TaskStatus{model.TaskCompiling, model.TaskFailed},
+			expectedError:  true,
+			expectedErrMsg: "not found in header",
+		},
 		{
 			name: "Error downloading transaction file",
 			event: model.CompilerEvent{
@@ -89,13 +386,35 @@ func TestFileCompilerProcessEvent(t *testing.T) {
 			},
 			setupMocks: func(t *testing.T, m *mockFileSetup, filePath string) {
 				// Mock download failure
-				m.gcsRepo.EXPECT().
-					DownloadFromBucket(gomock.Any(), transactionFile).
+				m.storageRepo.EXPECT().
+					NewReader(gomock.Any(), transactionFile).
 					Return(nil, errors.New("download failed"))
 			},
+			taskStatuses:   []model.TaskStatus{model.TaskCompiling, model.TaskFailed},
 			expectedError:  true,
 			expectedErrMsg: "error starting file streamer Bank File",
 		},
+		{
+			name: "Error reading transaction file",
+			event: model.CompilerEvent{
+				Transaction:   transactionFile,
+				BankStatement: bankStatementFile,
+				TaskID:        "test-task-id",
+				BankName:      "TestBank",
+			},
+			setupMocks: func(t *testing.T, m *mockFileSetup, filePath string) {
+				// The connection drops after the header, nothing is saved
+				m.storageRepo.EXPECT().
+					NewReader(gomock.Any(), transactionFile).
+					Return(io.NopCloser(io.MultiReader(
+						strings.NewReader("id,amount,type,timestamp\n"),
+						iotest.ErrReader(errors.New("connection reset")),
+					)), nil)
+			},
+			taskStatuses:   []model.TaskStatus{model.TaskCompiling, model.TaskFailed},
+			expectedError:  true,
+			expectedErrMsg: "error reading file",
+		},
 		{
 			name: "Error downloading bank statement file",
 			event: model.CompilerEvent{
@@ -107,18 +426,19 @@ func TestFileCompilerProcessEvent(t *testing.T) {
 			fileContent: "id,amount,type,timestamp\ntx123,100.50,CREDIT,2023-01-15T14:30:45Z",
 			setupMocks: func(t *testing.T, m *mockFileSetup, filePath string) {
 				// Mock successful transaction file download
-				m.gcsRepo.EXPECT().
-					DownloadFromBucket(gomock.Any(), transactionFile).
-					Return(createTempFileWithContent(t, filePath), nil)
+				m.storageRepo.EXPECT().
+					NewReader(gomock.Any(), transactionFile).
+					Return(newObjectReader(filePath), nil)
 
-				// Expect save calls for each transaction to succeed
-				m.txRepo.EXPECT().Save(gomock.Any()).Return(nil).AnyTimes()
+				// Expect the transactions to be saved
+				m.txRepo.EXPECT().SaveBatch(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
 
 				// Mock bank statement download failure
-				m.gcsRepo.EXPECT().
-					DownloadFromBucket(gomock.Any(), bankStatementFile).
+				m.storageRepo.EXPECT().
+					NewReader(gomock.Any(), bankStatementFile).
 					Return(nil, errors.New("download failed"))
 			},
+			taskStatuses:   []model.TaskStatus{model.TaskCompiling, model.TaskFailed},
 			expectedError:  true,
 			expectedErrMsg: "error starting file streamer Bank File",
 		},
@@ -133,13 +453,14 @@ func TestFileCompilerProcessEvent(t *testing.T) {
 			fileContent: "id,amount,type,timestamp\ntx123,100.50,CREDIT,2023-01-15T14:30:45Z",
 			setupMocks: func(t *testing.T, m *mockFileSetup, filePath string) {
 				// Mock GCS download
-				m.gcsRepo.EXPECT().
-					DownloadFromBucket(gomock.Any(), transactionFile).
-					Return(createTempFileWithContent(t, filePath), nil)
+				m.storageRepo.EXPECT().
+					NewReader(gomock.Any(), transactionFile).
+					Return(newObjectReader(filePath), nil)
 
 				// Mock save error
-				m.txRepo.EXPECT().Save(gomock.Any()).Return(errors.New("database error"))
+				m.txRepo.EXPECT().SaveBatch(gomock.Any(), gomock.Any()).Return(errors.New("database error"))
 			},
+			taskStatuses:   []model.TaskStatus{model.TaskCompiling, model.TaskFailed},
 			expectedError:  true,
 			expectedErrMsg: "error processing internal file",
 		},
@@ -154,24 +475,45 @@ func TestFileCompilerProcessEvent(t *testing.T) {
 			fileContent: "id,amount,type,timestamp\ntx123,100.50,CREDIT,2023-01-15T14:30:45Z",
 			setupMocks: func(t *testing.T, m *mockFileSetup, filePath string) {
 				// Mock successful transaction processing
-				m.gcsRepo.EXPECT().
-					DownloadFromBucket(gomock.Any(), transactionFile).
-					Return(createTempFileWithContent(t, filePath), nil)
-				m.txRepo.EXPECT().Save(gomock.Any()).Return(nil).AnyTimes()
+				m.storageRepo.EXPECT().
+					NewReader(gomock.Any(), transactionFile).
+					Return(newObjectReader(filePath), nil)
+				m.txRepo.EXPECT().SaveBatch(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
 
 				// Mock bank file download
-				m.gcsRepo.EXPECT().
-					DownloadFromBucket(gomock.Any(), bankStatementFile).
-					Return(createTempFileWithContent(t, "id,amount,date\n101,500.25,2023-01-15"), nil)
+				m.storageRepo.EXPECT().
+					NewReader(gomock.Any(), bankStatementFile).
+					Return(newObjectReader("id,amount,date\n101,500.25,2023-01-15"), nil)
 
 				// Mock save error for bank statement
-				m.bankStmtRepo.EXPECT().Save(gomock.Any()).Return(errors.New("database issue"))
+				m.bankStmtRepo.EXPECT().SaveBatch(gomock.Any(), gomock.Any()).Return(errors.New("database issue"))
 			},
+			taskStatuses:   []model.TaskStatus{model.TaskCompiling, model.TaskFailed},
 			expectedError:  true,
 			expectedErrMsg: "error processing internal file",
 		},
 		{
-			name: "Kafka publish error",
+			name: "Error saving MT940 bank statement",
+			event: model.CompilerEvent{
+				BankStatement: bankStatementFile,
+				TaskID:        "test-task-id",
+				BankName:      "TestBank",
+			},
+			setupMocks: func(t *testing.T, m *mockFileSetup, filePath string) {
+				m.storageRepo.EXPECT().
+					NewReader(gomock.Any(), bankStatementFile).
+					Return(newObjectReader("{1:F01BANKDEFFAXXX0000000000}{2:O9401200230115BANKDEFFAXXX00000000002301151200N}{4:\r\n"+
+						":20:STMT-1\r\n:25:DE89370400440532013000\r\n:28C:1/1\r\n:60F:C230114EUR1000,00\r\n"+
+						":61:2301150115C500,25NTRFTX123//BANKREF1\r\n:62F:C230115EUR1500,25\r\n-}"), nil)
+
+				m.bankStmtRepo.EXPECT().SaveBatch(gomock.Any(), gomock.Any()).Return(errors.New("database issue"))
+			},
+			taskStatuses:   []model.TaskStatus{model.TaskCompiling, model.TaskFailed},
+			expectedError:  true,
+			expectedErrMsg: "error processing mt940 bank statement file",
+		},
+		{
+			name: "Error storing reconciliation event",
 			event: model.CompilerEvent{
 				Transaction:   transactionFile,
 				BankStatement: bankStatementFile,
@@ -181,24 +523,103 @@ func TestFileCompilerProcessEvent(t *testing.T) {
 			fileContent: "id,amount,type,timestamp\ntx123,100.50,CREDIT,2023-01-15T14:30:45Z",
 			setupMocks: func(t *testing.T, m *mockFileSetup, filePath string) {
 				// Mock successful transaction processing
-				m.gcsRepo.EXPECT().
-					DownloadFromBucket(gomock.Any(), transactionFile).
-					Return(createTempFileWithContent(t, filePath), nil)
-				m.txRepo.EXPECT().Save(gomock.Any()).Return(nil).AnyTimes()
+				m.storageRepo.EXPECT().
+					NewReader(gomock.Any(), transactionFile).
+					Return(newObjectReader(filePath), nil)
+				m.txRepo.EXPECT().SaveBatch(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
 
 				// Mock successful bank statement processing
-				m.gcsRepo.EXPECT().
-					DownloadFromBucket(gomock.Any(), bankStatementFile).
-					Return(createTempFileWithContent(t, "id,amount,date\n101,500.25,2023-01-15"), nil)
-				m.bankStmtRepo.EXPECT().Save(gomock.Any()).Return(nil).AnyTimes()
+				m.storageRepo.EXPECT().
+					NewReader(gomock.Any(), bankStatementFile).
+					Return(newObjectReader("id,amount,date\n101,500.25,2023-01-15"), nil)
+				m.bankStmtRepo.EXPECT().SaveBatch(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
 
-				// Mock Kafka publish error
-				m.kafkaRepo.EXPECT().
-					Publish(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
-					Return(errors.New("kafka publish error"))
+				// Mock outbox error, rolling back the compiled status
+				m.outboxRepo.EXPECT().
+					Add(gomock.Any(), gomock.Any()).
+					Return(errors.New("database error"))
+			},
+			taskStatuses:   []model.TaskStatus{model.TaskCompiling, model.TaskCompiled, model.TaskFailed},
+			expectedError:  true,
+			expectedErrMsg: "error storing reconciliation event",
+		},
+		{
+			name: "Already compiled task is skipped",
+			event: model.CompilerEvent{
+				Transaction:   transactionFile,
+				BankStatement: bankStatementFile,
+				TaskID:        "test-task-id",
+				BankName:      "TestBank",
+			},
+			setupMocks: func(t *testing.T, m *mockFileSetup, filePath string) {
+				// No file is ingested or event stored again
+			},
+			currentStatus: model.TaskCompiled,
+			expectedError: false,
+		},
+		{
+			name: "Already reconciled task is skipped",
+			event: model.CompilerEvent{
+				Transaction:   transactionFile,
+				BankStatement: bankStatementFile,
+				TaskID:        "test-task-id",
+				BankName:      "TestBank",
+			},
+			setupMocks: func(t *testing.T, m *mockFileSetup, filePath string) {
+				// No repo calls expected
+			},
+			currentStatus: model.TaskCompleted,
+			expectedError: false,
+		},
+		{
+			name: "Event published before the envelope is upcast",
+			event: model.CompilerEvent{
+				Transaction: transactionFile,
+				TaskID:      "test-task-id",
+				BankName:    "TestBank",
 			},
+			headers:     map[string]string{},
+			fileContent: "id,amount,type,timestamp\ntx123,100.50,CREDIT,2023-01-15T14:30:45Z",
+			setupMocks: func(t *testing.T, m *mockFileSetup, filePath string) {
+				m.storageRepo.EXPECT().
+					NewReader(gomock.Any(), transactionFile).
+					Return(newObjectReader(filePath), nil)
+				m.txRepo.EXPECT().SaveBatch(gomock.Any(), gomock.Any()).Return(nil)
+				m.outboxRepo.EXPECT().Add(gomock.Any(), gomock.Any()).Return(nil)
+			},
+			taskStatuses:  []model.TaskStatus{model.TaskCompiling, model.TaskCompiled},
+			expectedError: false,
+		},
+		{
+			name: "Event of another type is skipped",
+			event: model.CompilerEvent{
+				TaskID:   "test-task-id",
+				BankName: "TestBank",
+			},
+			headers: model.NewEventEnvelope(context.Background(), model.EventTypeReconciliationRequested, "test-task-id").Headers(),
+			setupMocks: func(t *testing.T, m *mockFileSetup, filePath string) {
+				// No repo calls expected
+			},
+			expectedError: false,
+		},
+		{
+			name: "Unsupported schema version",
+			event: model.CompilerEvent{
+				Transaction: transactionFile,
+				TaskID:      "test-task-id",
+				BankName:    "TestBank",
+			},
+			headers: map[string]string{
+				model.HeaderEventType:     model.EventTypeCompilationRequested,
+				model.HeaderSchemaVersion: "2",
+				model.HeaderTaskID:        "test-task-id",
+			},
+			setupMocks: func(t *testing.T, m *mockFileSetup, filePath string) {
+				// No repo calls expected
+			},
+			taskStatuses:   []model.TaskStatus{model.TaskFailed},
 			expectedError:  true,
-			expectedErrMsg: "error publishing event to Kafka",
+			expectedErrMsg: "unsupported event schema version",
 		},
 		{
 			name: "Invalid event - missing fields",
@@ -210,9 +631,24 @@ func TestFileCompilerProcessEvent(t *testing.T) {
 			setupMocks: func(t *testing.T, m *mockFileSetup, filePath string) {
 				// No repo calls expected
 			},
+			taskStatuses:   []model.TaskStatus{model.TaskFailed},
 			expectedError:  true,
 			expectedErrMsg: "bank statement and transaction is empty",
 		},
+		{
+			name: "Invalid event - missing bank",
+			event: model.CompilerEvent{
+				Transaction:   transactionFile,
+				BankStatement: bankStatementFile,
+				TaskID:        "test-task-id",
+			},
+			setupMocks: func(t *testing.T, m *mockFileSetup, filePath string) {
+				// No repo calls expected
+			},
+			taskStatuses:   []model.TaskStatus{model.TaskFailed},
+			expectedError:  true,
+			expectedErrMsg: "taskID and bankName are required",
+		},
 	}
 
 	for _, tt := range tests {
@@ -222,8 +658,11 @@ func TestFileCompilerProcessEvent(t *testing.T) {
 			defer mockCtrl.Finish()
 			mockBankStmtRepo := repositorymock.NewMockBankStatementRepository(mockCtrl)
 			mockTxRepo := repositorymock.NewMockInternalTransactionRepository(mockCtrl)
-			mockGCSRepo := repositorymock.NewMockGCSRepository(mockCtrl)
-			mockKafkaRepo := repositorymock.NewMockKafkaRepository(mockCtrl)
+			mockStorageRepo := repositorymock.NewMockStorageRepository(mockCtrl)
+			mockOutboxRepo := repositorymock.NewMockOutboxRepository(mockCtrl)
+			mockTaskRepo := repositorymock.NewMockReconTaskRepository(mockCtrl)
+			mockProfileRepo := repositorymock.NewMockIngestionProfileRepository(mockCtrl)
+			mockStatementRepo := repositorymock.NewMockStatementRepository(mockCtrl)
 
 			// Create temp file with test content
 			var tempFilePath string
@@ -235,34 +674,73 @@ func TestFileCompilerProcessEvent(t *testing.T) {
 
 			// Setup mocks
 			mockSetup := &mockFileSetup{
-				bankStmtRepo: mockBankStmtRepo,
-				txRepo:       mockTxRepo,
-				gcsRepo:      mockGCSRepo,
-				kafkaRepo:    mockKafkaRepo,
+				bankStmtRepo:  mockBankStmtRepo,
+				txRepo:        mockTxRepo,
+				storageRepo:   mockStorageRepo,
+				outboxRepo:    mockOutboxRepo,
+				statementRepo: mockStatementRepo,
 			}
 
 			// Pass testing.T to setupMocks for better assertions
 			tt.setupMocks(t, mockSetup, tt.fileContent)
 
+			// The task is uploaded unless the case redelivers an event
+			currentStatus := tt.currentStatus
+			if currentStatus == "" {
+				currentStatus = model.TaskUploaded
+			}
+			mockTaskRepo.EXPECT().
+				Get(gomock.Any(), "test-task-id").
+				Return(model.ReconTask{TaskID: "test-task-id", Status: currentStatus}, nil).
+				AnyTimes()
+
+			// Banks without a profile are read in the default formats
+			if tt.profile != nil {
+				mockProfileRepo.EXPECT().Get(gomock.Any(), tt.event.BankName).Return(*tt.profile, nil).AnyTimes()
+			} else {
+				mockProfileRepo.EXPECT().Get(gomock.Any(), gomock.Any()).
+					Return(model.IngestionProfile{}, model.ErrIngestionProfileNotFound).AnyTimes()
+			}
+
+			// Expect the task to move through the given statuses in order
+			var statusCalls []any
+			for _, status := range tt.taskStatuses {
+				statusCalls = append(statusCalls, mockTaskRepo.EXPECT().
+					UpdateStatus(gomock.Any(), "test-task-id", status, gomock.Any()).
+					Return(nil))
+			}
+			gomock.InOrder(statusCalls...)
+
 			// Create compiler with proper configuration for batch sizes
 			compiler := usecase.NewFileCompiler(
 				&config.Config{
 					Bucket: config.BucketConfig{Name: "test-bucket"},
 					App:    config.AppConfig{Compiler: config.CompilerConfig{BatchSize: 10}},
-					Kafka:  config.KafkaConfig{Topic: config.TopicConfig{CompilerTopic: "test-topic"}},
+					Kafka:  config.KafkaConfig{Topic: config.TopicConfig{CompilerTopic: "test-topic", ReconTopic: "recon-topic"}},
 				},
-				mockGCSRepo,
+				mockStorageRepo,
 				mockBankStmtRepo,
 				mockTxRepo,
-				mockKafkaRepo,
+				newTransactor(mockCtrl),
+				mockOutboxRepo,
+				mockTaskRepo,
+				mockProfileRepo,
+				mockStatementRepo,
 			)
 
 			// Create event JSON
 			eventBytes, err := json.Marshal(tt.event)
 			assert.NoError(t, err)
 
+			// Envelope the event as published unless the case sets the headers
+			headers := tt.headers
+			if headers == nil {
+				ctx := model.ContextWithTraceParent(context.Background(), testTraceParent)
+				headers = model.NewEventEnvelope(ctx, model.EventTypeCompilationRequested, tt.event.TaskID).Headers()
+			}
+
 			// Process the event
-			err = compiler.ProcessEvent(eventBytes)
+			err = compiler.ProcessEvent(context.Background(), model.Message{Value: eventBytes, Headers: headers})
 
 			// Check expectations
 			if tt.expectedError {
@@ -277,30 +755,18 @@ func TestFileCompilerProcessEvent(t *testing.T) {
 	}
 }
 
-// Helper to create a temp file with content
-func createTempFileWithContent(t *testing.T, content string) *os.File {
-	tempFile, err := os.CreateTemp("", "testfile-*.csv")
-	if err != nil {
-		t.Fatalf("Failed to create temp file: %v", err)
-	}
-
-	if _, err := tempFile.WriteString(content); err != nil {
-		t.Fatalf("Failed to write to temp file: %v", err)
-	}
-
-	if _, err := tempFile.Seek(0, 0); err != nil {
-		t.Fatalf("Failed to seek in temp file: %v", err)
-	}
-
-	return tempFile
+// newObjectReader streams content like a stored object
+func newObjectReader(content string) io.ReadCloser {
+	return io.NopCloser(strings.NewReader(content))
 }
 
 // mockFileSetup is a helper for test setup
 type mockFileSetup struct {
-	bankStmtRepo *repositorymock.MockBankStatementRepository
-	txRepo       *repositorymock.MockInternalTransactionRepository
-	gcsRepo      *repositorymock.MockGCSRepository
-	kafkaRepo    *repositorymock.MockKafkaRepository
+	bankStmtRepo  *repositorymock.MockBankStatementRepository
+	txRepo        *repositorymock.MockInternalTransactionRepository
+	storageRepo   *repositorymock.MockStorageRepository
+	outboxRepo    *repositorymock.MockOutboxRepository
+	statementRepo *repositorymock.MockStatementRepository
 }
 
 // TestParseTransactionRecord tests the parseTransactionRecord function
@@ -316,7 +782,7 @@ func TestParseTransactionRecord(t *testing.T) {
 			record: []string{"tx123", "100.50", "CREDIT", "2023-01-15T14:30:45Z"},
 			expected: model.Transaction{
 				ID:              "tx123",
-				Amount:          100.50,
+				Amount:          money("100.50"),
 				Type:            "CREDIT",
 				TransactionTime: time.Date(2023, 1, 15, 14, 30, 45, 0, time.UTC),
 			},
@@ -332,6 +798,32 @@ func TestParseTransactionRecord(t *testing.T) {
 			record:        []string{"tx123", "invalid", "CREDIT", "2023-01-15T14:30:45Z"},
 			expectedError: true,
 		},
+		{
+			name:          "Amount with sub-cent precision",
+			record:        []string{"tx123", "100.505", "CREDIT", "2023-01-15T14:30:45Z"},
+			expectedError: true,
+		},
+		{
+			name:   "Transaction record with currency",
+			record: []string{"tx124", "1500", "DEBIT", "2023-01-15T14:30:45Z", " jpy "},
+			expected: model.Transaction{
+				ID:              "tx124",
+				Amount:          model.MustParseMoney("1500", "JPY"),
+				Type:            "DEBIT",
+				TransactionTime: time.Date(2023, 1, 15, 14, 30, 45, 0, time.UTC),
+			},
+			expectedError: false,
+		},
+		{
+			name:          "Fraction on zero decimal currency",
+			record:        []string{"tx124", "1500.50", "DEBIT", "2023-01-15T14:30:45Z", "JPY"},
+			expectedError: true,
+		},
+		{
+			name:          "Invalid currency",
+			record:        []string{"tx124", "100.50", "DEBIT", "2023-01-15T14:30:45Z", "US$"},
+			expectedError: true,
+		},
 		{
 			name:          "Invalid timestamp",
 			record:        []string{"tx123", "100.50", "CREDIT", "invalid-time"},
@@ -363,42 +855,47 @@ func TestSaveTransactionBatch(t *testing.T) {
 	defer mockCtrl.Finish()
 
 	mockTxRepo := repositorymock.NewMockInternalTransactionRepository(mockCtrl)
-	mockKafkaRepo := repositorymock.NewMockKafkaRepository(mockCtrl)
 
 	transactions := []model.Transaction{
-		{ID: "tx1", Amount: 100.0, Type: "CREDIT"},
-		{ID: "tx2", Amount: 200.0, Type: "DEBIT"},
+		{ID: "tx1", Amount: money("100.00"), Type: "CREDIT"},
+		{ID: "tx2", Amount: money("200.00"), Type: "DEBIT"},
 	}
 
 	t.Run("Save batch successfully", func(t *testing.T) {
-		mockTxRepo.EXPECT().Save(transactions[0]).Return(nil)
-		mockTxRepo.EXPECT().Save(transactions[1]).Return(nil)
+		mockTxRepo.EXPECT().SaveBatch(gomock.Any(), transactions).Return(nil)
 
 		compiler := usecase.NewFileCompiler(
 			&config.Config{},
 			nil,
 			nil,
 			mockTxRepo,
-			mockKafkaRepo,
+			nil,
+			nil,
+			nil,
+			nil,
+			nil,
 		)
 
-		err := compiler.SaveTransactionBatch(transactions)
+		err := compiler.SaveTransactionBatch(context.Background(), transactions)
 		assert.NoError(t, err)
 	})
 
 	t.Run("Error saving transaction", func(t *testing.T) {
-		mockTxRepo.EXPECT().Save(transactions[0]).Return(nil)
-		mockTxRepo.EXPECT().Save(transactions[1]).Return(errors.New("database error"))
+		mockTxRepo.EXPECT().SaveBatch(gomock.Any(), transactions).Return(errors.New("database error"))
 
 		compiler := usecase.NewFileCompiler(
 			&config.Config{},
 			nil,
 			nil,
 			mockTxRepo,
-			mockKafkaRepo,
+			nil,
+			nil,
+			nil,
+			nil,
+			nil,
 		)
 
-		err := compiler.SaveTransactionBatch(transactions)
+		err := compiler.SaveTransactionBatch(context.Background(), transactions)
 		assert.Error(t, err)
 		assert.Contains(t, err.Error(), "database error")
 	})
@@ -410,43 +907,48 @@ func TestSaveBankStatementBatch(t *testing.T) {
 	defer mockCtrl.Finish()
 
 	mockBankStmtRepo := repositorymock.NewMockBankStatementRepository(mockCtrl)
-	mockKafkaRepo := repositorymock.NewMockKafkaRepository(mockCtrl)
 
 	// Updated to use string IDs instead of integers
 	statements := []model.BankStatement{
-		{ID: "bs-1", Amount: 100.0, Date: time.Now()},
-		{ID: "bs-2", Amount: 200.0, Date: time.Now()},
+		{ID: "bs-1", Amount: money("100.00"), Date: time.Now()},
+		{ID: "bs-2", Amount: money("200.00"), Date: time.Now()},
 	}
 
 	t.Run("Save batch successfully", func(t *testing.T) {
-		mockBankStmtRepo.EXPECT().Save(statements[0]).Return(nil)
-		mockBankStmtRepo.EXPECT().Save(statements[1]).Return(nil)
+		mockBankStmtRepo.EXPECT().SaveBatch(gomock.Any(), statements).Return(nil)
 
 		compiler := usecase.NewFileCompiler(
 			&config.Config{},
 			nil,
 			mockBankStmtRepo,
 			nil,
-			mockKafkaRepo,
+			nil,
+			nil,
+			nil,
+			nil,
+			nil,
 		)
 
-		err := compiler.SaveBankStatementBatch(statements)
+		err := compiler.SaveBankStatementBatch(context.Background(), statements)
 		assert.NoError(t, err)
 	})
 
 	t.Run("Error saving bank statement", func(t *testing.T) {
-		mockBankStmtRepo.EXPECT().Save(statements[0]).Return(nil)
-		mockBankStmtRepo.EXPECT().Save(statements[1]).Return(errors.New("database error"))
+		mockBankStmtRepo.EXPECT().SaveBatch(gomock.Any(), statements).Return(errors.New("database error"))
 
 		compiler := usecase.NewFileCompiler(
 			&config.Config{},
 			nil,
 			mockBankStmtRepo,
 			nil,
-			mockKafkaRepo,
+			nil,
+			nil,
+			nil,
+			nil,
+			nil,
 		)
 
-		err := compiler.SaveBankStatementBatch(statements)
+		err := compiler.SaveBankStatementBatch(context.Background(), statements)
 		assert.Error(t, err)
 		assert.Contains(t, err.Error(), "database error")
 	})
@@ -465,11 +967,37 @@ func TestParseBankStatement(t *testing.T) {
 			record: []string{"bs-123", "500.25", "2023-01-15"},
 			expected: model.BankStatement{
 				ID:     "bs-123", // Updated to string ID
-				Amount: 500.25,
+				Amount: money("500.25"),
 				Date:   time.Date(2023, 1, 15, 0, 0, 0, 0, time.UTC),
 			},
 			expectedError: false,
 		},
+		{
+			name:   "Bank statement record with reference",
+			record: []string{"bs-124", "100.00", "2023-01-16", " PAY tx123 "},
+			expected: model.BankStatement{
+				ID:        "bs-124",
+				Amount:    money("100.00"),
+				Date:      time.Date(2023, 1, 16, 0, 0, 0, 0, time.UTC),
+				Reference: "PAY tx123",
+			},
+			expectedError: false,
+		},
+		{
+			name:   "Bank statement record with currency",
+			record: []string{"bs-125", "1.005", "2023-01-16", "", "bhd"},
+			expected: model.BankStatement{
+				ID:     "bs-125",
+				Amount: model.MustParseMoney("1.005", "BHD"),
+				Date:   time.Date(2023, 1, 16, 0, 0, 0, 0, time.UTC),
+			},
+			expectedError: false,
+		},
+		{
+			name:          "Invalid currency",
+			record:        []string{"bs-125", "100.00", "2023-01-16", "", "EURO"},
+			expectedError: true,
+		},
 		{
 			name:          "Record too short",
 			record:        []string{"bs-123", "500.25"},
@@ -497,6 +1025,7 @@ func TestParseBankStatement(t *testing.T) {
 				assert.NoError(t, err)
 				assert.Equal(t, tt.expected.ID, statement.ID)
 				assert.Equal(t, tt.expected.Amount, statement.Amount)
+				assert.Equal(t, tt.expected.Reference, statement.Reference)
 				assert.Equal(t, tt.expected.Date.Format("2006-01-02"),
 					statement.Date.Format("2006-01-02"))
 			}
@@ -0,0 +1,32 @@
+package usecase
+
+import (
+	"log"
+
+	"github.com/aferryc/yars/model"
+	"github.com/pkg/errors"
+)
+
+// errOtherEventType is returned for events meant for another handler on the
+// same topic
+var errOtherEventType = errors.New("event of another type")
+
+// eventPayload returns the envelope of msg and its payload upcast to the
+// current version of eventType. Events of other types return
+// errOtherEventType, and versions that cannot be upcast are rejected.
+func eventPayload(msg model.Message, eventType string) (model.EventEnvelope, []byte, error) {
+	envelope, err := model.ParseEventEnvelope(msg.Headers, eventType)
+	if err != nil {
+		return envelope, nil, err
+	}
+	if envelope.EventType != eventType {
+		log.Printf("Skipping %s event %s, expecting %s", envelope.EventType, envelope.EventID, eventType)
+		return envelope, nil, errOtherEventType
+	}
+
+	payload, err := model.UpcastEvent(envelope, msg.Value)
+	if err != nil {
+		return envelope, nil, err
+	}
+	return envelope, payload, nil
+}
@@ -0,0 +1,133 @@
+package usecase
+
+import (
+	"context"
+	"encoding/csv"
+	"io"
+	"math/big"
+	"time"
+
+	"github.com/aferryc/yars/model"
+	"github.com/aferryc/yars/repository"
+	"github.com/pkg/errors"
+)
+
+// FXRateUsecase manages the exchange rates used to reconcile records held in
+// different currencies
+type FXRateUsecase struct {
+	fxRepo repository.FXRateRepository
+}
+
+// NewFXRateUsecase creates a new instance of FXRateUsecase
+func NewFXRateUsecase(fxRepo repository.FXRateRepository) *FXRateUsecase {
+	return &FXRateUsecase{
+		fxRepo: fxRepo,
+	}
+}
+
+// ImportCSV loads rates from a CSV file with the header
+// date,base_currency,quote_currency,rate. The whole file is rejected when a
+// row is invalid, so a reconciliation never runs on a partial rate set.
+func (u *FXRateUsecase) ImportCSV(ctx context.Context, r io.Reader) (*model.FXRateImportResponse, error) {
+	csvReader := csv.NewReader(r)
+
+	// Skip header
+	if _, err := csvReader.Read(); err != nil {
+		return nil, errors.Wrap(model.ErrInvalidFXRate, "[ImportCSV] missing header")
+	}
+
+	var rates []model.FXRate
+	for {
+		record, err := csvReader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, errors.Wrapf(model.ErrInvalidFXRate, "[ImportCSV] %v", err)
+		}
+
+		rate, err := ParseFXRateRecord(record)
+		if err != nil {
+			line, _ := csvReader.FieldPos(0)
+			return nil, errors.Wrapf(model.ErrInvalidFXRate, "[ImportCSV] line %d: %v", line, err)
+		}
+		rates = append(rates, rate)
+	}
+
+	if err := u.fxRepo.SaveRates(ctx, rates); err != nil {
+		return nil, errors.Wrap(err, "[ImportCSV] error saving fx rates")
+	}
+
+	return &model.FXRateImportResponse{
+		Imported: len(rates),
+	}, nil
+}
+
+// GetRate returns the rate effective on date, the latest one published on or
+// before it. When only the opposite pair is published its rate is inverted.
+func (u *FXRateUsecase) GetRate(ctx context.Context, baseCurrency, quoteCurrency string, date time.Time) (*model.FXRateResponse, error) {
+	base, err := parseCurrency(baseCurrency)
+	if err != nil || base == "" {
+		return nil, errors.Wrapf(model.ErrInvalidFXRate, "[GetRate] invalid base currency %q", baseCurrency)
+	}
+	quote, err := parseCurrency(quoteCurrency)
+	if err != nil || quote == "" {
+		return nil, errors.Wrapf(model.ErrInvalidFXRate, "[GetRate] invalid quote currency %q", quoteCurrency)
+	}
+
+	rate, err := u.fxRepo.GetRate(ctx, base, quote, date)
+	if errors.Is(err, model.ErrFXRateNotFound) {
+		rate, err = u.fxRepo.GetRate(ctx, quote, base, date)
+		if err == nil {
+			rate = model.FXRate{
+				BaseCurrency:  base,
+				QuoteCurrency: quote,
+				Date:          rate.Date,
+				Rate:          new(big.Rat).Inv(rate.Rate),
+			}
+		}
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "[GetRate] error fetching fx rate")
+	}
+
+	return &model.FXRateResponse{
+		BaseCurrency:  rate.BaseCurrency,
+		QuoteCurrency: rate.QuoteCurrency,
+		Date:          rate.Date,
+		Rate:          model.FormatFXRate(rate.Rate),
+	}, nil
+}
+
+func ParseFXRateRecord(record []string) (model.FXRate, error) {
+	if len(record) < 4 {
+		return model.FXRate{}, errors.Wrap(errors.New("invalid record format"), "[parseFXRateRecord] error parsing fx rate record")
+	}
+
+	date, err := time.Parse("2006-01-02", record[0])
+	if err != nil {
+		return model.FXRate{}, errors.Wrap(err, "[parseFXRateRecord] error parsing date")
+	}
+
+	base, err := parseCurrency(record[1])
+	if err != nil || base == "" {
+		return model.FXRate{}, errors.Errorf("[parseFXRateRecord] invalid base currency %q", record[1])
+	}
+
+	quote, err := parseCurrency(record[2])
+	if err != nil || quote == "" {
+		return model.FXRate{}, errors.Errorf("[parseFXRateRecord] invalid quote currency %q", record[2])
+	}
+
+	rate, err := model.ParseFXRate(record[3])
+	if err != nil {
+		return model.FXRate{}, errors.Wrap(err, "[parseFXRateRecord] error parsing rate")
+	}
+
+	return model.FXRate{
+		BaseCurrency:  base,
+		QuoteCurrency: quote,
+		Date:          date,
+		Rate:          rate,
+	}, nil
+}
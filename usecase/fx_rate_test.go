@@ -0,0 +1,158 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aferryc/yars/model"
+	repositorymock "github.com/aferryc/yars/repository/mocks"
+	"github.com/aferryc/yars/usecase"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestFXRateUsecase_ImportCSV(t *testing.T) {
+	// Setup
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := repositorymock.NewMockFXRateRepository(ctrl)
+	useCase := usecase.NewFXRateUsecase(mockRepo)
+	ctx := context.Background()
+
+	t.Run("Successfully import rates", func(t *testing.T) {
+		content := "date,base_currency,quote_currency,rate\n" +
+			"2023-01-13,USD,EUR,0.9215\n" +
+			"2023-01-13,usd,jpy,128.5\n"
+
+		var saved []model.FXRate
+		mockRepo.EXPECT().
+			SaveRates(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, rates []model.FXRate) error {
+				saved = rates
+				return nil
+			})
+
+		// Execute
+		result, err := useCase.ImportCSV(ctx, strings.NewReader(content))
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, 2, result.Imported)
+		require.Len(t, saved, 2)
+		assert.Equal(t, "USD", saved[1].BaseCurrency)
+		assert.Equal(t, "JPY", saved[1].QuoteCurrency)
+		assert.Equal(t, time.Date(2023, 1, 13, 0, 0, 0, 0, time.UTC), saved[0].Date)
+		assert.Equal(t, 0, big.NewRat(9215, 10000).Cmp(saved[0].Rate))
+	})
+
+	t.Run("Invalid row rejects the whole file", func(t *testing.T) {
+		content := "date,base_currency,quote_currency,rate\n" +
+			"2023-01-13,USD,EUR,0.9215\n" +
+			"2023-01-13,USD,JPY,-1\n"
+
+		// Execute
+		result, err := useCase.ImportCSV(ctx, strings.NewReader(content))
+
+		// Assert
+		assert.Nil(t, result)
+		assert.True(t, errors.Is(err, model.ErrInvalidFXRate))
+		assert.Contains(t, err.Error(), "line 3")
+	})
+
+	t.Run("Empty file", func(t *testing.T) {
+		// Execute
+		_, err := useCase.ImportCSV(ctx, strings.NewReader(""))
+
+		// Assert
+		assert.True(t, errors.Is(err, model.ErrInvalidFXRate))
+	})
+
+	t.Run("Repository error", func(t *testing.T) {
+		content := "date,base_currency,quote_currency,rate\n2023-01-13,USD,EUR,0.9215\n"
+		mockRepo.EXPECT().
+			SaveRates(gomock.Any(), gomock.Any()).
+			Return(errors.New("database error"))
+
+		// Execute
+		_, err := useCase.ImportCSV(ctx, strings.NewReader(content))
+
+		// Assert
+		assert.Error(t, err)
+		assert.False(t, errors.Is(err, model.ErrInvalidFXRate))
+		assert.Contains(t, err.Error(), "database error")
+	})
+}
+
+func TestFXRateUsecase_GetRate(t *testing.T) {
+	// Setup
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := repositorymock.NewMockFXRateRepository(ctrl)
+	useCase := usecase.NewFXRateUsecase(mockRepo)
+	ctx := context.Background()
+	date := time.Date(2023, 1, 15, 0, 0, 0, 0, time.UTC)
+	published := time.Date(2023, 1, 13, 0, 0, 0, 0, time.UTC)
+
+	t.Run("Direct pair", func(t *testing.T) {
+		mockRepo.EXPECT().
+			GetRate(gomock.Any(), "USD", "EUR", date).
+			Return(model.FXRate{BaseCurrency: "USD", QuoteCurrency: "EUR", Date: published, Rate: big.NewRat(9215, 10000)}, nil)
+
+		// Execute
+		result, err := useCase.GetRate(ctx, "usd", "eur", date)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, "USD", result.BaseCurrency)
+		assert.Equal(t, "EUR", result.QuoteCurrency)
+		assert.Equal(t, published, result.Date)
+		assert.Equal(t, "0.9215", result.Rate)
+	})
+
+	t.Run("Inverted opposite pair", func(t *testing.T) {
+		mockRepo.EXPECT().
+			GetRate(gomock.Any(), "EUR", "USD", date).
+			Return(model.FXRate{}, model.ErrFXRateNotFound)
+		mockRepo.EXPECT().
+			GetRate(gomock.Any(), "USD", "EUR", date).
+			Return(model.FXRate{BaseCurrency: "USD", QuoteCurrency: "EUR", Date: published, Rate: big.NewRat(8, 10)}, nil)
+
+		// Execute
+		result, err := useCase.GetRate(ctx, "EUR", "USD", date)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, "EUR", result.BaseCurrency)
+		assert.Equal(t, "USD", result.QuoteCurrency)
+		assert.Equal(t, "1.25", result.Rate)
+	})
+
+	t.Run("Rate not found", func(t *testing.T) {
+		mockRepo.EXPECT().
+			GetRate(gomock.Any(), gomock.Any(), gomock.Any(), date).
+			Return(model.FXRate{}, model.ErrFXRateNotFound).
+			Times(2)
+
+		// Execute
+		result, err := useCase.GetRate(ctx, "USD", "GBP", date)
+
+		// Assert
+		assert.Nil(t, result)
+		assert.True(t, errors.Is(err, model.ErrFXRateNotFound))
+	})
+
+	t.Run("Invalid currency", func(t *testing.T) {
+		// Execute
+		_, err := useCase.GetRate(ctx, "US", "EUR", date)
+
+		// Assert
+		assert.True(t, errors.Is(err, model.ErrInvalidFXRate))
+	})
+}
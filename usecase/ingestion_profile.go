@@ -0,0 +1,157 @@
+package usecase
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/aferryc/yars/model"
+	"github.com/aferryc/yars/repository"
+	"github.com/pkg/errors"
+)
+
+var (
+	transactionFields = []string{
+		model.FieldID, model.FieldAmount, model.FieldType, model.FieldTransactionTime,
+		model.FieldCurrency, model.FieldDebit, model.FieldCredit, model.FieldIndicator,
+	}
+	bankStatementFields = []string{
+		model.FieldID, model.FieldAmount, model.FieldDate, model.FieldReference,
+		model.FieldCurrency, model.FieldDebit, model.FieldCredit, model.FieldIndicator,
+	}
+)
+
+// IngestionProfileUsecase manages the ingestion profiles describing the CSV
+// exports of each bank
+type IngestionProfileUsecase struct {
+	profileRepo repository.IngestionProfileRepository
+}
+
+// NewIngestionProfileUsecase creates a new instance of IngestionProfileUsecase
+func NewIngestionProfileUsecase(profileRepo repository.IngestionProfileRepository) *IngestionProfileUsecase {
+	return &IngestionProfileUsecase{
+		profileRepo: profileRepo,
+	}
+}
+
+// Save validates the profile and stores it as the profile of the bank,
+// replacing the previous one
+func (u *IngestionProfileUsecase) Save(ctx context.Context, bankName string, profile model.IngestionProfile) (*model.IngestionProfile, error) {
+	profile.BankName = strings.TrimSpace(bankName)
+	if profile.BankName == "" {
+		return nil, errors.Wrap(model.ErrInvalidIngestionProfile, "[SaveIngestionProfile] bank name is required")
+	}
+	if profile.Transactions != nil {
+		if err := validateFormat(*profile.Transactions, transactionFields, model.FieldTransactionTime); err != nil {
+			return nil, errors.Wrap(err, "[SaveIngestionProfile] invalid transaction format")
+		}
+	}
+	if profile.BankStatements != nil {
+		if err := validateFormat(*profile.BankStatements, bankStatementFields, model.FieldDate); err != nil {
+			return nil, errors.Wrap(err, "[SaveIngestionProfile] invalid bank statement format")
+		}
+	}
+
+	if err := u.profileRepo.Save(ctx, profile); err != nil {
+		return nil, errors.Wrap(err, "[SaveIngestionProfile] error saving ingestion profile")
+	}
+
+	saved, err := u.profileRepo.Get(ctx, profile.BankName)
+	if err != nil {
+		return nil, errors.Wrap(err, "[SaveIngestionProfile] error fetching ingestion profile")
+	}
+	return &saved, nil
+}
+
+// Get returns the profile of the bank
+func (u *IngestionProfileUsecase) Get(ctx context.Context, bankName string) (*model.IngestionProfile, error) {
+	profile, err := u.profileRepo.Get(ctx, strings.TrimSpace(bankName))
+	if err != nil {
+		return nil, errors.Wrap(err, "[GetIngestionProfile] error fetching ingestion profile")
+	}
+	return &profile, nil
+}
+
+// List returns the profiles of every bank
+func (u *IngestionProfileUsecase) List(ctx context.Context) ([]model.IngestionProfile, error) {
+	profiles, err := u.profileRepo.List(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "[ListIngestionProfiles] error fetching ingestion profiles")
+	}
+	return profiles, nil
+}
+
+// Delete removes the profile of the bank
+func (u *IngestionProfileUsecase) Delete(ctx context.Context, bankName string) error {
+	if err := u.profileRepo.Delete(ctx, strings.TrimSpace(bankName)); err != nil {
+		return errors.Wrap(err, "[DeleteIngestionProfile] error deleting ingestion profile")
+	}
+	return nil
+}
+
+// validateFormat checks the format only maps fields among fields, and maps the
+// ID, the date field and the amount columns of its sign convention
+func validateFormat(format model.CSVFormat, fields []string, dateField string) error {
+	if format.Delimiter != "" {
+		delimiter, size := utf8.DecodeRuneInString(format.Delimiter)
+		if size != len(format.Delimiter) || delimiter == utf8.RuneError ||
+			delimiter == '"' || delimiter == '\r' || delimiter == '\n' {
+			return errors.Wrapf(model.ErrInvalidIngestionProfile, "invalid delimiter %q", format.Delimiter)
+		}
+	}
+
+	for field, column := range format.Columns {
+		if !slices.Contains(fields, field) {
+			return errors.Wrapf(model.ErrInvalidIngestionProfile, "unknown field %q", field)
+		}
+		if (column.Name == "") == (column.Index == nil) {
+			return errors.Wrapf(model.ErrInvalidIngestionProfile, "column of %s needs either a name or an index", field)
+		}
+		if column.Index != nil && *column.Index < 0 {
+			return errors.Wrapf(model.ErrInvalidIngestionProfile, "negative column index for %s", field)
+		}
+	}
+
+	required := []string{model.FieldID, dateField}
+	switch format.SignConvention {
+	case "", model.SignConventionSigned:
+		required = append(required, model.FieldAmount)
+	case model.SignConventionIndicator:
+		required = append(required, model.FieldAmount, model.FieldIndicator)
+		if len(format.DebitIndicators) == 0 {
+			return errors.Wrap(model.ErrInvalidIngestionProfile, "debit indicators are required by the indicator sign convention")
+		}
+	case model.SignConventionSplit:
+		required = append(required, model.FieldDebit, model.FieldCredit)
+	default:
+		return errors.Wrapf(model.ErrInvalidIngestionProfile, "unknown sign convention %q", format.SignConvention)
+	}
+	for _, field := range required {
+		if _, ok := format.Columns[field]; !ok {
+			return errors.Wrapf(model.ErrInvalidIngestionProfile, "missing column for %s", field)
+		}
+	}
+
+	if len(format.DateLayouts) == 0 {
+		return errors.Wrap(model.ErrInvalidIngestionProfile, "at least one date layout is required")
+	}
+
+	decimal := format.DecimalSeparator
+	if decimal == "" {
+		decimal = "."
+	}
+	if utf8.RuneCountInString(decimal) != 1 || strings.ContainsAny(decimal, "0123456789+-") {
+		return errors.Wrapf(model.ErrInvalidIngestionProfile, "invalid decimal separator %q", format.DecimalSeparator)
+	}
+	if format.ThousandsSeparator != "" {
+		if utf8.RuneCountInString(format.ThousandsSeparator) != 1 || strings.ContainsAny(format.ThousandsSeparator, "0123456789+-") {
+			return errors.Wrapf(model.ErrInvalidIngestionProfile, "invalid thousands separator %q", format.ThousandsSeparator)
+		}
+		if format.ThousandsSeparator == decimal {
+			return errors.Wrap(model.ErrInvalidIngestionProfile, "thousands and decimal separators are the same")
+		}
+	}
+
+	return nil
+}
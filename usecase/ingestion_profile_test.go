@@ -0,0 +1,117 @@
+package usecase_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aferryc/yars/model"
+	repositorymock "github.com/aferryc/yars/repository/mocks"
+	"github.com/aferryc/yars/usecase"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestIngestionProfileUsecase_Save(t *testing.T) {
+	// Setup
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := repositorymock.NewMockIngestionProfileRepository(ctrl)
+	useCase := usecase.NewIngestionProfileUsecase(mockRepo)
+	ctx := context.Background()
+
+	validFormat := func() *model.CSVFormat {
+		return &model.CSVFormat{
+			Delimiter: ";",
+			Columns: map[string]model.Column{
+				model.FieldID:     {Name: "Reference"},
+				model.FieldDebit:  {Name: "Debit"},
+				model.FieldCredit: {Name: "Credit"},
+				model.FieldDate:   model.ColumnIndex(0),
+			},
+			DateLayouts:        []string{"02.01.2006"},
+			DecimalSeparator:   ",",
+			ThousandsSeparator: ".",
+			SignConvention:     model.SignConventionSplit,
+		}
+	}
+
+	t.Run("Saves a valid profile", func(t *testing.T) {
+		profile := model.IngestionProfile{BankName: "ignored", BankStatements: validFormat()}
+		stored := model.IngestionProfile{BankName: "TestBank", BankStatements: validFormat()}
+
+		mockRepo.EXPECT().Save(gomock.Any(), stored).Return(nil)
+		mockRepo.EXPECT().Get(gomock.Any(), "TestBank").Return(stored, nil)
+
+		// Execute
+		saved, err := useCase.Save(ctx, " TestBank ", profile)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, "TestBank", saved.BankName)
+	})
+
+	invalid := []struct {
+		name   string
+		modify func(*model.CSVFormat)
+	}{
+		{"Unknown field", func(f *model.CSVFormat) { f.Columns["type"] = model.ColumnIndex(5) }},
+		{"Column with a name and an index", func(f *model.CSVFormat) {
+			index := 1
+			f.Columns[model.FieldID] = model.Column{Name: "Reference", Index: &index}
+		}},
+		{"Missing amount column", func(f *model.CSVFormat) { delete(f.Columns, model.FieldDebit) }},
+		{"Missing date layout", func(f *model.CSVFormat) { f.DateLayouts = nil }},
+		{"Unknown sign convention", func(f *model.CSVFormat) { f.SignConvention = "reversed" }},
+		{"Indicator without debit indicators", func(f *model.CSVFormat) {
+			f.SignConvention = model.SignConventionIndicator
+			f.Columns[model.FieldAmount] = model.ColumnIndex(2)
+			f.Columns[model.FieldIndicator] = model.ColumnIndex(3)
+		}},
+		{"Same thousands and decimal separator", func(f *model.CSVFormat) { f.ThousandsSeparator = "," }},
+		{"Delimiter longer than a character", func(f *model.CSVFormat) { f.Delimiter = ";;" }},
+	}
+	for _, tt := range invalid {
+		t.Run(tt.name, func(t *testing.T) {
+			format := validFormat()
+			tt.modify(format)
+
+			// Execute
+			saved, err := useCase.Save(ctx, "TestBank", model.IngestionProfile{BankStatements: format})
+
+			// Assert
+			assert.ErrorIs(t, err, model.ErrInvalidIngestionProfile)
+			assert.Nil(t, saved)
+		})
+	}
+
+	t.Run("Missing bank name", func(t *testing.T) {
+		// Execute
+		saved, err := useCase.Save(ctx, " ", model.IngestionProfile{})
+
+		// Assert
+		assert.ErrorIs(t, err, model.ErrInvalidIngestionProfile)
+		assert.Nil(t, saved)
+	})
+}
+
+func TestIngestionProfileUsecase_Get(t *testing.T) {
+	// Setup
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := repositorymock.NewMockIngestionProfileRepository(ctrl)
+	useCase := usecase.NewIngestionProfileUsecase(mockRepo)
+
+	t.Run("Profile not found", func(t *testing.T) {
+		mockRepo.EXPECT().Get(gomock.Any(), "TestBank").Return(model.IngestionProfile{}, model.ErrIngestionProfileNotFound)
+
+		// Execute
+		profile, err := useCase.Get(context.Background(), "TestBank")
+
+		// Assert
+		assert.ErrorIs(t, err, model.ErrIngestionProfileNotFound)
+		assert.Nil(t, profile)
+	})
+}
@@ -33,7 +33,8 @@ func (u *ListUsecase) ListUnmatchedTransactions(ctx context.Context, taskID stri
 		result[i] = model.UnmatchedTransactionResponse{
 			ID:              tx.ID,
 			TaskID:          tx.TaskID,
-			Amount:          tx.Amount,
+			Amount:          tx.Amount.WithCurrency(tx.Currency),
+			Currency:        tx.Currency,
 			TransactionTime: tx.TransactionTime,
 			Type:            tx.Type,
 			Description:     tx.Description,
@@ -60,12 +61,97 @@ func (u *ListUsecase) ListUnmatchedBankStatements(ctx context.Context, taskID st
 	result := make([]model.UnmatchedBankStatementResponse, len(dbStatements))
 	for i, stmt := range dbStatements {
 		result[i] = model.UnmatchedBankStatementResponse{
-			ID:        stmt.ID,
-			TaskID:    stmt.TaskID,
-			Amount:    stmt.Amount,
-			Date:      stmt.Date,
-			Reference: stmt.Reference,
-			BankName:  stmt.BankName,
+			ID:              stmt.ID,
+			TaskID:          stmt.TaskID,
+			BankStatementID: stmt.BankStatementID,
+			Amount:          stmt.Amount.WithCurrency(stmt.Currency),
+			Currency:        stmt.Currency,
+			Date:            stmt.Date,
+			Reference:       stmt.Reference,
+			BankName:        stmt.BankName,
+		}
+	}
+
+	return &model.PaginatedResponse{
+		Data:       result,
+		TotalCount: totalCount,
+		Limit:      limit,
+		Offset:     offset,
+	}, nil
+}
+
+// ListMatchedPairs retrieves every transaction and bank statement pairing by task ID
+func (u *ListUsecase) ListMatchedPairs(ctx context.Context, taskID string, limit, offset int) (*model.PaginatedResponse, error) {
+	// Get matched pairs from repository
+	dbPairs, totalCount, err := u.reconRepo.GetMatchedPairs(ctx, taskID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	// Convert DB entities to response DTOs
+	result := make([]model.MatchedPairResponse, len(dbPairs))
+	for i, pair := range dbPairs {
+		result[i] = model.MatchedPairResponse{
+			ID:                  pair.ID,
+			TaskID:              pair.TaskID,
+			Rule:                pair.Rule,
+			MatchType:           pair.MatchType,
+			AggregateMatchID:    pair.AggregateMatchID.String,
+			TransactionID:       pair.TransactionID,
+			TransactionAmount:   pair.TransactionAmount.WithCurrency(pair.TransactionCurrency),
+			TransactionCurrency: pair.TransactionCurrency,
+			TransactionTime:     pair.TransactionTime,
+			BankStatementID:     pair.BankStatementID,
+			BankAmount:          pair.BankAmount.WithCurrency(pair.BankCurrency),
+			BankCurrency:        pair.BankCurrency,
+			BankDate:            pair.BankDate,
+			BankReference:       pair.BankReference.String,
+			CreatedAt:           pair.CreatedAt,
+		}
+		if pair.AmountDifference != nil {
+			difference := pair.AmountDifference.WithCurrency(pair.BankCurrency)
+			result[i].AmountDifference = &difference
+		}
+	}
+
+	return &model.PaginatedResponse{
+		Data:       result,
+		TotalCount: totalCount,
+		Limit:      limit,
+		Offset:     offset,
+	}, nil
+}
+
+// ListAggregateMatches retrieves the many-to-one and one-to-many groups by task ID
+func (u *ListUsecase) ListAggregateMatches(ctx context.Context, taskID string, limit, offset int) (*model.PaginatedResponse, error) {
+	// Get aggregate matches with their items from repository
+	dbMatches, totalCount, err := u.reconRepo.GetAggregateMatches(ctx, taskID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	// Convert DB entities to response DTOs
+	result := make([]model.AggregateMatchResponse, len(dbMatches))
+	for i, match := range dbMatches {
+		items := make([]model.AggregateMatchItemResponse, len(match.Items))
+		for j, item := range match.Items {
+			items[j] = model.AggregateMatchItemResponse{
+				RecordType: item.RecordType,
+				RecordID:   item.RecordID,
+				Amount:     item.Amount.WithCurrency(item.Currency),
+				Currency:   item.Currency,
+				Date:       item.Date,
+			}
+		}
+		result[i] = model.AggregateMatchResponse{
+			ID:        match.ID,
+			TaskID:    match.TaskID,
+			MatchType: match.MatchType,
+			Rule:      match.Rule,
+			Amount:    match.Amount.WithCurrency(match.Currency),
+			Currency:  match.Currency,
+			Items:     items,
+			CreatedAt: match.CreatedAt,
 		}
 	}
 
@@ -91,7 +177,9 @@ func (u *ListUsecase) ListReconSummaries(ctx context.Context, limit, offset int)
 		result[i] = model.ReconSummaryResponse{
 			TaskID:                 summary.TaskID,
 			TotalMatched:           summary.TotalMatched,
-			TotalDiscrepancy:       summary.TotalDiscrepancy,
+			TotalDiscrepancy:       summary.TotalDiscrepancy.WithCurrency(summary.Currency),
+			Currency:               summary.Currency,
+			MissingRates:           summary.MissingRates,
 			TotalTransaction:       summary.TotalTransaction,
 			TotalUnmatchedBank:     summary.TotalUnmatchedBank,
 			TotalUnmatchedInternal: summary.TotalUnmatchedInternal,
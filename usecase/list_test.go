@@ -2,6 +2,7 @@ package usecase_test
 
 import (
 	"context"
+	"database/sql"
 	"errors"
 	"testing"
 	"time"
@@ -33,7 +34,7 @@ func TestListUnmatchedTransactions(t *testing.T) {
 			{
 				ID:              "tx1",
 				TaskID:          taskID,
-				Amount:          100.50,
+				Amount:          money("100.50"),
 				TransactionTime: time.Date(2023, 1, 15, 12, 0, 0, 0, time.UTC),
 				Type:            "CREDIT",
 				Description:     "Test Transaction 1",
@@ -41,7 +42,7 @@ func TestListUnmatchedTransactions(t *testing.T) {
 			{
 				ID:              "tx2",
 				TaskID:          taskID,
-				Amount:          200.75,
+				Amount:          money("200.75"),
 				TransactionTime: time.Date(2023, 1, 16, 14, 0, 0, 0, time.UTC),
 				Type:            "DEBIT",
 				Description:     "Test Transaction 2",
@@ -72,7 +73,7 @@ func TestListUnmatchedTransactions(t *testing.T) {
 		assert.Len(t, transactions, 2)
 		assert.Equal(t, "tx1", transactions[0].ID)
 		assert.Equal(t, taskID, transactions[0].TaskID)
-		assert.Equal(t, 100.50, transactions[0].Amount)
+		assert.Equal(t, money("100.50"), transactions[0].Amount)
 		assert.Equal(t, "CREDIT", transactions[0].Type)
 		assert.Equal(t, "Test Transaction 1", transactions[0].Description)
 	})
@@ -134,20 +135,22 @@ func TestListUnmatchedBankStatements(t *testing.T) {
 		// Test data
 		mockStatements := []postgres.UnmatchedBankStatement{
 			{
-				ID:        1,
-				TaskID:    taskID,
-				Amount:    100.50,
-				Date:      time.Date(2023, 1, 15, 0, 0, 0, 0, time.UTC),
-				Reference: "REF123",
-				BankName:  "Test Bank",
+				ID:              1,
+				TaskID:          taskID,
+				BankStatementID: "STMT-1/1",
+				Amount:          money("100.50"),
+				Date:            time.Date(2023, 1, 15, 0, 0, 0, 0, time.UTC),
+				Reference:       "REF123",
+				BankName:        "Test Bank",
 			},
 			{
-				ID:        2,
-				TaskID:    taskID,
-				Amount:    200.75,
-				Date:      time.Date(2023, 1, 16, 0, 0, 0, 0, time.UTC),
-				Reference: "REF456",
-				BankName:  "Test Bank",
+				ID:              2,
+				TaskID:          taskID,
+				BankStatementID: "STMT-1/2",
+				Amount:          money("200.75"),
+				Date:            time.Date(2023, 1, 16, 0, 0, 0, 0, time.UTC),
+				Reference:       "REF456",
+				BankName:        "Test Bank",
 			},
 		}
 		totalCount := 8 // Total bank statements in database
@@ -175,7 +178,8 @@ func TestListUnmatchedBankStatements(t *testing.T) {
 		assert.Len(t, statements, 2)
 		assert.Equal(t, 1, statements[0].ID)
 		assert.Equal(t, taskID, statements[0].TaskID)
-		assert.Equal(t, 100.50, statements[0].Amount)
+		assert.Equal(t, "STMT-1/1", statements[0].BankStatementID)
+		assert.Equal(t, money("100.50"), statements[0].Amount)
 		assert.Equal(t, "REF123", statements[0].Reference)
 		assert.Equal(t, "Test Bank", statements[0].BankName)
 	})
@@ -243,7 +247,7 @@ func TestListReconSummaries(t *testing.T) {
 			{
 				TaskID:                 "task1",
 				TotalMatched:           10,
-				TotalDiscrepancy:       150.75,
+				TotalDiscrepancy:       money("150.75"),
 				TotalTransaction:       15,
 				TotalUnmatchedBank:     3,
 				TotalUnmatchedInternal: 2,
@@ -255,7 +259,7 @@ func TestListReconSummaries(t *testing.T) {
 			{
 				TaskID:                 "task2",
 				TotalMatched:           20,
-				TotalDiscrepancy:       75.25,
+				TotalDiscrepancy:       money("75.25"),
 				TotalTransaction:       25,
 				TotalUnmatchedBank:     2,
 				TotalUnmatchedInternal: 3,
@@ -290,7 +294,7 @@ func TestListReconSummaries(t *testing.T) {
 		assert.Len(t, summaries, 2)
 		assert.Equal(t, "task1", summaries[0].TaskID)
 		assert.Equal(t, 10, summaries[0].TotalMatched)
-		assert.Equal(t, 150.75, summaries[0].TotalDiscrepancy)
+		assert.Equal(t, money("150.75"), summaries[0].TotalDiscrepancy)
 		assert.Equal(t, 15, summaries[0].TotalTransaction)
 		assert.Equal(t, 3, summaries[0].TotalUnmatchedBank)
 		assert.Equal(t, 2, summaries[0].TotalUnmatchedInternal)
@@ -340,3 +344,166 @@ func TestListReconSummaries(t *testing.T) {
 		assert.Nil(t, result)
 	})
 }
+
+func TestListAggregateMatches(t *testing.T) {
+	// Setup
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := repositorymock.NewMockReconResultRepository(ctrl)
+	useCase := usecase.NewListUsecase(mockRepo)
+	ctx := context.Background()
+	taskID := "test-task-id"
+	limit := 10
+	offset := 0
+
+	t.Run("Successfully retrieve aggregate matches", func(t *testing.T) {
+		// Test data
+		date := time.Date(2023, 1, 15, 0, 0, 0, 0, time.UTC)
+		mockMatches := []postgres.AggregateMatch{
+			{
+				ID:        "group-1",
+				TaskID:    taskID,
+				MatchType: model.MatchManyToOne,
+				Rule:      "aggregate",
+				Amount:    money("100.00"),
+				Items: []postgres.AggregateMatchItem{
+					{AggregateMatchID: "group-1", RecordType: postgres.RecordTypeTransaction, RecordID: "tx1", Amount: money("40.00"), Date: date},
+					{AggregateMatchID: "group-1", RecordType: postgres.RecordTypeTransaction, RecordID: "tx2", Amount: money("60.00"), Date: date},
+					{AggregateMatchID: "group-1", RecordType: postgres.RecordTypeBankStatement, RecordID: "bs-1", Amount: money("100.00"), Date: date},
+				},
+			},
+		}
+
+		// Set expectations
+		mockRepo.EXPECT().
+			GetAggregateMatches(gomock.Any(), taskID, limit, offset).
+			Return(mockMatches, 1, nil)
+
+		// Execute
+		result, err := useCase.ListAggregateMatches(ctx, taskID, limit, offset)
+
+		// Assert
+		require.NoError(t, err)
+		require.NotNil(t, result)
+		assert.Equal(t, 1, result.TotalCount)
+
+		matches, ok := result.Data.([]model.AggregateMatchResponse)
+		require.True(t, ok, "Data should be of type []model.AggregateMatchResponse")
+		require.Len(t, matches, 1)
+		assert.Equal(t, "group-1", matches[0].ID)
+		assert.Equal(t, model.MatchManyToOne, matches[0].MatchType)
+		assert.Equal(t, "aggregate", matches[0].Rule)
+		assert.Equal(t, money("100.00"), matches[0].Amount)
+		require.Len(t, matches[0].Items, 3)
+		assert.Equal(t, "bs-1", matches[0].Items[2].RecordID)
+		assert.Equal(t, postgres.RecordTypeBankStatement, matches[0].Items[2].RecordType)
+	})
+
+	t.Run("Repository error", func(t *testing.T) {
+		// Set expectations
+		expectedErr := errors.New("database error")
+		mockRepo.EXPECT().
+			GetAggregateMatches(gomock.Any(), taskID, limit, offset).
+			Return(nil, 0, expectedErr)
+
+		// Execute
+		result, err := useCase.ListAggregateMatches(ctx, taskID, limit, offset)
+
+		// Assert
+		assert.Error(t, err)
+		assert.Equal(t, expectedErr, err)
+		assert.Nil(t, result)
+	})
+}
+
+func TestListMatchedPairs(t *testing.T) {
+	// Setup
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := repositorymock.NewMockReconResultRepository(ctrl)
+	useCase := usecase.NewListUsecase(mockRepo)
+	ctx := context.Background()
+	taskID := "test-task-id"
+	limit := 10
+	offset := 0
+
+	t.Run("Successfully retrieve matched pairs", func(t *testing.T) {
+		// Test data
+		txTime := time.Date(2023, 1, 15, 12, 0, 0, 0, time.UTC)
+		bankDate := time.Date(2023, 1, 16, 0, 0, 0, 0, time.UTC)
+		mockPairs := []postgres.MatchedPair{
+			{
+				ID:                1,
+				TaskID:            taskID,
+				Rule:              "exact_reference",
+				MatchType:         model.MatchOneToOne,
+				TransactionID:     "tx1",
+				TransactionAmount: money("100.50"),
+				TransactionTime:   txTime,
+				BankStatementID:   "bs-1",
+				BankAmount:        money("99.50"),
+				BankDate:          bankDate,
+				BankReference:     sql.NullString{String: "PAY tx1", Valid: true},
+				AmountDifference:  moneyRef("-1.00"),
+			},
+			{
+				ID:                2,
+				TaskID:            taskID,
+				Rule:              "aggregate",
+				MatchType:         model.MatchManyToOne,
+				AggregateMatchID:  sql.NullString{String: "group-1", Valid: true},
+				TransactionID:     "tx2",
+				TransactionAmount: money("40.00"),
+				TransactionTime:   txTime,
+				BankStatementID:   "bs-2",
+				BankAmount:        money("100.00"),
+				BankDate:          bankDate,
+			},
+		}
+
+		// Set expectations
+		mockRepo.EXPECT().
+			GetMatchedPairs(gomock.Any(), taskID, limit, offset).
+			Return(mockPairs, 2, nil)
+
+		// Execute
+		result, err := useCase.ListMatchedPairs(ctx, taskID, limit, offset)
+
+		// Assert
+		require.NoError(t, err)
+		require.NotNil(t, result)
+		assert.Equal(t, 2, result.TotalCount)
+
+		pairs, ok := result.Data.([]model.MatchedPairResponse)
+		require.True(t, ok, "Data should be of type []model.MatchedPairResponse")
+		require.Len(t, pairs, 2)
+		assert.Equal(t, "tx1", pairs[0].TransactionID)
+		assert.Equal(t, "bs-1", pairs[0].BankStatementID)
+		assert.Equal(t, "exact_reference", pairs[0].Rule)
+		assert.Equal(t, "PAY tx1", pairs[0].BankReference)
+		assert.Empty(t, pairs[0].AggregateMatchID)
+		assert.Equal(t, moneyRef("-1.00"), pairs[0].AmountDifference)
+		assert.Equal(t, "group-1", pairs[1].AggregateMatchID)
+		assert.Nil(t, pairs[1].AmountDifference)
+		assert.Equal(t, txTime, pairs[1].TransactionTime)
+		assert.Equal(t, bankDate, pairs[1].BankDate)
+	})
+
+	t.Run("Repository error", func(t *testing.T) {
+		// Set expectations
+		expectedErr := errors.New("database error")
+		mockRepo.EXPECT().
+			GetMatchedPairs(gomock.Any(), taskID, limit, offset).
+			Return(nil, 0, expectedErr)
+
+		// Execute
+		result, err := useCase.ListMatchedPairs(ctx, taskID, limit, offset)
+
+		// Assert
+		assert.Error(t, err)
+		assert.Equal(t, expectedErr, err)
+		assert.Nil(t, result)
+	})
+}
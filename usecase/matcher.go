@@ -0,0 +1,142 @@
+package usecase
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/aferryc/yars/internal/config"
+	"github.com/aferryc/yars/model"
+)
+
+const (
+	MatcherExactReference = "exact_reference"
+	MatcherAmountDate     = "amount_date"
+	MatcherAggregate      = "aggregate"
+	MatcherFXAmountDate   = "fx_amount_date"
+)
+
+// DefaultMatcherChain is used when no chain is configured
+var DefaultMatcherChain = []string{MatcherExactReference, MatcherAmountDate, MatcherFXAmountDate}
+
+// Matcher pairs internal transactions with bank statements. In a chain every
+// matcher only receives the records the previous matchers left unmatched.
+type Matcher interface {
+	// Name identifies the rule and is recorded on every match it produces
+	Name() string
+	Match(transactions []model.Transaction, statements []model.BankStatement) MatchResult
+}
+
+// RateAwareMatcher is implemented by matchers that compare amounts across
+// currencies. The chain hands them the exchange rates of the reconciliation
+// being run, so the shared matcher itself is never modified.
+type RateAwareMatcher interface {
+	Matcher
+	WithRates(rates *model.FXRateTable) Matcher
+}
+
+// MatchResult holds the matches found by a Matcher and the records it could not pair
+type MatchResult struct {
+	Matches           []model.Match
+	UnmatchedInternal []model.Transaction
+	UnmatchedBank     []model.BankStatement
+}
+
+// MatcherFactory builds a Matcher from the reconciliation config
+type MatcherFactory func(cfg config.ReconciliationConfig) Matcher
+
+var (
+	matcherFactoriesMu sync.RWMutex
+	matcherFactories   = map[string]MatcherFactory{
+		MatcherExactReference: func(cfg config.ReconciliationConfig) Matcher {
+			return NewReferenceMatcher(cfg.ReferenceNormalization)
+		},
+		MatcherAmountDate: func(cfg config.ReconciliationConfig) Matcher {
+			return NewAmountDateMatcher(cfg.DateToleranceDays)
+		},
+		MatcherAggregate: func(cfg config.ReconciliationConfig) Matcher {
+			return NewAggregateMatcher(cfg.Aggregate, cfg.DateToleranceDays)
+		},
+		MatcherFXAmountDate: func(cfg config.ReconciliationConfig) Matcher {
+			return NewFXAmountDateMatcher(cfg.DateToleranceDays, cfg.FX.ToleranceBasisPoints)
+		},
+	}
+)
+
+// RegisterMatcher makes a matcher available to the configured chain under the
+// given name, so bank-specific strategies can be plugged in without touching
+// the reconciliation usecase. Registering an existing name replaces it.
+func RegisterMatcher(name string, factory MatcherFactory) {
+	matcherFactoriesMu.Lock()
+	defer matcherFactoriesMu.Unlock()
+	matcherFactories[name] = factory
+}
+
+// BuildMatcherChain resolves the configured matcher names, in order, into matchers
+func BuildMatcherChain(cfg config.ReconciliationConfig) ([]Matcher, error) {
+	names := cfg.MatcherChain
+	if len(names) == 0 {
+		names = DefaultMatcherChain
+	}
+
+	matcherFactoriesMu.RLock()
+	defer matcherFactoriesMu.RUnlock()
+
+	matchers := make([]Matcher, 0, len(names))
+	for _, name := range names {
+		factory, ok := matcherFactories[strings.TrimSpace(name)]
+		if !ok {
+			return nil, fmt.Errorf("unknown matcher %q", name)
+		}
+		matchers = append(matchers, factory(cfg))
+	}
+	return matchers, nil
+}
+
+// runMatcherChain runs the matchers in order, feeding each one the leftovers
+// of the previous, and stamps every match with the rule that produced it and
+// the difference between the amounts of a pair.
+func runMatcherChain(matchers []Matcher, rates *model.FXRateTable, transactions []model.Transaction, statements []model.BankStatement) MatchResult {
+	result := MatchResult{
+		UnmatchedInternal: transactions,
+		UnmatchedBank:     statements,
+	}
+
+	for _, matcher := range matchers {
+		if len(result.UnmatchedInternal) == 0 && len(result.UnmatchedBank) == 0 {
+			break
+		}
+
+		if rateAware, ok := matcher.(RateAwareMatcher); ok {
+			matcher = rateAware.WithRates(rates)
+		}
+
+		step := matcher.Match(result.UnmatchedInternal, result.UnmatchedBank)
+		for _, match := range step.Matches {
+			match.Rule = matcher.Name()
+			if match.Type == model.MatchOneToOne {
+				match.Difference = amountDifference(rates, match.Transactions[0], match.BankStatements[0])
+			}
+			result.Matches = append(result.Matches, match)
+		}
+		result.UnmatchedInternal = step.UnmatchedInternal
+		result.UnmatchedBank = step.UnmatchedBank
+	}
+
+	return result
+}
+
+// amountDifference returns the bank amount less the transaction amount, the
+// transaction amount being converted into the bank currency at the rate of its
+// date. It returns nil when there is no such rate.
+func amountDifference(rates *model.FXRateTable, transaction model.Transaction, statement model.BankStatement) *model.Money {
+	amount, err := rates.Convert(transaction.SignedAmount(), statement.Amount.Currency(), transaction.TransactionTime)
+	if err != nil {
+		return nil
+	}
+	difference, err := statement.Amount.Add(amount.Neg())
+	if err != nil {
+		return nil
+	}
+	return &difference
+}
@@ -0,0 +1,227 @@
+package usecase
+
+import (
+	"sort"
+	"time"
+
+	"github.com/aferryc/yars/internal/config"
+	"github.com/aferryc/yars/model"
+)
+
+// deadlineCheckInterval is how many search steps run between deadline checks
+const deadlineCheckInterval = 1024
+
+// aggregateCandidate is a record that may take part in a group, with its
+// absolute amount in minor units.
+type aggregateCandidate struct {
+	index int
+	minor int64
+}
+
+// AggregateMatcher looks for groups of records on one side whose amounts sum
+// to a single record on the other side. Many-to-one groups are searched first,
+// then one-to-many groups on what is left. Every member of a group has to be
+// in the same currency as, and within the date tolerance of, the single record
+// it is grouped with.
+type AggregateMatcher struct {
+	cfg           config.AggregateConfig
+	toleranceDays int
+}
+
+// NewAggregateMatcher creates an AggregateMatcher bounded by the group size
+// and search time in cfg.
+func NewAggregateMatcher(cfg config.AggregateConfig, toleranceDays int) *AggregateMatcher {
+	return &AggregateMatcher{
+		cfg:           cfg,
+		toleranceDays: toleranceDays,
+	}
+}
+
+func (m *AggregateMatcher) Name() string {
+	return MatcherAggregate
+}
+
+func (m *AggregateMatcher) Match(transactions []model.Transaction, statements []model.BankStatement) MatchResult {
+	if m.cfg.MaxGroupSize < 2 || len(transactions) == 0 || len(statements) == 0 {
+		return MatchResult{
+			UnmatchedInternal: transactions,
+			UnmatchedBank:     statements,
+		}
+	}
+
+	deadline := time.Now().Add(m.cfg.SearchTimeout)
+	usedTx := make([]bool, len(transactions))
+	usedBank := make([]bool, len(statements))
+	var result MatchResult
+
+	// Many internal transactions settled as one bank statement line
+	for j, statement := range statements {
+		target := statement.Amount.Minor()
+		var candidates []aggregateCandidate
+		for i, transaction := range transactions {
+			if usedTx[i] || transaction.Amount.Currency() != statement.Amount.Currency() ||
+				!withinTolerance(transaction.TransactionTime, statement.Date, m.toleranceDays) {
+				continue
+			}
+			if minor := transaction.SignedAmount().Minor(); sameSign(minor, target) {
+				candidates = append(candidates, aggregateCandidate{index: i, minor: abs(minor)})
+			}
+		}
+
+		group, timedOut := findAggregateGroup(abs(target), candidates, m.cfg.MaxGroupSize, deadline)
+		if timedOut {
+			break
+		}
+		if group == nil {
+			continue
+		}
+
+		match := model.Match{
+			Type:           model.MatchManyToOne,
+			BankStatements: []model.BankStatement{statement},
+		}
+		for _, i := range group {
+			usedTx[i] = true
+			match.Transactions = append(match.Transactions, transactions[i])
+		}
+		usedBank[j] = true
+		result.Matches = append(result.Matches, match)
+	}
+
+	// One internal transaction split across several bank statement lines
+	for i, transaction := range transactions {
+		if usedTx[i] || time.Now().After(deadline) {
+			continue
+		}
+
+		target := transaction.SignedAmount().Minor()
+		var candidates []aggregateCandidate
+		for j, statement := range statements {
+			if usedBank[j] || transaction.Amount.Currency() != statement.Amount.Currency() ||
+				!withinTolerance(transaction.TransactionTime, statement.Date, m.toleranceDays) {
+				continue
+			}
+			if minor := statement.Amount.Minor(); sameSign(minor, target) {
+				candidates = append(candidates, aggregateCandidate{index: j, minor: abs(minor)})
+			}
+		}
+
+		group, timedOut := findAggregateGroup(abs(target), candidates, m.cfg.MaxGroupSize, deadline)
+		if timedOut {
+			break
+		}
+		if group == nil {
+			continue
+		}
+
+		match := model.Match{
+			Type:         model.MatchOneToMany,
+			Transactions: []model.Transaction{transaction},
+		}
+		for _, j := range group {
+			usedBank[j] = true
+			match.BankStatements = append(match.BankStatements, statements[j])
+		}
+		usedTx[i] = true
+		result.Matches = append(result.Matches, match)
+	}
+
+	for i, transaction := range transactions {
+		if !usedTx[i] {
+			result.UnmatchedInternal = append(result.UnmatchedInternal, transaction)
+		}
+	}
+
+	for j, statement := range statements {
+		if !usedBank[j] {
+			result.UnmatchedBank = append(result.UnmatchedBank, statement)
+		}
+	}
+
+	return result
+}
+
+// findAggregateGroup runs a bounded depth-first subset sum over the candidates
+// and returns the indexes of a group of at least two records summing exactly
+// to target. The second return value reports whether the deadline was hit.
+func findAggregateGroup(target int64, candidates []aggregateCandidate, maxSize int, deadline time.Time) ([]int, bool) {
+	if target == 0 || len(candidates) < 2 {
+		return nil, false
+	}
+
+	// Largest amounts first so the search overshoots early and prunes more
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].minor > candidates[j].minor
+	})
+
+	// suffix[i] is the sum of all candidates from i onwards
+	suffix := make([]int64, len(candidates)+1)
+	for i := len(candidates) - 1; i >= 0; i-- {
+		suffix[i] = suffix[i+1] + candidates[i].minor
+	}
+	if suffix[0] < target {
+		return nil, false
+	}
+
+	var path []int
+	var steps int
+	var timedOut bool
+
+	var search func(start int, remaining int64) bool
+	search = func(start int, remaining int64) bool {
+		if remaining == 0 {
+			return len(path) >= 2
+		}
+		if len(path) == maxSize {
+			return false
+		}
+		for i := start; i < len(candidates); i++ {
+			steps++
+			if steps%deadlineCheckInterval == 0 && time.Now().After(deadline) {
+				timedOut = true
+			}
+			if timedOut || suffix[i] < remaining {
+				return false
+			}
+			if candidates[i].minor > remaining {
+				continue
+			}
+			path = append(path, i)
+			if search(i+1, remaining-candidates[i].minor) {
+				return true
+			}
+			path = path[:len(path)-1]
+		}
+		return false
+	}
+
+	if !search(0, target) {
+		return nil, timedOut
+	}
+
+	group := make([]int, len(path))
+	for k, p := range path {
+		group[k] = candidates[p].index
+	}
+	return group, false
+}
+
+// withinTolerance reports whether two dates are at most toleranceDays apart.
+// A negative tolerance disables the date check.
+func withinTolerance(a, b time.Time, toleranceDays int) bool {
+	if toleranceDays < 0 {
+		return true
+	}
+	return abs(int64(dayNumber(a)-dayNumber(b))) <= int64(toleranceDays)
+}
+
+func sameSign(a, b int64) bool {
+	return (a > 0 && b > 0) || (a < 0 && b < 0)
+}
+
+func abs(v int64) int64 {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
@@ -0,0 +1,159 @@
+package usecase
+
+import (
+	"sort"
+	"time"
+
+	"github.com/aferryc/yars/model"
+)
+
+// AmountDateMatcher pairs records with the same amount whose dates are within
+// the configured tolerance, the closest dates winning among candidates.
+type AmountDateMatcher struct {
+	toleranceDays int
+}
+
+// NewAmountDateMatcher creates an AmountDateMatcher. A tolerance of zero only
+// pairs records of the same day, while a negative one such as
+// config.DateToleranceDisabled disables the date check and matches on amount
+// only.
+func NewAmountDateMatcher(toleranceDays int) *AmountDateMatcher {
+	return &AmountDateMatcher{
+		toleranceDays: toleranceDays,
+	}
+}
+
+func (m *AmountDateMatcher) Name() string {
+	return MatcherAmountDate
+}
+
+func (m *AmountDateMatcher) Match(transactions []model.Transaction, statements []model.BankStatement) MatchResult {
+	tx := model.TransactionList{Transactions: transactions}.Precompile()
+	bank := model.BankStatementList{BankStatements: statements}.Precompile()
+
+	// Walk the amount buckets in a fixed order so results are reproducible
+	amounts := make([]string, 0, len(tx.List))
+	for amount := range tx.List {
+		amounts = append(amounts, amount)
+	}
+	sort.Strings(amounts)
+
+	var result MatchResult
+	for _, amount := range amounts {
+		pairs, unmatchedInternal, unmatchedBank := pairByClosestDate(tx.List[amount], bank.List[amount], m.toleranceDays)
+		result.Matches = append(result.Matches, pairs...)
+		result.UnmatchedInternal = append(result.UnmatchedInternal, unmatchedInternal...)
+		result.UnmatchedBank = append(result.UnmatchedBank, unmatchedBank...)
+	}
+
+	bankAmounts := make([]string, 0, len(bank.List))
+	for amount := range bank.List {
+		// Buckets with internal transactions were already handled above
+		if tx.Count[amount] == 0 {
+			bankAmounts = append(bankAmounts, amount)
+		}
+	}
+	sort.Strings(bankAmounts)
+
+	for _, amount := range bankAmounts {
+		result.UnmatchedBank = append(result.UnmatchedBank, bank.List[amount]...)
+	}
+
+	return result
+}
+
+// datePairCandidate is a possible pairing between a transaction and a bank
+// statement of the same amount, scored by how many days apart they are.
+type datePairCandidate struct {
+	txIndex   int
+	bankIndex int
+	distance  int
+}
+
+// pairByClosestDate pairs transactions with bank statements of the same amount.
+// A pair is only allowed when both dates are at most toleranceDays apart, and
+// the closest dates win when several candidates compete for the same record.
+// A negative tolerance disables the date check and pairs records in order.
+func pairByClosestDate(transactions []model.Transaction, statements []model.BankStatement, toleranceDays int) ([]model.Match, []model.Transaction, []model.BankStatement) {
+	if toleranceDays < 0 {
+		matched := min(len(transactions), len(statements))
+		pairs := make([]model.Match, matched)
+		for i := 0; i < matched; i++ {
+			pairs[i] = newOneToOneMatch(transactions[i], statements[i])
+		}
+		return pairs, transactions[matched:], statements[matched:]
+	}
+
+	// Sort a copy of the statements by date so candidates for each
+	// transaction can be located with a binary search
+	sorted := make([]model.BankStatement, len(statements))
+	copy(sorted, statements)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Date.Before(sorted[j].Date)
+	})
+
+	var candidates []datePairCandidate
+	for i, transaction := range transactions {
+		txDay := dayNumber(transaction.TransactionTime)
+		start := sort.Search(len(sorted), func(j int) bool {
+			return dayNumber(sorted[j].Date) >= txDay-toleranceDays
+		})
+		for j := start; j < len(sorted); j++ {
+			distance := dayNumber(sorted[j].Date) - txDay
+			if distance > toleranceDays {
+				break
+			}
+			candidates = append(candidates, datePairCandidate{
+				txIndex:   i,
+				bankIndex: j,
+				distance:  max(distance, -distance),
+			})
+		}
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].distance < candidates[j].distance
+	})
+
+	usedTx := make([]bool, len(transactions))
+	usedBank := make([]bool, len(sorted))
+	var pairs []model.Match
+	for _, candidate := range candidates {
+		if usedTx[candidate.txIndex] || usedBank[candidate.bankIndex] {
+			continue
+		}
+		usedTx[candidate.txIndex] = true
+		usedBank[candidate.bankIndex] = true
+		pairs = append(pairs, newOneToOneMatch(transactions[candidate.txIndex], sorted[candidate.bankIndex]))
+	}
+
+	var unmatchedInternal []model.Transaction
+	for i, transaction := range transactions {
+		if !usedTx[i] {
+			unmatchedInternal = append(unmatchedInternal, transaction)
+		}
+	}
+
+	var unmatchedBank []model.BankStatement
+	for j, statement := range sorted {
+		if !usedBank[j] {
+			unmatchedBank = append(unmatchedBank, statement)
+		}
+	}
+
+	return pairs, unmatchedInternal, unmatchedBank
+}
+
+func newOneToOneMatch(transaction model.Transaction, statement model.BankStatement) model.Match {
+	return model.Match{
+		Type:           model.MatchOneToOne,
+		Transactions:   []model.Transaction{transaction},
+		BankStatements: []model.BankStatement{statement},
+	}
+}
+
+// dayNumber returns the number of whole days since the Unix epoch in UTC,
+// so that a timestamp and a plain date on the same calendar day compare equal.
+func dayNumber(t time.Time) int {
+	return int(t.UTC().Unix() / int64(24*time.Hour/time.Second))
+}
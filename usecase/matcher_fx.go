@@ -0,0 +1,158 @@
+package usecase
+
+import (
+	"math/bits"
+	"sort"
+
+	"github.com/aferryc/yars/model"
+)
+
+// basisPointsPerUnit is the number of basis points in 100%
+const basisPointsPerUnit = 10000
+
+// FXAmountDateMatcher pairs records held in different currencies. The
+// transaction amount is converted into the bank statement currency at the rate
+// of the transaction date, and the pair is accepted when the converted amount
+// is within the configured tolerance of the bank amount. Records in the same
+// currency are left to the other matchers.
+type FXAmountDateMatcher struct {
+	toleranceDays int
+	toleranceBps  int
+	rates         *model.FXRateTable
+}
+
+// fxPairCandidate is a possible cross-currency pairing, scored by how many
+// days apart both records are and then by how far the converted amount is
+// from the bank amount.
+type fxPairCandidate struct {
+	txIndex    int
+	bankIndex  int
+	distance   int
+	difference int64
+}
+
+// NewFXAmountDateMatcher creates an FXAmountDateMatcher. A negative date
+// tolerance disables the date check.
+func NewFXAmountDateMatcher(toleranceDays, toleranceBps int) *FXAmountDateMatcher {
+	return &FXAmountDateMatcher{
+		toleranceDays: toleranceDays,
+		toleranceBps:  toleranceBps,
+	}
+}
+
+func (m *FXAmountDateMatcher) Name() string {
+	return MatcherFXAmountDate
+}
+
+// WithRates returns a copy of the matcher converting with the given rates
+func (m *FXAmountDateMatcher) WithRates(rates *model.FXRateTable) Matcher {
+	matcher := *m
+	matcher.rates = rates
+	return &matcher
+}
+
+func (m *FXAmountDateMatcher) Match(transactions []model.Transaction, statements []model.BankStatement) MatchResult {
+	// Sort a copy of the statements by date so candidates for each
+	// transaction can be located with a binary search
+	sorted := make([]model.BankStatement, len(statements))
+	copy(sorted, statements)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Date.Before(sorted[j].Date)
+	})
+
+	var candidates []fxPairCandidate
+	for i, transaction := range transactions {
+		amount := transaction.SignedAmount()
+		txDay := dayNumber(transaction.TransactionTime)
+
+		start := 0
+		if m.toleranceDays >= 0 {
+			start = sort.Search(len(sorted), func(j int) bool {
+				return dayNumber(sorted[j].Date) >= txDay-m.toleranceDays
+			})
+		}
+
+		// Several statements usually share a currency, convert once per currency
+		converted := make(map[string]*model.Money)
+		for j := start; j < len(sorted); j++ {
+			distance := dayNumber(sorted[j].Date) - txDay
+			if m.toleranceDays >= 0 && distance > m.toleranceDays {
+				break
+			}
+
+			bankAmount := sorted[j].Amount
+			currency := bankAmount.Currency()
+			if currency == amount.Currency() {
+				continue
+			}
+
+			inBankCurrency, ok := converted[currency]
+			if !ok {
+				if value, err := m.rates.Convert(amount, currency, transaction.TransactionTime); err == nil {
+					inBankCurrency = &value
+				}
+				converted[currency] = inBankCurrency
+			}
+			if inBankCurrency == nil || inBankCurrency.Sign() != bankAmount.Sign() {
+				continue
+			}
+
+			difference := abs(inBankCurrency.Minor() - bankAmount.Minor())
+			if !withinBasisPoints(difference, abs(bankAmount.Minor()), m.toleranceBps) {
+				continue
+			}
+
+			candidates = append(candidates, fxPairCandidate{
+				txIndex:    i,
+				bankIndex:  j,
+				distance:   max(distance, -distance),
+				difference: difference,
+			})
+		}
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if candidates[i].distance != candidates[j].distance {
+			return candidates[i].distance < candidates[j].distance
+		}
+		return candidates[i].difference < candidates[j].difference
+	})
+
+	usedTx := make([]bool, len(transactions))
+	usedBank := make([]bool, len(sorted))
+	var result MatchResult
+	for _, candidate := range candidates {
+		if usedTx[candidate.txIndex] || usedBank[candidate.bankIndex] {
+			continue
+		}
+		usedTx[candidate.txIndex] = true
+		usedBank[candidate.bankIndex] = true
+		result.Matches = append(result.Matches, newOneToOneMatch(transactions[candidate.txIndex], sorted[candidate.bankIndex]))
+	}
+
+	for i, transaction := range transactions {
+		if !usedTx[i] {
+			result.UnmatchedInternal = append(result.UnmatchedInternal, transaction)
+		}
+	}
+
+	for j, statement := range sorted {
+		if !usedBank[j] {
+			result.UnmatchedBank = append(result.UnmatchedBank, statement)
+		}
+	}
+
+	return result
+}
+
+// withinBasisPoints reports whether difference is at most toleranceBps basis
+// points of amount. Products are compared in 128 bits so large amounts cannot
+// overflow.
+func withinBasisPoints(difference, amount int64, toleranceBps int) bool {
+	if toleranceBps < 0 {
+		return false
+	}
+	diffHi, diffLo := bits.Mul64(uint64(difference), basisPointsPerUnit)
+	allowedHi, allowedLo := bits.Mul64(uint64(amount), uint64(toleranceBps))
+	return diffHi < allowedHi || (diffHi == allowedHi && diffLo <= allowedLo)
+}
@@ -0,0 +1,135 @@
+package usecase
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/aferryc/yars/model"
+)
+
+const (
+	ReferenceNormalizationNone            = "none"
+	ReferenceNormalizationCaseInsensitive = "case_insensitive"
+	ReferenceNormalizationAlphanumeric    = "alphanumeric"
+)
+
+// minContainedIDLength is the shortest normalized transaction ID that may be
+// matched as a substring of a bank reference. Shorter IDs only match when the
+// reference is exactly equal, otherwise IDs like "1" would match everything.
+const minContainedIDLength = 4
+
+// ReferenceMatcher pairs bank statements whose reference equals or contains
+// an internal transaction ID, whatever their amounts.
+type ReferenceMatcher struct {
+	normalize func(string) string
+}
+
+// NewReferenceMatcher creates a ReferenceMatcher using the given normalization mode
+func NewReferenceMatcher(normalization string) *ReferenceMatcher {
+	return &ReferenceMatcher{
+		normalize: referenceNormalizer(normalization),
+	}
+}
+
+func (m *ReferenceMatcher) Name() string {
+	return MatcherExactReference
+}
+
+// Match prefers an exact reference match, otherwise the longest contained ID
+// wins. Amounts may differ, e.g. by a bank fee, the chain recording the
+// difference on the match. Each record is paired at most once.
+func (m *ReferenceMatcher) Match(transactions []model.Transaction, statements []model.BankStatement) MatchResult {
+	idIndex := make(map[string][]int)
+	idLengths := make(map[int]bool)
+	for i, transaction := range transactions {
+		id := m.normalize(transaction.ID)
+		if id == "" {
+			continue
+		}
+		idIndex[id] = append(idIndex[id], i)
+		idLengths[len(id)] = true
+	}
+
+	// Check longer IDs first so "TX12" is not paired through "TX1"
+	lengths := make([]int, 0, len(idLengths))
+	for length := range idLengths {
+		if length >= minContainedIDLength {
+			lengths = append(lengths, length)
+		}
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(lengths)))
+
+	usedTx := make([]bool, len(transactions))
+	takeTransaction := func(id string) (int, bool) {
+		for _, i := range idIndex[id] {
+			if !usedTx[i] {
+				usedTx[i] = true
+				return i, true
+			}
+		}
+		return 0, false
+	}
+
+	var result MatchResult
+	for _, statement := range statements {
+		reference := m.normalize(statement.Reference)
+		if reference != "" {
+			if i, ok := takeContainedTransaction(reference, lengths, takeTransaction); ok {
+				result.Matches = append(result.Matches, model.Match{
+					Type:           model.MatchOneToOne,
+					Transactions:   []model.Transaction{transactions[i]},
+					BankStatements: []model.BankStatement{statement},
+				})
+				continue
+			}
+		}
+		result.UnmatchedBank = append(result.UnmatchedBank, statement)
+	}
+
+	for i, transaction := range transactions {
+		if !usedTx[i] {
+			result.UnmatchedInternal = append(result.UnmatchedInternal, transaction)
+		}
+	}
+
+	return result
+}
+
+// referenceNormalizer returns the normalization function for the given mode.
+// Unknown modes fall back to trimming whitespace only.
+func referenceNormalizer(mode string) func(string) string {
+	switch mode {
+	case ReferenceNormalizationCaseInsensitive:
+		return func(s string) string {
+			return strings.ToUpper(strings.TrimSpace(s))
+		}
+	case ReferenceNormalizationAlphanumeric:
+		return func(s string) string {
+			return strings.Map(func(r rune) rune {
+				if unicode.IsLetter(r) || unicode.IsDigit(r) {
+					return unicode.ToUpper(r)
+				}
+				return -1
+			}, s)
+		}
+	default:
+		return strings.TrimSpace
+	}
+}
+
+// takeContainedTransaction tries the whole reference first and then every
+// substring with one of the given lengths, stopping at the first ID taken.
+func takeContainedTransaction(reference string, lengths []int, take func(string) (int, bool)) (int, bool) {
+	if i, ok := take(reference); ok {
+		return i, true
+	}
+	for _, length := range lengths {
+		for start := 0; start+length <= len(reference); start++ {
+			if i, ok := take(reference[start : start+length]); ok {
+				return i, true
+			}
+		}
+	}
+	return 0, false
+}
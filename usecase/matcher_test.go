@@ -0,0 +1,197 @@
+package usecase_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aferryc/yars/internal/config"
+	"github.com/aferryc/yars/model"
+	mockrepository "github.com/aferryc/yars/repository/mocks"
+	"github.com/aferryc/yars/usecase"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+// descriptionMatcher is a bank-specific matcher pairing records by description
+type descriptionMatcher struct{}
+
+func (m descriptionMatcher) Name() string {
+	return "description"
+}
+
+func (m descriptionMatcher) Match(transactions []model.Transaction, statements []model.BankStatement) usecase.MatchResult {
+	var result usecase.MatchResult
+	used := make(map[int]bool)
+	for _, tx := range transactions {
+		matched := false
+		for j, stmt := range statements {
+			if !used[j] && tx.Description != "" && tx.Description == stmt.Reference {
+				used[j] = true
+				matched = true
+				result.Matches = append(result.Matches, model.Match{
+					Type:           model.MatchOneToOne,
+					Transactions:   []model.Transaction{tx},
+					BankStatements: []model.BankStatement{stmt},
+				})
+				break
+			}
+		}
+		if !matched {
+			result.UnmatchedInternal = append(result.UnmatchedInternal, tx)
+		}
+	}
+	for j, stmt := range statements {
+		if !used[j] {
+			result.UnmatchedBank = append(result.UnmatchedBank, stmt)
+		}
+	}
+	return result
+}
+
+func TestBuildMatcherChain(t *testing.T) {
+	t.Run("Default chain", func(t *testing.T) {
+		matchers, err := usecase.BuildMatcherChain(config.ReconciliationConfig{})
+		require.NoError(t, err)
+		require.Len(t, matchers, 3)
+		assert.Equal(t, usecase.MatcherExactReference, matchers[0].Name())
+		assert.Equal(t, usecase.MatcherAmountDate, matchers[1].Name())
+		assert.Equal(t, usecase.MatcherFXAmountDate, matchers[2].Name())
+	})
+
+	t.Run("Configured chain keeps its order", func(t *testing.T) {
+		matchers, err := usecase.BuildMatcherChain(config.ReconciliationConfig{
+			MatcherChain: []string{usecase.MatcherAggregate, " " + usecase.MatcherAmountDate},
+		})
+		require.NoError(t, err)
+		require.Len(t, matchers, 2)
+		assert.Equal(t, usecase.MatcherAggregate, matchers[0].Name())
+		assert.Equal(t, usecase.MatcherAmountDate, matchers[1].Name())
+	})
+
+	t.Run("Unknown matcher", func(t *testing.T) {
+		_, err := usecase.BuildMatcherChain(config.ReconciliationConfig{
+			MatcherChain: []string{"does_not_exist"},
+		})
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "does_not_exist")
+	})
+}
+
+func TestReconciliationUsecase_CustomMatcherChain(t *testing.T) {
+	// Setup
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	usecase.RegisterMatcher("description", func(cfg config.ReconciliationConfig) usecase.Matcher {
+		return descriptionMatcher{}
+	})
+
+	bankRepo := mockrepository.NewMockBankStatementRepository(ctrl)
+	internalRepo := mockrepository.NewMockInternalTransactionRepository(ctrl)
+	reconRepo := mockrepository.NewMockReconResultRepository(ctrl)
+	fxRepo := mockrepository.NewMockFXRateRepository(ctrl)
+	cfg := &config.Config{
+		App: config.AppConfig{
+			Reconciliation: config.ReconciliationConfig{
+				MatcherChain: []string{"description", usecase.MatcherAmountDate},
+			},
+		},
+	}
+	uc := newReconciliationUsecase(t, cfg, internalRepo, bankRepo, reconRepo, fxRepo)
+
+	startTime := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	endTime := time.Date(2023, 1, 31, 23, 59, 59, 0, time.UTC)
+	cTime := time.Date(2023, 1, 15, 12, 0, 0, 0, time.UTC)
+
+	// Test data - the description matcher pairs tx-1 even though the amount
+	// differs, so bs-1 is left over for the amount matcher to pair with tx-2
+	internalTransactions := []model.Transaction{
+		{ID: "tx-1", Amount: money("99.00"), TransactionTime: cTime, Type: "CREDIT", Description: "INVOICE 7"},
+		{ID: "tx-2", Amount: money("100.00"), TransactionTime: cTime, Type: "CREDIT"},
+	}
+	bankStatements := []model.BankStatement{
+		{ID: "bs-1", Amount: money("100.00"), Date: cTime},
+		{ID: "bs-2", Amount: money("100.00"), Date: cTime, Reference: "INVOICE 7"},
+	}
+
+	bankRepo.EXPECT().FetchAll(gomock.Any(), gomock.Any(), startTime, endTime).Return(model.BankStatementList{
+		BankStatements: bankStatements,
+	}, nil)
+	internalRepo.EXPECT().FetchAll(gomock.Any(), gomock.Any(), startTime, endTime).Return(model.TransactionList{
+		Transactions: internalTransactions,
+	}, nil)
+
+	var stored model.ReconciliationSummary
+	reconRepo.EXPECT().StoreSummary(gomock.Any(), gomock.Any(), startTime, endTime).
+		DoAndReturn(func(_ any, summary model.ReconciliationSummary, _, _ time.Time) error {
+			stored = summary
+			return nil
+		})
+
+	err := uc.ReconcileTransactions(context.Background(), model.ReconciliationEvent{
+		TaskID:    "test-task-chain",
+		StartDate: startTime,
+		EndDate:   endTime,
+	})
+	require.NoError(t, err)
+
+	require.Len(t, stored.Matches, 2)
+	assert.Equal(t, "description", stored.Matches[0].Rule)
+	assert.Equal(t, "tx-1", stored.Matches[0].Transactions[0].ID)
+	assert.Equal(t, "bs-2", stored.Matches[0].BankStatements[0].ID)
+	assert.Equal(t, usecase.MatcherAmountDate, stored.Matches[1].Rule)
+	assert.Equal(t, "tx-2", stored.Matches[1].Transactions[0].ID)
+	assert.Equal(t, "bs-1", stored.Matches[1].BankStatements[0].ID)
+	assert.Empty(t, stored.UnmatchedInternal)
+	assert.Empty(t, stored.UnmatchedBank)
+}
+
+func TestAmountDateMatcher_Tolerance(t *testing.T) {
+	day := func(d int) time.Time {
+		return time.Date(2023, 1, d, 10, 0, 0, 0, time.UTC)
+	}
+	transactions := []model.Transaction{
+		{ID: "jan-03", Amount: money("100.00"), TransactionTime: day(3), Type: "CREDIT"},
+		{ID: "jan-05", Amount: money("100.00"), TransactionTime: day(5), Type: "CREDIT"},
+		{ID: "jan-20", Amount: money("100.00"), TransactionTime: day(20), Type: "CREDIT"},
+	}
+	statements := []model.BankStatement{
+		{ID: "bs-jan-05", Amount: money("100.00"), Date: time.Date(2023, 1, 5, 0, 0, 0, 0, time.UTC)},
+		{ID: "bs-jan-04", Amount: money("100.00"), Date: time.Date(2023, 1, 4, 0, 0, 0, 0, time.UTC)},
+		{ID: "bs-jan-28", Amount: money("100.00"), Date: time.Date(2023, 1, 28, 0, 0, 0, 0, time.UTC)},
+	}
+
+	tests := []struct {
+		name          string
+		toleranceDays int
+		expected      map[string]string
+	}{
+		{
+			name:          "Zero only pairs records of the same day",
+			toleranceDays: 0,
+			expected:      map[string]string{"jan-05": "bs-jan-05"},
+		},
+		{
+			name:          "Disabled pairs records whatever their dates",
+			toleranceDays: config.DateToleranceDisabled,
+			expected:      map[string]string{"jan-03": "bs-jan-05", "jan-05": "bs-jan-04", "jan-20": "bs-jan-28"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Execute
+			result := usecase.NewAmountDateMatcher(tt.toleranceDays).Match(transactions, statements)
+
+			// Assert
+			matched := make(map[string]string)
+			for _, match := range result.Matches {
+				matched[match.Transactions[0].ID] = match.BankStatements[0].ID
+			}
+			assert.Equal(t, tt.expected, matched)
+			assert.Len(t, result.UnmatchedInternal, len(transactions)-len(tt.expected))
+			assert.Len(t, result.UnmatchedBank, len(statements)-len(tt.expected))
+		})
+	}
+}
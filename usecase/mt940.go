@@ -0,0 +1,335 @@
+package usecase
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/aferryc/yars/model"
+	"github.com/pkg/errors"
+)
+
+// mt940Tag matches the start of a field, such as ":61:" or ":60F:"
+var mt940Tag = regexp.MustCompile(`^:([0-9]{2}[A-Z]?):`)
+
+// MT940Reader streams the entries of a SWIFT MT940 file. The file may hold
+// several statements, with or without the SWIFT block headers, and the entries
+// of a statement are only returned once its closing balance is read.
+type MT940Reader struct {
+	scanner *bufio.Scanner
+	line    int
+	// peeked is a line read ahead to find the end of a field
+	peeked    string
+	hasPeeked bool
+	// pending is the :20: field starting the next statement
+	pending *mt940Field
+
+	statements []model.Statement
+	// queue holds the entries of the last statement not returned yet
+	queue []model.BankStatement
+	// entries counts the entries read so far, numbering the entry IDs
+	entries int
+}
+
+type mt940Field struct {
+	tag   string
+	value string
+	line  int
+}
+
+// NewMT940Reader creates a new MT940Reader reading r
+func NewMT940Reader(r io.Reader) *MT940Reader {
+	return &MT940Reader{
+		scanner: bufio.NewScanner(r),
+	}
+}
+
+// Next returns the next entry, or io.EOF after the last one. Each :61: line
+// becomes an entry, with the :86: line following it as narrative. Entries are
+// numbered across the file and take the ID <statement reference>/<number>.
+func (r *MT940Reader) Next() (model.BankStatement, error) {
+	for len(r.queue) == 0 {
+		statement, err := r.nextStatement()
+		if err != nil {
+			return model.BankStatement{}, err
+		}
+		r.queue = statement.Entries
+		// Only the balances are kept, the entries are returned by Next
+		statement.Entries = nil
+		r.statements = append(r.statements, statement)
+	}
+
+	entry := r.queue[0]
+	r.queue = r.queue[1:]
+	return entry, nil
+}
+
+// Statements returns the statements read so far, without their entries
+func (r *MT940Reader) Statements() []model.Statement {
+	return r.statements
+}
+
+// nextStatement reads the next statement with its entries, or returns io.EOF
+// after the last one
+func (r *MT940Reader) nextStatement() (model.Statement, error) {
+	var statement model.Statement
+	var currency string
+	var hasOpening, hasClosing bool
+	var previous string
+
+	field, err := r.nextField()
+	if err != nil {
+		return model.Statement{}, err
+	}
+	if field.tag != "20" {
+		return model.Statement{}, errors.Errorf("[MT940Reader] line %d: statement starts with :%s: instead of :20:", field.line, field.tag)
+	}
+
+	for {
+		switch field.tag {
+		case "20":
+			statement.Reference = strings.TrimSpace(field.value)
+		case "25":
+			statement.Account = strings.TrimSpace(field.value)
+		case "28", "28C":
+			statement.Number = strings.TrimSpace(field.value)
+		case "60F", "60M":
+			statement.OpeningBalance, err = parseMT940Balance(field.value)
+			if err != nil {
+				return model.Statement{}, errors.Wrapf(err, "[MT940Reader] line %d: invalid opening balance", field.line)
+			}
+			currency = statement.OpeningBalance.Amount.Currency()
+			hasOpening = true
+		case "61":
+			if !hasOpening {
+				return model.Statement{}, errors.Errorf("[MT940Reader] line %d: entry before the opening balance", field.line)
+			}
+			entry, err := parseMT940Entry(field.value, currency)
+			if err != nil {
+				return model.Statement{}, errors.Wrapf(err, "[MT940Reader] line %d: invalid entry", field.line)
+			}
+			r.entries++
+			entry.ID = fmt.Sprintf("%s/%d", statement.Reference, r.entries)
+			statement.Entries = append(statement.Entries, entry)
+		case "86":
+			// Information following the closing balance is about the whole
+			// statement and has no entry to go with
+			if previous == "61" {
+				statement.Entries[len(statement.Entries)-1].Narrative = strings.TrimSpace(field.value)
+			}
+		case "62F", "62M":
+			statement.ClosingBalance, err = parseMT940Balance(field.value)
+			if err != nil {
+				return model.Statement{}, errors.Wrapf(err, "[MT940Reader] line %d: invalid closing balance", field.line)
+			}
+			hasClosing = true
+		}
+		previous = field.tag
+
+		field, err = r.nextField()
+		if err == io.EOF || (err == nil && field.tag == "20") {
+			if err == nil {
+				r.pending = &field
+			}
+			break
+		}
+		if err != nil {
+			return model.Statement{}, err
+		}
+	}
+
+	if !hasOpening || !hasClosing {
+		return model.Statement{}, errors.Errorf("[MT940Reader] statement %s lacks its opening or closing balance", statement.Reference)
+	}
+	return statement, nil
+}
+
+// nextField returns the next field with its continuation lines, skipping the
+// SWIFT block headers and trailers
+func (r *MT940Reader) nextField() (mt940Field, error) {
+	if r.pending != nil {
+		field := *r.pending
+		r.pending = nil
+		return field, nil
+	}
+
+	var field mt940Field
+	for {
+		line, ok := r.readLine()
+		if !ok {
+			break
+		}
+		match := mt940Tag.FindStringSubmatch(line)
+		if match == nil {
+			// Continuation of the current field, lines before the first
+			// field belong to the headers
+			if field.tag != "" {
+				field.value += "\n" + line
+			}
+			continue
+		}
+		if field.tag != "" {
+			r.unreadLine(line)
+			return field, nil
+		}
+		field = mt940Field{tag: match[1], value: line[len(match[0]):], line: r.line}
+	}
+
+	if err := r.scanner.Err(); err != nil {
+		return mt940Field{}, errors.Wrap(err, "[MT940Reader] error reading file")
+	}
+	if field.tag == "" {
+		return mt940Field{}, io.EOF
+	}
+	return field, nil
+}
+
+// readLine returns the next line holding data
+func (r *MT940Reader) readLine() (string, bool) {
+	if r.hasPeeked {
+		r.hasPeeked = false
+		return r.peeked, true
+	}
+	for r.scanner.Scan() {
+		r.line++
+		line := strings.TrimRight(r.scanner.Text(), " \r")
+		if r.line == 1 {
+			line = strings.TrimPrefix(line, "\ufeff")
+		}
+		// Skip blank lines, the {1:...}{2:...}{4: block headers and the
+		// -} trailer ending each message
+		if line == "" || strings.HasPrefix(line, "{") || line == "-" || strings.HasPrefix(line, "-}") {
+			continue
+		}
+		return line, true
+	}
+	return "", false
+}
+
+func (r *MT940Reader) unreadLine(line string) {
+	r.peeked = line
+	r.hasPeeked = true
+}
+
+// parseMT940Balance parses a balance such as C230115EUR1234,56, a credit of
+// 1234.56 EUR on 15 January 2023
+func parseMT940Balance(value string) (model.Balance, error) {
+	value = strings.TrimSpace(value)
+	if len(value) < 11 {
+		return model.Balance{}, errors.Errorf("balance %q is too short", value)
+	}
+
+	mark := value[0]
+	if mark != 'C' && mark != 'D' {
+		return model.Balance{}, errors.Errorf("invalid debit/credit mark %q", mark)
+	}
+
+	date, err := time.Parse("060102", value[1:7])
+	if err != nil {
+		return model.Balance{}, errors.Wrap(err, "invalid date")
+	}
+
+	currency, err := parseCurrency(value[7:10])
+	if err != nil {
+		return model.Balance{}, err
+	}
+
+	amount, err := parseMT940Amount(value[10:], currency)
+	if err != nil {
+		return model.Balance{}, err
+	}
+	if mark == 'D' {
+		amount = amount.Neg()
+	}
+
+	return model.Balance{
+		Date:   date,
+		Amount: amount,
+	}, nil
+}
+
+// parseMT940Entry parses a :61: field such as
+// 2301150115D100,50NTRFINV42//BANKREF1, a debit of 100.50 booked on 15 January
+// 2023 with INV42 as reference. Supplementary details on the second line are
+// kept as narrative until a :86: field replaces them.
+func parseMT940Entry(value, currency string) (model.BankStatement, error) {
+	line, details, _ := strings.Cut(value, "\n")
+	if len(line) < 6 {
+		return model.BankStatement{}, errors.Errorf("entry %q is too short", line)
+	}
+
+	date, err := time.Parse("060102", line[:6])
+	if err != nil {
+		return model.BankStatement{}, errors.Wrap(err, "invalid value date")
+	}
+	rest := line[6:]
+
+	// Optional entry date, MMDD
+	if len(rest) >= 4 && model.IsDigits(rest[:4]) {
+		rest = rest[4:]
+	}
+
+	// A reversal of a credit is a debit and a reversal of a debit a credit
+	var negative bool
+	switch {
+	case strings.HasPrefix(rest, "RC"):
+		negative, rest = true, rest[2:]
+	case strings.HasPrefix(rest, "RD"):
+		negative, rest = false, rest[2:]
+	case strings.HasPrefix(rest, "C"):
+		negative, rest = false, rest[1:]
+	case strings.HasPrefix(rest, "D"):
+		negative, rest = true, rest[1:]
+	default:
+		return model.BankStatement{}, errors.Errorf("invalid debit/credit mark in %q", line)
+	}
+
+	// Optional funds code, the last letter of the currency code
+	if rest != "" && rest[0] >= 'A' && rest[0] <= 'Z' {
+		rest = rest[1:]
+	}
+
+	end := strings.IndexFunc(rest, func(c rune) bool { return (c < '0' || c > '9') && c != ',' })
+	if end < 0 {
+		end = len(rest)
+	}
+	amount, err := parseMT940Amount(rest[:end], currency)
+	if err != nil {
+		return model.BankStatement{}, err
+	}
+	if negative {
+		amount = amount.Neg()
+	}
+	rest = rest[end:]
+
+	// Transaction type identification code, such as NTRF
+	if len(rest) < 4 {
+		return model.BankStatement{}, errors.Errorf("missing transaction type in %q", line)
+	}
+	rest = rest[4:]
+
+	ownerReference, bankReference, _ := strings.Cut(rest, "//")
+	reference := strings.TrimSpace(ownerReference)
+	if reference == "" || reference == "NONREF" {
+		reference = strings.TrimSpace(bankReference)
+	}
+
+	return model.BankStatement{
+		Amount:    amount,
+		Date:      date,
+		Reference: reference,
+		Narrative: strings.TrimSpace(details),
+	}, nil
+}
+
+// parseMT940Amount parses an amount written with a decimal comma, such as
+// 1234,56 or 100,
+func parseMT940Amount(value, currency string) (model.Money, error) {
+	if !strings.Contains(value, ",") {
+		return model.Money{}, errors.Errorf("amount %q has no decimal comma", value)
+	}
+	return model.ParseMoney(strings.Replace(value, ",", ".", 1), currency)
+}
@@ -0,0 +1,170 @@
+package usecase_test
+
+import (
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aferryc/yars/model"
+	"github.com/aferryc/yars/usecase"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const mt940File = `:20:STMT-1
+:25:NL91ABNA0417164300
+:28C:00042/1
+:60F:C230114EUR1234,56
+:61:2301150115C500,25NTRFTX123//BANKREF1
+:86:PAYMENT TX123 INVOICE 42
+ACME CORP
+:61:230116D1000,NMSCNONREF//FEE-0001
+OVERDRAFT
+:61:230116RD20,00NCHGTX456
+:86:REVERSED CHARGE
+:62F:C230116EUR754,81
+:86:END OF DAY STATEMENT
+-
+:20:STMT-2
+:25:NL91ABNA0417164300
+:28C:00043/1
+:60F:C230116EUR754,81
+:62F:D230117EUR0,19
+`
+
+func TestMT940Reader(t *testing.T) {
+	t.Run("Reads the entries and balances of every statement", func(t *testing.T) {
+		// Setup
+		reader := usecase.NewMT940Reader(strings.NewReader(mt940File))
+
+		// Execute
+		var rows []model.BankStatement
+		for {
+			row, err := reader.Next()
+			if err == io.EOF {
+				break
+			}
+			require.NoError(t, err)
+			rows = append(rows, row)
+		}
+
+		// Assert
+		assert.Equal(t, []model.BankStatement{
+			{
+				ID:        "STMT-1/1",
+				Amount:    model.MustParseMoney("500.25", "EUR"),
+				Date:      time.Date(2023, 1, 15, 0, 0, 0, 0, time.UTC),
+				Reference: "TX123",
+				Narrative: "PAYMENT TX123 INVOICE 42\nACME CORP",
+			},
+			{
+				ID:        "STMT-1/2",
+				Amount:    model.MustParseMoney("-1000.00", "EUR"),
+				Date:      time.Date(2023, 1, 16, 0, 0, 0, 0, time.UTC),
+				Reference: "FEE-0001",
+				Narrative: "OVERDRAFT",
+			},
+			{
+				// A reversed debit is a credit
+				ID:        "STMT-1/3",
+				Amount:    model.MustParseMoney("20.00", "EUR"),
+				Date:      time.Date(2023, 1, 16, 0, 0, 0, 0, time.UTC),
+				Reference: "TX456",
+				Narrative: "REVERSED CHARGE",
+			},
+		}, rows)
+
+		// The second statement has no entries but its balances are kept
+		assert.Equal(t, []model.Statement{
+			{
+				Reference: "STMT-1",
+				Account:   "NL91ABNA0417164300",
+				Number:    "00042/1",
+				OpeningBalance: model.Balance{
+					Date:   time.Date(2023, 1, 14, 0, 0, 0, 0, time.UTC),
+					Amount: model.MustParseMoney("1234.56", "EUR"),
+				},
+				ClosingBalance: model.Balance{
+					Date:   time.Date(2023, 1, 16, 0, 0, 0, 0, time.UTC),
+					Amount: model.MustParseMoney("754.81", "EUR"),
+				},
+			},
+			{
+				Reference: "STMT-2",
+				Account:   "NL91ABNA0417164300",
+				Number:    "00043/1",
+				OpeningBalance: model.Balance{
+					Date:   time.Date(2023, 1, 16, 0, 0, 0, 0, time.UTC),
+					Amount: model.MustParseMoney("754.81", "EUR"),
+				},
+				ClosingBalance: model.Balance{
+					Date:   time.Date(2023, 1, 17, 0, 0, 0, 0, time.UTC),
+					Amount: model.MustParseMoney("-0.19", "EUR"),
+				},
+			},
+		}, reader.Statements())
+	})
+
+	t.Run("Skips the SWIFT blocks", func(t *testing.T) {
+		// Setup
+		content := "{1:F01BANKDEFFAXXX0000000000}{2:O940}{4:\r\n" +
+			":20:STMT-1\r\n:25:ACCOUNT\r\n:60F:C230114USD0,\r\n" +
+			":61:230115C10,NTRFTX1\r\n:62F:C230115USD10,\r\n-}{5:{CHK:123}}\r\n"
+		reader := usecase.NewMT940Reader(strings.NewReader(content))
+
+		// Execute
+		row, err := reader.Next()
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, model.MustParseMoney("10", "USD"), row.Amount)
+		_, err = reader.Next()
+		assert.Equal(t, io.EOF, err)
+		assert.Len(t, reader.Statements(), 1)
+	})
+
+	invalid := []struct {
+		name    string
+		content string
+		errMsg  string
+	}{
+		{
+			name:    "Statement without closing balance",
+			content: ":20:STMT-1\n:25:ACCOUNT\n:60F:C230114EUR0,\n:61:230115C10,NTRFTX1\n",
+			errMsg:  "lacks its opening or closing balance",
+		},
+		{
+			name:    "Entry before the opening balance",
+			content: ":20:STMT-1\n:61:230115C10,NTRFTX1\n",
+			errMsg:  "line 2: entry before the opening balance",
+		},
+		{
+			name:    "Invalid debit/credit mark",
+			content: ":20:STMT-1\n:60F:C230114EUR0,\n:61:230115X10,NTRFTX1\n:62F:C230115EUR10,\n",
+			errMsg:  "line 3: invalid entry",
+		},
+		{
+			name:    "Amount without decimal comma",
+			content: ":20:STMT-1\n:60F:C230114EUR0,\n:61:230115C10NTRFTX1\n:62F:C230115EUR10,\n",
+			errMsg:  "has no decimal comma",
+		},
+		{
+			name:    "File not starting with a statement",
+			content: ":25:ACCOUNT\n",
+			errMsg:  "instead of :20:",
+		},
+	}
+	for _, tt := range invalid {
+		t.Run(tt.name, func(t *testing.T) {
+			// Setup
+			reader := usecase.NewMT940Reader(strings.NewReader(tt.content))
+
+			// Execute
+			_, err := reader.Next()
+
+			// Assert
+			assert.ErrorContains(t, err, tt.errMsg)
+		})
+	}
+}
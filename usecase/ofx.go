@@ -0,0 +1,215 @@
+package usecase
+
+import (
+	"bufio"
+	"html"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/aferryc/yars/model"
+	"github.com/pkg/errors"
+)
+
+// OFXReader streams the transactions of an OFX or QFX statement download,
+// whether SGML (version 1) or XML (version 2). Both are read as a sequence of
+// tags, the value of an element being the text following its start tag, so the
+// end tags SGML leaves out are not needed.
+type OFXReader struct {
+	reader *bufio.Reader
+	// element is the start tag whose value is awaited
+	element string
+	// currency is the default currency of the statement being read
+	currency string
+	account  string
+	// transaction is the STMTTRN being read
+	transaction *ofxTransaction
+	inCurrency  bool
+	// entries counts the transactions read so far, locating errors
+	entries int
+}
+
+type ofxTransaction struct {
+	fitID    string
+	amount   string
+	posted   string
+	name     string
+	memo     string
+	currency string
+}
+
+// NewOFXReader creates a new OFXReader reading r
+func NewOFXReader(r io.Reader) *OFXReader {
+	return &OFXReader{
+		reader: bufio.NewReader(r),
+	}
+}
+
+// Next returns the next transaction, or io.EOF after the last one. FITID, the
+// bank's unique ID of the transaction, becomes the reference, NAME the
+// counterparty and MEMO the narrative. Rows take the ID <account>/<FITID>, so
+// transactions downloaded twice keep the same row.
+func (r *OFXReader) Next() (model.BankStatement, error) {
+	for {
+		text, err := r.reader.ReadString('<')
+		if err == io.EOF {
+			if r.transaction != nil {
+				return model.BankStatement{}, errors.New("[OFXReader] file ends inside a transaction")
+			}
+			return model.BankStatement{}, io.EOF
+		}
+		if err != nil {
+			return model.BankStatement{}, errors.Wrap(err, "[OFXReader] error reading file")
+		}
+		if r.element != "" {
+			r.setValue(r.element, ofxText(text[:len(text)-1]))
+			r.element = ""
+		}
+
+		tag, err := r.reader.ReadString('>')
+		if err != nil {
+			return model.BankStatement{}, errors.Wrap(err, "[OFXReader] unterminated tag")
+		}
+		tag = strings.ToUpper(strings.TrimSpace(tag[:len(tag)-1]))
+		// Skip the XML declaration, the OFX processing instruction and comments
+		if strings.HasPrefix(tag, "?") || strings.HasPrefix(tag, "!") {
+			continue
+		}
+
+		switch tag {
+		case "STMTRS", "CCSTMTRS":
+			r.currency, r.account = "", ""
+		case "STMTTRN":
+			r.entries++
+			r.transaction = &ofxTransaction{}
+		case "CURRENCY":
+			r.inCurrency = true
+		case "/CURRENCY":
+			r.inCurrency = false
+		case "/STMTTRN":
+			if r.transaction == nil {
+				return model.BankStatement{}, errors.New("[OFXReader] STMTTRN closed before being opened")
+			}
+			row, err := r.row(*r.transaction)
+			r.transaction = nil
+			if err != nil {
+				return model.BankStatement{}, errors.Wrapf(err, "[OFXReader] transaction %d", r.entries)
+			}
+			return row, nil
+		default:
+			if !strings.HasPrefix(tag, "/") {
+				r.element = tag
+			}
+		}
+	}
+}
+
+// Statements returns no statements, OFX only reporting the balance at the end
+// of the download
+func (r *OFXReader) Statements() []model.Statement {
+	return nil
+}
+
+// setValue keeps the value of the elements read, the others being ignored
+func (r *OFXReader) setValue(element, value string) {
+	if value == "" {
+		return
+	}
+	if r.transaction == nil {
+		switch element {
+		case "CURDEF":
+			r.currency = value
+		case "ACCTID":
+			r.account = value
+		}
+		return
+	}
+
+	switch element {
+	case "FITID":
+		r.transaction.fitID = value
+	case "TRNAMT":
+		r.transaction.amount = value
+	case "DTPOSTED":
+		r.transaction.posted = value
+	case "NAME":
+		r.transaction.name = value
+	case "MEMO":
+		r.transaction.memo = value
+	case "CURSYM":
+		// The amount is in the currency of a CURRENCY aggregate, while an
+		// ORIGCURRENCY one only tells the currency it was converted from
+		if r.inCurrency {
+			r.transaction.currency = value
+		}
+	}
+}
+
+// row maps a transaction to a bank statement row
+func (r *OFXReader) row(transaction ofxTransaction) (model.BankStatement, error) {
+	if transaction.fitID == "" {
+		return model.BankStatement{}, errors.New("missing FITID")
+	}
+
+	currency := transaction.currency
+	if currency == "" {
+		currency = r.currency
+	}
+	currency, err := parseCurrency(currency)
+	if err != nil {
+		return model.BankStatement{}, err
+	}
+
+	// Some banks write the amount with a decimal comma
+	value := transaction.amount
+	if !strings.Contains(value, ".") {
+		value = strings.Replace(value, ",", ".", 1)
+	}
+	amount, err := model.ParseMoney(value, currency)
+	if err != nil {
+		return model.BankStatement{}, errors.Wrap(err, "invalid TRNAMT")
+	}
+
+	date, err := parseOFXDate(transaction.posted)
+	if err != nil {
+		return model.BankStatement{}, errors.Wrap(err, "invalid DTPOSTED")
+	}
+
+	id := transaction.fitID
+	if r.account != "" {
+		id = r.account + "/" + transaction.fitID
+	}
+
+	return model.BankStatement{
+		ID:           id,
+		Amount:       amount,
+		Date:         date,
+		Reference:    transaction.fitID,
+		Narrative:    transaction.memo,
+		Counterparty: transaction.name,
+	}, nil
+}
+
+// parseOFXDate parses the date of a datetime such as 20230115120000.000[-5:EST],
+// keeping the local date
+func parseOFXDate(value string) (time.Time, error) {
+	if len(value) < 8 {
+		return time.Time{}, errors.Errorf("invalid date %q", value)
+	}
+	return time.Parse("20060102", value[:8])
+}
+
+// ofxText decodes the text of an element. Version 1 files are often encoded
+// in Latin-1 rather than UTF-8, their bytes then being read as such.
+func ofxText(text string) string {
+	text = strings.TrimSpace(text)
+	if !utf8.ValidString(text) {
+		runes := make([]rune, len(text))
+		for i := 0; i < len(text); i++ {
+			runes[i] = rune(text[i])
+		}
+		text = string(runes)
+	}
+	return html.UnescapeString(text)
+}
@@ -0,0 +1,158 @@
+package usecase_test
+
+import (
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aferryc/yars/model"
+	"github.com/aferryc/yars/usecase"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const ofxSGMLFile = "OFXHEADER:100\r\nDATA:OFXSGML\r\nVERSION:102\r\nENCODING:USASCII\r\nCHARSET:1252\r\n\r\n" +
+	"<OFX><SIGNONMSGSRSV1><SONRS><STATUS><CODE>0<SEVERITY>INFO</STATUS><DTSERVER>20230116</SONRS></SIGNONMSGSRSV1>\r\n" +
+	"<BANKMSGSRSV1><STMTTRNRS><TRNUID>1<STMTRS><CURDEF>USD\r\n" +
+	"<BANKACCTFROM><BANKID>121000248<ACCTID>0975312468<ACCTTYPE>CHECKING</BANKACCTFROM>\r\n" +
+	"<BANKTRANLIST><DTSTART>20230114<DTEND>20230116\r\n" +
+	"<STMTTRN><TRNTYPE>CREDIT<DTPOSTED>20230115120000.000[-5:EST]<TRNAMT>500.25<FITID>202301150001<NAME>ACME CORP<MEMO>INVOICE 42 &amp; 43</STMTTRN>\r\n" +
+	"<STMTTRN><TRNTYPE>DEBIT<DTPOSTED>20230116<TRNAMT>-75,50<FITID>202301160002<NAME>Caf\xe9 Paris</STMTTRN>\r\n" +
+	"</BANKTRANLIST><LEDGERBAL><BALAMT>1424.75<DTASOF>20230116</LEDGERBAL></STMTRS></STMTTRNRS></BANKMSGSRSV1></OFX>\r\n"
+
+const ofxXMLFile = `<?xml version="1.0" encoding="UTF-8" standalone="no"?>
+<?OFX OFXHEADER="200" VERSION="220" SECURITY="NONE" OLDFILEUID="NONE" NEWFILEUID="NONE"?>
+<OFX>
+  <CREDITCARDMSGSRSV1>
+    <CCSTMTTRNRS>
+      <TRNUID>1</TRNUID>
+      <CCSTMTRS>
+        <CURDEF>EUR</CURDEF>
+        <CCACCTFROM><ACCTID>4111111111111111</ACCTID></CCACCTFROM>
+        <BANKTRANLIST>
+          <STMTTRN>
+            <TRNTYPE>DEBIT</TRNTYPE>
+            <DTPOSTED>20230201</DTPOSTED>
+            <TRNAMT>-20.00</TRNAMT>
+            <FITID>FIT-1</FITID>
+            <PAYEE><NAME>HOTEL LONDON</NAME></PAYEE>
+            <CURRENCY><CURRATE>1.15</CURRATE><CURSYM>GBP</CURSYM></CURRENCY>
+          </STMTTRN>
+          <STMTTRN>
+            <TRNTYPE>CREDIT</TRNTYPE>
+            <DTPOSTED>20230202093000</DTPOSTED>
+            <TRNAMT>+10.00</TRNAMT>
+            <FITID>FIT-2</FITID>
+            <MEMO>REFUND</MEMO>
+            <ORIGCURRENCY><CURRATE>1.15</CURRATE><CURSYM>GBP</CURSYM></ORIGCURRENCY>
+          </STMTTRN>
+        </BANKTRANLIST>
+      </CCSTMTRS>
+    </CCSTMTTRNRS>
+  </CREDITCARDMSGSRSV1>
+</OFX>`
+
+func readOFX(t *testing.T, file string) []model.BankStatement {
+	reader := usecase.NewOFXReader(strings.NewReader(file))
+	var rows []model.BankStatement
+	for {
+		row, err := reader.Next()
+		if err == io.EOF {
+			return rows
+		}
+		require.NoError(t, err)
+		rows = append(rows, row)
+	}
+}
+
+func TestOFXReader(t *testing.T) {
+	t.Run("Reads the transactions of an SGML file", func(t *testing.T) {
+		// Execute
+		rows := readOFX(t, ofxSGMLFile)
+
+		// Assert
+		assert.Equal(t, []model.BankStatement{
+			{
+				ID:           "0975312468/202301150001",
+				Amount:       model.MustParseMoney("500.25", "USD"),
+				Date:         time.Date(2023, 1, 15, 0, 0, 0, 0, time.UTC),
+				Reference:    "202301150001",
+				Narrative:    "INVOICE 42 & 43",
+				Counterparty: "ACME CORP",
+			},
+			{
+				// A decimal comma and Latin-1 text
+				ID:           "0975312468/202301160002",
+				Amount:       model.MustParseMoney("-75.50", "USD"),
+				Date:         time.Date(2023, 1, 16, 0, 0, 0, 0, time.UTC),
+				Reference:    "202301160002",
+				Counterparty: "Café Paris",
+			},
+		}, rows)
+	})
+
+	t.Run("Reads the transactions of an XML file", func(t *testing.T) {
+		// Execute
+		rows := readOFX(t, ofxXMLFile)
+
+		// Assert
+		assert.Equal(t, []model.BankStatement{
+			{
+				// The amount is in the currency of the transaction
+				ID:           "4111111111111111/FIT-1",
+				Amount:       model.MustParseMoney("-20.00", "GBP"),
+				Date:         time.Date(2023, 2, 1, 0, 0, 0, 0, time.UTC),
+				Reference:    "FIT-1",
+				Counterparty: "HOTEL LONDON",
+			},
+			{
+				// The amount was converted to the statement's currency
+				ID:        "4111111111111111/FIT-2",
+				Amount:    model.MustParseMoney("10.00", "EUR"),
+				Date:      time.Date(2023, 2, 2, 0, 0, 0, 0, time.UTC),
+				Reference: "FIT-2",
+				Narrative: "REFUND",
+			},
+		}, rows)
+	})
+
+	tests := []struct {
+		name        string
+		transaction string
+		expectedErr string
+	}{
+		{
+			name:        "Missing FITID",
+			transaction: "<STMTTRN><DTPOSTED>20230115<TRNAMT>1.00</STMTTRN>",
+			expectedErr: "transaction 1: missing FITID",
+		},
+		{
+			name:        "Invalid amount",
+			transaction: "<STMTTRN><DTPOSTED>20230115<TRNAMT>abc<FITID>1</STMTTRN>",
+			expectedErr: "invalid TRNAMT",
+		},
+		{
+			name:        "Invalid date",
+			transaction: "<STMTTRN><DTPOSTED>2023<TRNAMT>1.00<FITID>1</STMTTRN>",
+			expectedErr: "invalid DTPOSTED",
+		},
+		{
+			name:        "Unclosed transaction",
+			transaction: "<STMTTRN><DTPOSTED>20230115<TRNAMT>1.00<FITID>1",
+			expectedErr: "file ends inside a transaction",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Setup
+			reader := usecase.NewOFXReader(strings.NewReader("<OFX><STMTRS><CURDEF>USD" + tt.transaction))
+
+			// Execute
+			_, err := reader.Next()
+
+			// Assert
+			assert.ErrorContains(t, err, tt.expectedErr)
+		})
+	}
+}
@@ -0,0 +1,90 @@
+package usecase
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/aferryc/yars/internal/config"
+	"github.com/aferryc/yars/model"
+	"github.com/aferryc/yars/repository"
+	"github.com/pkg/errors"
+)
+
+// OutboxRelay publishes the events stored in the outbox to Kafka
+type OutboxRelay struct {
+	cfg        config.OutboxConfig
+	transactor repository.Transactor
+	outboxRepo repository.OutboxRepository
+	kafkaRepo  repository.KafkaRepository
+}
+
+// NewOutboxRelay creates an OutboxRelay
+func NewOutboxRelay(cfg config.OutboxConfig, transactor repository.Transactor, outboxRepo repository.OutboxRepository, kafkaRepo repository.KafkaRepository) *OutboxRelay {
+	return &OutboxRelay{
+		cfg:        cfg,
+		transactor: transactor,
+		outboxRepo: outboxRepo,
+		kafkaRepo:  kafkaRepo,
+	}
+}
+
+// Run publishes pending events until ctx is cancelled, polling for new ones
+// every PollInterval once the outbox is drained
+func (o *OutboxRelay) Run(ctx context.Context) {
+	for {
+		published, err := o.PublishPending(ctx)
+		if err != nil && ctx.Err() == nil {
+			log.Printf("Error relaying outbox events: %v", err)
+		}
+
+		// A full batch means more events are likely waiting
+		if err == nil && published > 0 && published == o.cfg.BatchSize {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(o.cfg.PollInterval):
+		}
+	}
+}
+
+// PublishPending publishes a batch of pending events in order and marks them
+// as sent, returning how many were published. An event that fails to publish
+// stops the batch, and is retried with the events after it on the next call.
+// Events are published at least once: an event published right before a
+// failure to mark it as sent is published again.
+func (o *OutboxRelay) PublishPending(ctx context.Context) (int, error) {
+	var published []int64
+	var publishErr error
+	err := o.transactor.WithinTx(ctx, func(ctx context.Context) error {
+		events, err := o.outboxRepo.FetchUnsent(ctx, o.cfg.BatchSize)
+		if err != nil {
+			return err
+		}
+
+		for _, event := range events {
+			if publishErr = o.publish(ctx, event); publishErr != nil {
+				break
+			}
+			published = append(published, event.ID)
+		}
+
+		// The events published before a failure are still marked as sent
+		return o.outboxRepo.MarkSent(ctx, published)
+	})
+	if err != nil {
+		return 0, errors.Wrap(err, "[OutboxRelay.PublishPending] failed to relay events")
+	}
+	return len(published), publishErr
+}
+
+func (o *OutboxRelay) publish(ctx context.Context, event model.OutboxEvent) error {
+	err := o.kafkaRepo.PublishEvent(ctx, event.Topic, event.Envelope, event.Payload)
+	if err != nil {
+		return errors.Wrapf(err, "[OutboxRelay.PublishPending] failed to publish event %s", event.Envelope.EventID)
+	}
+	return nil
+}
@@ -0,0 +1,181 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aferryc/yars/internal/config"
+	"github.com/aferryc/yars/model"
+	mockrepository "github.com/aferryc/yars/repository/mocks"
+	"github.com/aferryc/yars/usecase"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestOutboxRelay_PublishPending(t *testing.T) {
+	events := []model.OutboxEvent{
+		{
+			ID:       1,
+			Topic:    "recon-topic",
+			Envelope: model.EventEnvelope{EventType: model.EventTypeReconciliationRequested, EventID: "event-1", TaskID: "task-1"},
+			Payload:  []byte(`{"taskID":"task-1"}`),
+		},
+		{
+			ID:       2,
+			Topic:    "result-topic",
+			Envelope: model.EventEnvelope{EventType: model.EventTypeReconciliationCompleted, EventID: "event-2", TaskID: "task-2"},
+			Payload:  []byte(`{"taskID":"task-2"}`),
+		},
+	}
+
+	newRelay := func(ctrl *gomock.Controller) (*usecase.OutboxRelay, *mockrepository.MockOutboxRepository, *mockrepository.MockKafkaRepository) {
+		outboxRepo := mockrepository.NewMockOutboxRepository(ctrl)
+		kafkaRepo := mockrepository.NewMockKafkaRepository(ctrl)
+		relay := usecase.NewOutboxRelay(config.OutboxConfig{BatchSize: 10}, newTransactor(ctrl), outboxRepo, kafkaRepo)
+		return relay, outboxRepo, kafkaRepo
+	}
+
+	t.Run("Publishes events in order", func(t *testing.T) {
+		// Setup
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		relay, outboxRepo, kafkaRepo := newRelay(ctrl)
+
+		gomock.InOrder(
+			outboxRepo.EXPECT().FetchUnsent(gomock.Any(), 10).Return(events, nil),
+			kafkaRepo.EXPECT().
+				PublishEvent(gomock.Any(), "recon-topic", events[0].Envelope, gomock.Any()).
+				DoAndReturn(func(_ context.Context, _ string, _ model.EventEnvelope, payload any) error {
+					assert.Equal(t, events[0].Payload, payload)
+					return nil
+				}),
+			kafkaRepo.EXPECT().PublishEvent(gomock.Any(), "result-topic", events[1].Envelope, gomock.Any()).Return(nil),
+			outboxRepo.EXPECT().MarkSent(gomock.Any(), []int64{1, 2}).Return(nil),
+		)
+
+		// Execute
+		published, err := relay.PublishPending(context.Background())
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, 2, published)
+	})
+
+	t.Run("Nothing to publish", func(t *testing.T) {
+		// Setup
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		relay, outboxRepo, _ := newRelay(ctrl)
+
+		outboxRepo.EXPECT().FetchUnsent(gomock.Any(), 10).Return(nil, nil)
+		outboxRepo.EXPECT().MarkSent(gomock.Any(), gomock.Len(0)).Return(nil)
+
+		// Execute
+		published, err := relay.PublishPending(context.Background())
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, 0, published)
+	})
+
+	t.Run("Publish error stops the batch", func(t *testing.T) {
+		// Setup
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		relay, outboxRepo, kafkaRepo := newRelay(ctrl)
+
+		// The events published before the failure are still marked as sent
+		gomock.InOrder(
+			outboxRepo.EXPECT().FetchUnsent(gomock.Any(), 10).Return(events, nil),
+			kafkaRepo.EXPECT().PublishEvent(gomock.Any(), "recon-topic", gomock.Any(), gomock.Any()).Return(nil),
+			kafkaRepo.EXPECT().PublishEvent(gomock.Any(), "result-topic", gomock.Any(), gomock.Any()).Return(errors.New("kafka error")),
+			outboxRepo.EXPECT().MarkSent(gomock.Any(), []int64{1}).Return(nil),
+		)
+
+		// Execute
+		published, err := relay.PublishPending(context.Background())
+
+		// Assert
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "event-2")
+		assert.Equal(t, 1, published)
+	})
+
+	t.Run("Error fetching events", func(t *testing.T) {
+		// Setup
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		relay, outboxRepo, _ := newRelay(ctrl)
+
+		outboxRepo.EXPECT().FetchUnsent(gomock.Any(), 10).Return(nil, errors.New("database error"))
+
+		// Execute
+		published, err := relay.PublishPending(context.Background())
+
+		// Assert
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "database error")
+		assert.Equal(t, 0, published)
+	})
+
+	t.Run("Error marking events as sent", func(t *testing.T) {
+		// Setup
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		relay, outboxRepo, kafkaRepo := newRelay(ctrl)
+
+		outboxRepo.EXPECT().FetchUnsent(gomock.Any(), 10).Return(events[:1], nil)
+		kafkaRepo.EXPECT().PublishEvent(gomock.Any(), "recon-topic", gomock.Any(), gomock.Any()).Return(nil)
+		outboxRepo.EXPECT().MarkSent(gomock.Any(), []int64{1}).Return(errors.New("database error"))
+
+		// Execute
+		published, err := relay.PublishPending(context.Background())
+
+		// Assert
+		assert.Error(t, err)
+		assert.Equal(t, 0, published)
+	})
+}
+
+func TestOutboxRelay_Run(t *testing.T) {
+	t.Run("Waits between polls when the batch size is zero", func(t *testing.T) {
+		// Setup
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		outboxRepo := mockrepository.NewMockOutboxRepository(ctrl)
+		kafkaRepo := mockrepository.NewMockKafkaRepository(ctrl)
+		relay := usecase.NewOutboxRelay(config.OutboxConfig{BatchSize: 0, PollInterval: 20 * time.Millisecond}, newTransactor(ctrl), outboxRepo, kafkaRepo)
+
+		var polls int
+		outboxRepo.EXPECT().FetchUnsent(gomock.Any(), 0).DoAndReturn(func(context.Context, int) ([]model.OutboxEvent, error) {
+			polls++
+			return nil, nil
+		}).AnyTimes()
+		outboxRepo.EXPECT().MarkSent(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+
+		// Execute
+		relay.Run(ctx)
+
+		// Assert
+		assert.LessOrEqual(t, polls, 10)
+	})
+}
+
+// newTransactor returns a transactor running functions as they are, the
+// transaction boundaries being covered by the postgres tests
+func newTransactor(ctrl *gomock.Controller) *mockrepository.MockTransactor {
+	transactor := mockrepository.NewMockTransactor(ctrl)
+	transactor.EXPECT().
+		WithinTx(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, fn func(context.Context) error) error {
+			return fn(ctx)
+		}).
+		AnyTimes()
+	return transactor
+}
@@ -14,20 +14,23 @@ import (
 const fileDirFormat = "uploads/%s/%s"
 
 type ReconManager struct {
-	gcsRepo   repository.GCSRepository
-	kafkaRepo repository.KafkaRepository
-	cfg       *config.Config
+	storageRepo repository.StorageRepository
+	kafkaRepo   repository.KafkaRepository
+	taskRepo    repository.ReconTaskRepository
+	cfg         *config.Config
 }
 
 func NewReconManager(
-	gcsRepo repository.GCSRepository,
+	storageRepo repository.StorageRepository,
 	kafkaRepo repository.KafkaRepository,
+	taskRepo repository.ReconTaskRepository,
 	cfg *config.Config,
 ) *ReconManager {
 	return &ReconManager{
-		gcsRepo:   gcsRepo,
-		kafkaRepo: kafkaRepo,
-		cfg:       cfg,
+		storageRepo: storageRepo,
+		kafkaRepo:   kafkaRepo,
+		taskRepo:    taskRepo,
+		cfg:         cfg,
 	}
 }
 
@@ -39,19 +42,24 @@ func (rm *ReconManager) GenerateUploadURLs(ctx context.Context) (*model.UploadUR
 
 	expires := time.Now().Add(15 * time.Minute)
 
-	transactionURL, err := rm.gcsRepo.GenerateUploadURL(transactionPath, "text/csv", expires)
+	transactionURL, err := rm.storageRepo.GenerateUploadURL(transactionPath, "text/csv", expires)
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate transaction upload URL: %w", err)
 	}
 
-	bankStatementURL, err := rm.gcsRepo.GenerateUploadURL(bankStatementPath, "text/csv", expires)
+	bankStatementURL, err := rm.storageRepo.GenerateUploadURL(bankStatementPath, "text/csv", expires)
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate bank statement upload URL: %w", err)
 	}
 
+	if err := rm.taskRepo.Create(ctx, taskID); err != nil {
+		return nil, fmt.Errorf("failed to create task: %w", err)
+	}
+
 	return &model.UploadURLResponse{
 		TransactionURL:   transactionURL,
 		BankStatementURL: bankStatementURL,
+		UploadMethod:     rm.storageRepo.UploadMethod(),
 		TaskID:           taskID,
 		ExpiresAt:        expires,
 	}, nil
@@ -66,6 +74,10 @@ func (rm *ReconManager) InitiateCompilation(ctx context.Context, req model.Compi
 		return fmt.Errorf("bank name is required")
 	}
 
+	if err := rm.taskRepo.UpdateStatus(ctx, req.TaskID, model.TaskUploaded, ""); err != nil {
+		return fmt.Errorf("failed to mark task as uploaded: %w", err)
+	}
+
 	transactionPath := transactionDirectory(req.TaskID)
 	bankStatementPath := bankDirectory(req.TaskID)
 
@@ -78,14 +90,35 @@ func (rm *ReconManager) InitiateCompilation(ctx context.Context, req model.Compi
 		TaskID:        req.TaskID,
 	}
 
-	err := rm.kafkaRepo.Publish(ctx, rm.cfg.Kafka.Topic.CompilerTopic, req.TaskID, event)
+	envelope := model.NewEventEnvelope(ctx, model.EventTypeCompilationRequested, req.TaskID)
+	err := rm.kafkaRepo.PublishEvent(ctx, rm.cfg.Kafka.Topic.CompilerTopic, envelope, event)
 	if err != nil {
-		return fmt.Errorf("failed to publish compilation event: %w", err)
+		err = fmt.Errorf("failed to publish compilation event: %w", err)
+		markTaskFailed(ctx, rm.taskRepo, req.TaskID, err)
+		return err
 	}
 
 	return nil
 }
 
+// GetTaskStatus returns where the task is in its lifecycle, and why it failed
+// if it did
+func (rm *ReconManager) GetTaskStatus(ctx context.Context, taskID string) (*model.TaskStatusResponse, error) {
+	task, err := rm.taskRepo.Get(ctx, taskID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get task: %w", err)
+	}
+
+	return &model.TaskStatusResponse{
+		TaskID:     task.TaskID,
+		Status:     task.Status,
+		Error:      task.Error,
+		CreatedAt:  task.CreatedAt,
+		UpdatedAt:  task.UpdatedAt,
+		FinishedAt: task.FinishedAt,
+	}, nil
+}
+
 func bankDirectory(taskID string) string {
 	return fmt.Sprintf(fileDirFormat, taskID, model.BankStatementFile)
 }
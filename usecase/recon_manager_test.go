@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net/http"
 	"strings"
 	"testing"
 	"time"
@@ -22,8 +23,9 @@ func TestReconManager_GenerateUploadURLs(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
-	mockGCSRepo := repositorymock.NewMockGCSRepository(ctrl)
+	mockStorageRepo := repositorymock.NewMockStorageRepository(ctrl)
 	mockKafkaRepo := repositorymock.NewMockKafkaRepository(ctrl)
+	mockTaskRepo := repositorymock.NewMockReconTaskRepository(ctrl)
 
 	cfg := &config.Config{
 		Kafka: config.KafkaConfig{
@@ -33,7 +35,7 @@ func TestReconManager_GenerateUploadURLs(t *testing.T) {
 		},
 	}
 
-	manager := usecase.NewReconManager(mockGCSRepo, mockKafkaRepo, cfg)
+	manager := usecase.NewReconManager(mockStorageRepo, mockKafkaRepo, mockTaskRepo, cfg)
 
 	t.Run("Successfully generate upload URLs", func(t *testing.T) {
 		// Setup expectations
@@ -41,7 +43,7 @@ func TestReconManager_GenerateUploadURLs(t *testing.T) {
 		expectedBankStatementURL := "https://storage.googleapis.com/bucket/uploads/uuid/bank_statement.csv"
 
 		// Use a matcher for the taskID since it's a UUID we can't predict
-		mockGCSRepo.EXPECT().
+		mockStorageRepo.EXPECT().
 			GenerateUploadURL(gomock.Any(), "text/csv", gomock.Any()).
 			DoAndReturn(func(path, contentType string, expires time.Time) (string, error) {
 				// Ensure path starts with "uploads/" and contains the file name
@@ -63,6 +65,8 @@ func TestReconManager_GenerateUploadURLs(t *testing.T) {
 				return expectedTransactionURL, nil
 			}).
 			Times(2)
+		mockStorageRepo.EXPECT().UploadMethod().Return(http.MethodPut)
+		mockTaskRepo.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil)
 
 		// Call the method
 		ctx := context.Background()
@@ -74,6 +78,7 @@ func TestReconManager_GenerateUploadURLs(t *testing.T) {
 		assert.NotEmpty(t, response.TaskID)
 		assert.Equal(t, expectedTransactionURL, response.TransactionURL)
 		assert.Equal(t, expectedBankStatementURL, response.BankStatementURL)
+		assert.Equal(t, http.MethodPut, response.UploadMethod)
 		assert.True(t, response.ExpiresAt.After(time.Now()))
 
 		// Validate UUID format
@@ -81,9 +86,26 @@ func TestReconManager_GenerateUploadURLs(t *testing.T) {
 		assert.NoError(t, err, "TaskID should be a valid UUID")
 	})
 
+	t.Run("Error creating task", func(t *testing.T) {
+		// Setup expectations
+		mockStorageRepo.EXPECT().
+			GenerateUploadURL(gomock.Any(), "text/csv", gomock.Any()).
+			Return("url", nil).
+			Times(2)
+		mockTaskRepo.EXPECT().Create(gomock.Any(), gomock.Any()).Return(errors.New("database error"))
+
+		// Call the method
+		response, err := manager.GenerateUploadURLs(context.Background())
+
+		// Assert
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to create task")
+		assert.Nil(t, response)
+	})
+
 	t.Run("Error generating transaction URL", func(t *testing.T) {
 		// Setup expectations
-		mockGCSRepo.EXPECT().
+		mockStorageRepo.EXPECT().
 			GenerateUploadURL(gomock.Any(), "text/csv", gomock.Any()).
 			Return("", errors.New("GCS error")).
 			Times(1)
@@ -101,10 +123,10 @@ func TestReconManager_GenerateUploadURLs(t *testing.T) {
 	t.Run("Error generating bank statement URL", func(t *testing.T) {
 		// Setup expectations - first call succeeds, second fails
 		gomock.InOrder(
-			mockGCSRepo.EXPECT().
+			mockStorageRepo.EXPECT().
 				GenerateUploadURL(gomock.Any(), "text/csv", gomock.Any()).
 				Return("transaction-url", nil),
-			mockGCSRepo.EXPECT().
+			mockStorageRepo.EXPECT().
 				GenerateUploadURL(gomock.Any(), "text/csv", gomock.Any()).
 				Return("", errors.New("GCS error")),
 		)
@@ -123,8 +145,9 @@ func TestReconManager_InitiateCompilation(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
-	mockGCSRepo := repositorymock.NewMockGCSRepository(ctrl)
+	mockStorageRepo := repositorymock.NewMockStorageRepository(ctrl)
 	mockKafkaRepo := repositorymock.NewMockKafkaRepository(ctrl)
+	mockTaskRepo := repositorymock.NewMockReconTaskRepository(ctrl)
 
 	cfg := &config.Config{
 		Kafka: config.KafkaConfig{
@@ -134,7 +157,7 @@ func TestReconManager_InitiateCompilation(t *testing.T) {
 		},
 	}
 
-	manager := usecase.NewReconManager(mockGCSRepo, mockKafkaRepo, cfg)
+	manager := usecase.NewReconManager(mockStorageRepo, mockKafkaRepo, mockTaskRepo, cfg)
 	ctx := context.Background()
 
 	t.Run("Successfully initiate compilation", func(t *testing.T) {
@@ -152,14 +175,22 @@ func TestReconManager_InitiateCompilation(t *testing.T) {
 		}
 
 		// Setup expectations
+		mockTaskRepo.EXPECT().UpdateStatus(ctx, taskID, model.TaskUploaded, "").Return(nil)
 		mockKafkaRepo.EXPECT().
-			Publish(
+			PublishEvent(
 				ctx,
 				cfg.Kafka.Topic.CompilerTopic,
-				taskID,
+				gomock.Any(),
 				gomock.Any(),
 			).
-			DoAndReturn(func(ctx context.Context, topic, key string, message any) error {
+			DoAndReturn(func(ctx context.Context, topic string, envelope model.EventEnvelope, message any) error {
+				// Validate the envelope
+				assert.Equal(t, model.EventTypeCompilationRequested, envelope.EventType)
+				assert.Equal(t, 1, envelope.SchemaVersion)
+				assert.Equal(t, taskID, envelope.TaskID)
+				assert.NotEmpty(t, envelope.EventID)
+				assert.NotEmpty(t, envelope.TraceParent)
+
 				// Validate the event structure
 				event, ok := message.(model.CompilerEvent)
 				if !ok {
@@ -220,15 +251,84 @@ func TestReconManager_InitiateCompilation(t *testing.T) {
 		}
 
 		// Setup expectations
-		mockKafkaRepo.EXPECT().
-			Publish(ctx, cfg.Kafka.Topic.CompilerTopic, req.TaskID, gomock.Any()).
-			Return(errors.New("kafka error"))
+		gomock.InOrder(
+			mockTaskRepo.EXPECT().UpdateStatus(ctx, req.TaskID, model.TaskUploaded, "").Return(nil),
+			mockKafkaRepo.EXPECT().
+				PublishEvent(ctx, cfg.Kafka.Topic.CompilerTopic, gomock.Any(), gomock.Any()).
+				Return(errors.New("kafka error")),
+			mockTaskRepo.EXPECT().
+				UpdateStatus(ctx, req.TaskID, model.TaskFailed, gomock.Any()).
+				DoAndReturn(func(_ context.Context, _ string, _ model.TaskStatus, message string) error {
+					assert.Contains(t, message, "kafka error")
+					return nil
+				}),
+		)
 
 		err := manager.InitiateCompilation(ctx, req)
 
 		assert.Error(t, err)
 		assert.Contains(t, err.Error(), "failed to publish compilation event")
 	})
+
+	t.Run("Task already submitted", func(t *testing.T) {
+		req := model.CompilerRequest{
+			TaskID:   uuid.New().String(),
+			BankName: "Test Bank",
+		}
+
+		// Setup expectations
+		mockTaskRepo.EXPECT().
+			UpdateStatus(ctx, req.TaskID, model.TaskUploaded, "").
+			Return(model.ErrInvalidTaskTransition)
+
+		err := manager.InitiateCompilation(ctx, req)
+
+		assert.True(t, errors.Is(err, model.ErrInvalidTaskTransition))
+	})
+}
+
+func TestReconManager_GetTaskStatus(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStorageRepo := repositorymock.NewMockStorageRepository(ctrl)
+	mockKafkaRepo := repositorymock.NewMockKafkaRepository(ctrl)
+	mockTaskRepo := repositorymock.NewMockReconTaskRepository(ctrl)
+	manager := usecase.NewReconManager(mockStorageRepo, mockKafkaRepo, mockTaskRepo, &config.Config{})
+	ctx := context.Background()
+
+	t.Run("Failed task", func(t *testing.T) {
+		createdAt := time.Date(2023, 1, 15, 12, 0, 0, 0, time.UTC)
+		finishedAt := createdAt.Add(time.Minute)
+		mockTaskRepo.EXPECT().Get(ctx, "test-task-id").Return(model.ReconTask{
+			TaskID:     "test-task-id",
+			Status:     model.TaskFailed,
+			Error:      "error processing internal file",
+			CreatedAt:  createdAt,
+			UpdatedAt:  finishedAt,
+			FinishedAt: &finishedAt,
+		}, nil)
+
+		// Execute
+		status, err := manager.GetTaskStatus(ctx, "test-task-id")
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, model.TaskFailed, status.Status)
+		assert.Equal(t, "error processing internal file", status.Error)
+		assert.Equal(t, &finishedAt, status.FinishedAt)
+	})
+
+	t.Run("Unknown task", func(t *testing.T) {
+		mockTaskRepo.EXPECT().Get(ctx, "missing").Return(model.ReconTask{}, model.ErrTaskNotFound)
+
+		// Execute
+		status, err := manager.GetTaskStatus(ctx, "missing")
+
+		// Assert
+		assert.Nil(t, status)
+		assert.True(t, errors.Is(err, model.ErrTaskNotFound))
+	})
 }
 
 // TestReconManager_Helpers tests the helper functions for directory path generation
@@ -239,8 +339,9 @@ func TestReconManager_Helpers(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
-	mockGCSRepo := repositorymock.NewMockGCSRepository(ctrl)
+	mockStorageRepo := repositorymock.NewMockStorageRepository(ctrl)
 	mockKafkaRepo := repositorymock.NewMockKafkaRepository(ctrl)
+	mockTaskRepo := repositorymock.NewMockReconTaskRepository(ctrl)
 
 	cfg := &config.Config{
 		Kafka: config.KafkaConfig{
@@ -250,7 +351,7 @@ func TestReconManager_Helpers(t *testing.T) {
 		},
 	}
 
-	manager := usecase.NewReconManager(mockGCSRepo, mockKafkaRepo, cfg)
+	manager := usecase.NewReconManager(mockStorageRepo, mockKafkaRepo, mockTaskRepo, cfg)
 
 	// Call InitiateCompilation to test the path formation indirectly
 	taskID := "test-uuid"
@@ -261,9 +362,10 @@ func TestReconManager_Helpers(t *testing.T) {
 
 	// Set up expectations to capture the paths
 	var capturedTransaction, capturedBankStatement string
+	mockTaskRepo.EXPECT().UpdateStatus(gomock.Any(), taskID, model.TaskUploaded, "").Return(nil)
 	mockKafkaRepo.EXPECT().
-		Publish(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
-		DoAndReturn(func(ctx context.Context, topic, key string, message any) error {
+		PublishEvent(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, topic string, envelope model.EventEnvelope, message any) error {
 			event := message.(model.CompilerEvent)
 			capturedTransaction = event.Transaction
 			capturedBankStatement = event.BankStatement
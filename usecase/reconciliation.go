@@ -4,121 +4,306 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"log"
+	"sort"
 	"time"
 
+	"github.com/aferryc/yars/internal/config"
 	"github.com/aferryc/yars/model"
 	"github.com/aferryc/yars/repository"
 	"github.com/pkg/errors"
 )
 
 type ReconciliationUsecase struct {
+	cfg          *config.Config
+	matchers     []Matcher
 	internalRepo repository.InternalTransactionRepository
 	bankRepo     repository.BankStatementRepository
 	reconRepo    repository.ReconResultRepository
+	fxRepo       repository.FXRateRepository
+	taskRepo     repository.ReconTaskRepository
+	transactor   repository.Transactor
+	outboxRepo   repository.OutboxRepository
 }
 
-func NewReconciliationUsecase(internalRepo repository.InternalTransactionRepository, bankRepo repository.BankStatementRepository, reconRepo repository.ReconResultRepository) *ReconciliationUsecase {
+// NewReconciliationUsecase creates a ReconciliationUsecase running the given
+// matchers in order, see BuildMatcherChain.
+func NewReconciliationUsecase(cfg *config.Config, matchers []Matcher, internalRepo repository.InternalTransactionRepository, bankRepo repository.BankStatementRepository, reconRepo repository.ReconResultRepository, fxRepo repository.FXRateRepository, taskRepo repository.ReconTaskRepository, transactor repository.Transactor, outboxRepo repository.OutboxRepository) *ReconciliationUsecase {
 	return &ReconciliationUsecase{
+		cfg:          cfg,
+		matchers:     matchers,
 		internalRepo: internalRepo,
 		bankRepo:     bankRepo,
 		reconRepo:    reconRepo,
+		fxRepo:       fxRepo,
+		taskRepo:     taskRepo,
+		transactor:   transactor,
+		outboxRepo:   outboxRepo,
 	}
 }
 
-func (r *ReconciliationUsecase) ProcessEvent(event []byte) error {
+func (r *ReconciliationUsecase) ProcessEvent(ctx context.Context, msg model.Message) error {
+	envelope, payload, err := eventPayload(msg, model.EventTypeReconciliationRequested)
+	if errors.Is(err, errOtherEventType) {
+		return nil
+	}
+	if err != nil {
+		markTaskFailed(ctx, r.taskRepo, envelope.TaskID, err)
+		return errors.Wrap(err, "[parseEvent] failed to read event")
+	}
+	ctx = model.ContextWithTraceParent(ctx, envelope.TraceParent)
+
 	var reconEvent model.ReconciliationEvent
-	err := json.Unmarshal(event, &reconEvent)
+	err = json.Unmarshal(payload, &reconEvent)
 	if err != nil {
+		markTaskFailed(ctx, r.taskRepo, envelope.TaskID, err)
 		return errors.Wrap(err, "[parseEvent] failed to unmarshal event")
 	}
 	if reconEvent.StartDate.IsZero() || reconEvent.EndDate.IsZero() {
-		return errors.Wrap(errors.New("startDate and endDate are required fields"), "[parseEvent] invalid event")
+		err = errors.Wrap(errors.New("startDate and endDate are required fields"), "[parseEvent] invalid event")
+		markTaskFailed(ctx, r.taskRepo, reconEvent.TaskID, err)
+		return err
+	}
+	if reconEvent.TaskID == "" || reconEvent.BankName == "" {
+		err = errors.Wrap(errors.New("taskID and bankName are required fields"), "[parseEvent] invalid event")
+		markTaskFailed(ctx, r.taskRepo, reconEvent.TaskID, err)
+		return err
 	}
 
-	return r.ReconcileTransactions(reconEvent)
+	return r.ReconcileTransactions(ctx, reconEvent)
 }
 
-func (r *ReconciliationUsecase) ReconcileTransactions(event model.ReconciliationEvent) error {
-	ctx := context.Background()
-	internalTransactions, err := r.internalRepo.FetchAll(event.StartDate, event.EndDate)
+// ReconcileTransactions matches the records ingested for the task, stores the
+// results and reports them on the result topic, keeping the task status up to
+// date. A completed task is skipped so a redelivered event is harmless.
+func (r *ReconciliationUsecase) ReconcileTransactions(ctx context.Context, event model.ReconciliationEvent) error {
+	task, err := r.taskRepo.Get(ctx, event.TaskID)
 	if err != nil {
-		return err
+		return errors.Wrap(err, "[ReconcileTransactions] failed to get task")
+	}
+	if task.Status == model.TaskCompleted {
+		log.Printf("Task %s is already reconciled, skipping event", event.TaskID)
+		return nil
+	}
+
+	if err := r.taskRepo.UpdateStatus(ctx, event.TaskID, model.TaskReconciling, ""); err != nil {
+		return errors.Wrap(err, "[ReconcileTransactions] failed to mark task as reconciling")
 	}
 
-	bankStatements, err := r.bankRepo.FetchAll(event.StartDate, event.EndDate)
+	summary, err := r.reconcile(ctx, event)
+	if err == nil {
+		err = r.complete(ctx, event, summary)
+	}
 	if err != nil {
+		markTaskFailed(ctx, r.taskRepo, event.TaskID, err)
 		return err
 	}
+	return nil
+}
 
-	summary := r.matchTransactions(internalTransactions, bankStatements)
-	summary.TaskID = event.TaskID
-
-	err = r.reconRepo.StoreSummary(ctx, summary, event.StartDate, event.EndDate)
+func (r *ReconciliationUsecase) reconcile(ctx context.Context, event model.ReconciliationEvent) (model.ReconciliationSummary, error) {
+	internalTransactions, err := r.internalRepo.FetchAll(event.TaskID, event.BankName, event.StartDate, event.EndDate)
 	if err != nil {
-		return errors.Wrap(err, "[ReconcileTransactions] failed to store summary")
-
+		return model.ReconciliationSummary{}, err
 	}
 
-	return nil
-}
+	bankStatements, err := r.bankRepo.FetchAll(event.TaskID, event.BankName, event.StartDate, event.EndDate)
+	if err != nil {
+		return model.ReconciliationSummary{}, err
+	}
 
-func (r *ReconciliationUsecase) matchTransactions(internalTransactions model.TransactionList, bankStatements model.BankStatementList) model.ReconciliationSummary {
-	var matchedCount int
-	tx := internalTransactions.Precompile()
-	bank := bankStatements.Precompile()
-	var unmatchedInternal []model.Transaction
-	var unmatchedBank []model.BankStatement
+	transactions, statements := applyBaseCurrency(internalTransactions.Transactions, bankStatements.BankStatements, r.cfg.App.Reconciliation.FX.BaseCurrency)
 
-	matchedCount = 0
+	// Rates are only needed when the records are not all in one currency
+	var rates *model.FXRateTable
+	if len(currenciesOf(transactions, statements)) > 1 {
+		rates, err = r.loadRates(ctx, event)
+		if err != nil {
+			return model.ReconciliationSummary{}, errors.Wrap(err, "[ReconcileTransactions] failed to load fx rates")
+		}
+	}
 
-	for amount, count := range tx.Count {
-		bankCount := bank.Count[amount]
+	summary, err := r.matchTransactions(transactions, statements, rates)
+	if err != nil {
+		return model.ReconciliationSummary{}, errors.Wrap(err, "[ReconcileTransactions] failed to match transactions")
+	}
+	summary.TaskID = event.TaskID
 
-		matchesAtThisAmount := min(count, bankCount)
-		matchedCount += matchesAtThisAmount
+	return summary, nil
+}
 
-		if count > bankCount {
-			unmatchedCount := count - bankCount
+// complete stores the results, the completed event reporting them to
+// downstream services and the completed status in one transaction, so the
+// event is published if and only if the task is completed
+func (r *ReconciliationUsecase) complete(ctx context.Context, event model.ReconciliationEvent, summary model.ReconciliationSummary) error {
+	envelope := model.NewEventEnvelope(ctx, model.EventTypeReconciliationCompleted, event.TaskID)
+	completed, err := model.NewOutboxEvent(r.cfg.Kafka.Topic.ResultTopic, envelope, model.ReconciliationCompletedEvent{
+		TaskID:                 event.TaskID,
+		BankName:               event.BankName,
+		StartDate:              event.StartDate,
+		EndDate:                event.EndDate,
+		TotalTransaction:       summary.TotalTransaction,
+		TotalMatched:           summary.TotalMatched,
+		TotalUnmatchedInternal: len(summary.UnmatchedInternal),
+		TotalUnmatchedBank:     len(summary.UnmatchedBank),
+		TotalDiscrepancy:       summary.TotalDiscrepancy,
+		Currency:               summary.TotalDiscrepancy.Currency(),
+		MissingRates:           summary.MissingRates,
+		CompletedAt:            envelope.OccurredAt,
+	})
+	if err != nil {
+		return errors.Wrap(err, "[ReconcileTransactions] failed to create completed event")
+	}
 
-			// Take the last unmatchedCount transactions from this amount group
-			transactions := tx.List[amount]
-			startIndex := len(transactions) - unmatchedCount
-			unmatchedInternal = append(unmatchedInternal, transactions[startIndex:]...)
+	return r.transactor.WithinTx(ctx, func(ctx context.Context) error {
+		if err := r.reconRepo.StoreSummary(ctx, summary, event.StartDate, event.EndDate); err != nil {
+			return errors.Wrap(err, "[ReconcileTransactions] failed to store summary")
 		}
-	}
+		if err := r.outboxRepo.Add(ctx, completed); err != nil {
+			return errors.Wrap(err, "[ReconcileTransactions] failed to store completed event")
+		}
+		if err := r.taskRepo.UpdateStatus(ctx, event.TaskID, model.TaskCompleted, ""); err != nil {
+			return errors.Wrap(err, "[ReconcileTransactions] failed to mark task as completed")
+		}
+		return nil
+	})
+}
 
-	for amount, count := range bank.Count {
-		internalCount := tx.Count[amount]
+// loadRates fetches the rates that may apply to records of the event. Records
+// can fall a few days outside the event range through the date tolerance, and
+// older rates are used through the lookback window.
+func (r *ReconciliationUsecase) loadRates(ctx context.Context, event model.ReconciliationEvent) (*model.FXRateTable, error) {
+	fxCfg := r.cfg.App.Reconciliation.FX
+	// Without a date check records are only those of the event range
+	toleranceDays := max(r.cfg.App.Reconciliation.DateToleranceDays, 0)
 
-		if count > internalCount {
-			unmatchedCount := count - internalCount
-			// Take the last unmatchedCount statements from this amount group
-			statements := bank.List[amount]
-			startIndex := len(statements) - unmatchedCount
-			unmatchedBank = append(unmatchedBank, statements[startIndex:]...)
-		}
+	rates, err := r.fxRepo.FetchRates(ctx,
+		event.StartDate.AddDate(0, 0, -(toleranceDays+fxCfg.RateLookbackDays)),
+		event.EndDate.AddDate(0, 0, toleranceDays),
+	)
+	if err != nil {
+		return nil, err
 	}
+	return model.NewFXRateTable(rates, fxCfg.RateLookbackDays), nil
+}
 
-	totalDiscrepancy := sumTotalDiscrepancy(unmatchedBank, unmatchedInternal)
+func (r *ReconciliationUsecase) matchTransactions(transactions []model.Transaction, statements []model.BankStatement, rates *model.FXRateTable) (model.ReconciliationSummary, error) {
+	result := runMatcherChain(r.matchers, rates, transactions, statements)
+	unmatchedInternal := result.UnmatchedInternal
+	unmatchedBank := result.UnmatchedBank
+	matchedCount := countMatched(result.Matches)
+
+	totalDiscrepancy, missingRates, err := sumTotalDiscrepancy(unmatchedBank, unmatchedInternal, rates, r.cfg.App.Reconciliation.FX.BaseCurrency)
+	if err != nil {
+		return model.ReconciliationSummary{}, errors.Wrap(err, "[matchTransactions] failed to sum discrepancy")
+	}
+	if len(missingRates) > 0 {
+		log.Printf("Discrepancy leaves out the unmatched records in %v, no FX rate into %s was found for them", missingRates, totalDiscrepancy.Currency())
+	}
 
 	return model.ReconciliationSummary{
 		UnmatchedInternal: unmatchedInternal,
 		UnmatchedBank:     unmatchedBank,
+		Matches:           result.Matches,
 		TotalMatched:      matchedCount,
 		TotalDiscrepancy:  totalDiscrepancy,
+		MissingRates:      missingRates,
 		TotalTransaction:  matchedCount + len(unmatchedInternal) + len(unmatchedBank),
+	}, nil
+}
+
+// countMatched counts the records settled by the matches. As a one-to-one pair
+// counts once, a group counts once per record on its larger side, so that
+// TotalTransaction still adds up to every record reconciled.
+func countMatched(matches []model.Match) int {
+	var count int
+	for _, match := range matches {
+		count += max(len(match.Transactions), len(match.BankStatements))
 	}
+	return count
 }
 
-func sumTotalDiscrepancy(unmatchedBank []model.BankStatement, unmatchedInternal []model.Transaction) float64 {
-	var totalDiscrepancy float64
+// sumTotalDiscrepancy adds up the unmatched amounts. When they are all in one
+// currency the total is in that currency, otherwise every amount is converted
+// into the base currency at the rate of its own date. Amounts without a rate
+// are left out of the total, their currencies being returned sorted.
+func sumTotalDiscrepancy(unmatchedBank []model.BankStatement, unmatchedInternal []model.Transaction, rates *model.FXRateTable, baseCurrency string) (model.Money, []string, error) {
+	currency := baseCurrency
+	currencies := currenciesOf(unmatchedInternal, unmatchedBank)
+	if len(currencies) == 1 {
+		for single := range currencies {
+			currency = single
+		}
+	} else if len(currencies) > 1 && baseCurrency == "" {
+		return model.Money{}, nil, errors.New("[sumTotalDiscrepancy] records are in several currencies but no base currency is configured")
+	}
+
+	totalDiscrepancy := model.NewMoney(0, currency)
+	missing := make(map[string]bool)
+	add := func(amount model.Money, date time.Time) error {
+		var err error
+		if amount.Currency() != currency {
+			converted, err := rates.Convert(amount, currency, date)
+			if errors.Is(err, model.ErrFXRateNotFound) {
+				missing[amount.Currency()] = true
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			amount = converted
+		}
+		totalDiscrepancy, err = totalDiscrepancy.Add(amount)
+		return err
+	}
+
 	for _, statement := range unmatchedBank {
-		totalDiscrepancy += statement.Amount
+		if err := add(statement.Amount, statement.Date); err != nil {
+			return model.Money{}, nil, errors.Wrapf(err, "[sumTotalDiscrepancy] bank statement %s", statement.ID)
+		}
 	}
 	for _, transaction := range unmatchedInternal {
-		totalDiscrepancy += transaction.Amount
+		if err := add(transaction.Amount, transaction.TransactionTime); err != nil {
+			return model.Money{}, nil, errors.Wrapf(err, "[sumTotalDiscrepancy] transaction %s", transaction.ID)
+		}
+	}
+
+	var missingRates []string
+	for missingCurrency := range missing {
+		missingRates = append(missingRates, missingCurrency)
+	}
+	sort.Strings(missingRates)
+	return totalDiscrepancy, missingRates, nil
+}
+
+// applyBaseCurrency assigns the base currency to records ingested without one
+func applyBaseCurrency(transactions []model.Transaction, statements []model.BankStatement, baseCurrency string) ([]model.Transaction, []model.BankStatement) {
+	if baseCurrency == "" {
+		return transactions, statements
+	}
+	for i := range transactions {
+		if transactions[i].Amount.Currency() == "" {
+			transactions[i].Amount = transactions[i].Amount.WithCurrency(baseCurrency)
+		}
+	}
+	for i := range statements {
+		if statements[i].Amount.Currency() == "" {
+			statements[i].Amount = statements[i].Amount.WithCurrency(baseCurrency)
+		}
+	}
+	return transactions, statements
+}
+
+// currenciesOf returns the distinct currencies of the given records
+func currenciesOf(transactions []model.Transaction, statements []model.BankStatement) map[string]bool {
+	currencies := make(map[string]bool)
+	for _, transaction := range transactions {
+		currencies[transaction.Amount.Currency()] = true
+	}
+	for _, statement := range statements {
+		currencies[statement.Amount.Currency()] = true
 	}
-	return totalDiscrepancy
+	return currencies
 }
 
 func (r *ReconciliationUsecase) GenerateReport(unmatchedInternal []model.Transaction, unmatchedBank []model.BankStatement) string {
@@ -1,10 +1,15 @@
 package usecase_test
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
 	"testing"
 	"time"
 
+	"github.com/aferryc/yars/internal/config"
 	"github.com/aferryc/yars/model"
 	mockrepository "github.com/aferryc/yars/repository/mocks"
 	"github.com/aferryc/yars/usecase"
@@ -22,7 +27,8 @@ func TestReconciliationService(t *testing.T) {
 	bankRepo := mockrepository.NewMockBankStatementRepository(ctrl)
 	internalRepo := mockrepository.NewMockInternalTransactionRepository(ctrl)
 	reconRepo := mockrepository.NewMockReconResultRepository(ctrl)
-	uc := usecase.NewReconciliationUsecase(internalRepo, bankRepo, reconRepo)
+	fxRepo := mockrepository.NewMockFXRateRepository(ctrl)
+	uc := newReconciliationUsecase(t, &config.Config{}, internalRepo, bankRepo, reconRepo, fxRepo)
 
 	// Define time range for the test
 	startTime := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
@@ -31,18 +37,18 @@ func TestReconciliationService(t *testing.T) {
 
 	// Test data
 	internalTransactions := []model.Transaction{
-		{ID: "foo", Amount: 200, TransactionTime: cTime, Type: "CREDIT"},
-		{ID: "bar", Amount: 200, TransactionTime: cTime, Type: "CREDIT"},
-		{ID: "lorem", Amount: 400, TransactionTime: cTime, Type: "DEBIT"},
-		{ID: "ipsum", Amount: 200, TransactionTime: cTime, Type: "CREDIT"},
+		{ID: "foo", Amount: money("200.00"), TransactionTime: cTime, Type: "CREDIT"},
+		{ID: "bar", Amount: money("200.00"), TransactionTime: cTime, Type: "CREDIT"},
+		{ID: "lorem", Amount: money("400.00"), TransactionTime: cTime, Type: "DEBIT"},
+		{ID: "ipsum", Amount: money("200.00"), TransactionTime: cTime, Type: "CREDIT"},
 	}
 
 	// Updated bank statements to use string IDs instead of integers
 	bankStatements := []model.BankStatement{
-		{ID: "bs-1", Amount: 200, Date: cTime},
-		{ID: "bs-2", Amount: 202, Date: cTime},
-		{ID: "bs-3", Amount: -400, Date: cTime},
-		{ID: "bs-4", Amount: 200, Date: cTime},
+		{ID: "bs-1", Amount: money("200.00"), Date: cTime},
+		{ID: "bs-2", Amount: money("202.00"), Date: cTime},
+		{ID: "bs-3", Amount: money("-400.00"), Date: cTime},
+		{ID: "bs-4", Amount: money("200.00"), Date: cTime},
 	}
 
 	// Create reconciliation event
@@ -53,10 +59,10 @@ func TestReconciliationService(t *testing.T) {
 	}
 
 	// Mock repository methods
-	bankRepo.EXPECT().FetchAll(startTime, endTime).Return(model.BankStatementList{
+	bankRepo.EXPECT().FetchAll(gomock.Any(), gomock.Any(), startTime, endTime).Return(model.BankStatementList{
 		BankStatements: bankStatements,
 	}, nil)
-	internalRepo.EXPECT().FetchAll(startTime, endTime).Return(model.TransactionList{
+	internalRepo.EXPECT().FetchAll(gomock.Any(), gomock.Any(), startTime, endTime).Return(model.TransactionList{
 		Transactions: internalTransactions,
 	}, nil)
 
@@ -69,7 +75,7 @@ func TestReconciliationService(t *testing.T) {
 	).Return(nil)
 
 	// Perform reconciliation with event
-	err := uc.ReconcileTransactions(reconEvent)
+	err := uc.ReconcileTransactions(context.Background(), reconEvent)
 	require.NoError(t, err)
 }
 
@@ -82,7 +88,8 @@ func TestReconciliationUsecase_ProcessEvent(t *testing.T) {
 	bankRepo := mockrepository.NewMockBankStatementRepository(ctrl)
 	internalRepo := mockrepository.NewMockInternalTransactionRepository(ctrl)
 	reconRepo := mockrepository.NewMockReconResultRepository(ctrl)
-	uc := usecase.NewReconciliationUsecase(internalRepo, bankRepo, reconRepo)
+	fxRepo := mockrepository.NewMockFXRateRepository(ctrl)
+	uc := newReconciliationUsecase(t, &config.Config{}, internalRepo, bankRepo, reconRepo, fxRepo)
 
 	// Define time range for the test
 	startTime := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
@@ -91,6 +98,7 @@ func TestReconciliationUsecase_ProcessEvent(t *testing.T) {
 	// Create event JSON
 	event := model.ReconciliationEvent{
 		TaskID:    "test-task-2",
+		BankName:  "TestBank",
 		StartDate: startTime,
 		EndDate:   endTime,
 	}
@@ -99,19 +107,19 @@ func TestReconciliationUsecase_ProcessEvent(t *testing.T) {
 
 	// Test data
 	internalTransactions := []model.Transaction{
-		{ID: "tx1", Amount: 100, TransactionTime: startTime.Add(24 * time.Hour), Type: "CREDIT"},
+		{ID: "tx1", Amount: money("100.00"), TransactionTime: startTime.Add(24 * time.Hour), Type: "CREDIT"},
 	}
 
 	// Updated bank statement to use string ID
 	bankStatements := []model.BankStatement{
-		{ID: "bs-100", Amount: 100, Date: startTime.Add(24 * time.Hour)},
+		{ID: "bs-100", Amount: money("100.00"), Date: startTime.Add(24 * time.Hour)},
 	}
 
 	// Setup expectations
-	bankRepo.EXPECT().FetchAll(startTime, endTime).Return(model.BankStatementList{
+	bankRepo.EXPECT().FetchAll("test-task-2", "TestBank", startTime, endTime).Return(model.BankStatementList{
 		BankStatements: bankStatements,
 	}, nil)
-	internalRepo.EXPECT().FetchAll(startTime, endTime).Return(model.TransactionList{
+	internalRepo.EXPECT().FetchAll("test-task-2", "TestBank", startTime, endTime).Return(model.TransactionList{
 		Transactions: internalTransactions,
 	}, nil)
 
@@ -124,7 +132,7 @@ func TestReconciliationUsecase_ProcessEvent(t *testing.T) {
 	).Return(nil)
 
 	// Test ProcessEvent
-	err = uc.ProcessEvent(eventJSON)
+	err = uc.ProcessEvent(context.Background(), model.Message{Value: eventJSON})
 	require.NoError(t, err)
 }
 
@@ -137,10 +145,11 @@ func TestReconciliationUsecase_ProcessEvent_InvalidJSON(t *testing.T) {
 	bankRepo := mockrepository.NewMockBankStatementRepository(ctrl)
 	internalRepo := mockrepository.NewMockInternalTransactionRepository(ctrl)
 	reconRepo := mockrepository.NewMockReconResultRepository(ctrl)
-	uc := usecase.NewReconciliationUsecase(internalRepo, bankRepo, reconRepo)
+	fxRepo := mockrepository.NewMockFXRateRepository(ctrl)
+	uc := newReconciliationUsecase(t, &config.Config{}, internalRepo, bankRepo, reconRepo, fxRepo)
 
 	// Test with invalid JSON
-	err := uc.ProcessEvent([]byte(`{"invalid": json`))
+	err := uc.ProcessEvent(context.Background(), model.Message{Value: []byte(`{"invalid": json`)})
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "failed to unmarshal event")
 }
@@ -154,12 +163,48 @@ func TestReconciliationUsecase_ProcessEvent_MissingFields(t *testing.T) {
 	bankRepo := mockrepository.NewMockBankStatementRepository(ctrl)
 	internalRepo := mockrepository.NewMockInternalTransactionRepository(ctrl)
 	reconRepo := mockrepository.NewMockReconResultRepository(ctrl)
-	uc := usecase.NewReconciliationUsecase(internalRepo, bankRepo, reconRepo)
+	fxRepo := mockrepository.NewMockFXRateRepository(ctrl)
+	uc := newReconciliationUsecase(t, &config.Config{}, internalRepo, bankRepo, reconRepo, fxRepo)
 
 	// Test with missing required fields
-	err := uc.ProcessEvent([]byte(`{}`))
+	err := uc.ProcessEvent(context.Background(), model.Message{Value: []byte(`{}`)})
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "startDate and endDate are required fields")
+
+	// Test without the task scope
+	err = uc.ProcessEvent(context.Background(), model.Message{Value: []byte(`{"startDate":"2023-01-01T00:00:00Z","endDate":"2023-01-31T00:00:00Z","taskID":"test-task"}`)})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "taskID and bankName are required fields")
+}
+
+// TestReconciliationUsecase_ProcessEvent_Envelope tests that events are
+// filtered and versioned through their envelope
+func TestReconciliationUsecase_ProcessEvent_Envelope(t *testing.T) {
+	// Setup
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	bankRepo := mockrepository.NewMockBankStatementRepository(ctrl)
+	internalRepo := mockrepository.NewMockInternalTransactionRepository(ctrl)
+	reconRepo := mockrepository.NewMockReconResultRepository(ctrl)
+	fxRepo := mockrepository.NewMockFXRateRepository(ctrl)
+	uc := newReconciliationUsecase(t, &config.Config{}, internalRepo, bankRepo, reconRepo, fxRepo)
+
+	// An event of another type is left to its own handler
+	compilerEvent := model.NewEventEnvelope(context.Background(), model.EventTypeCompilationRequested, "test-task")
+	err := uc.ProcessEvent(context.Background(), model.Message{Value: []byte(`{}`), Headers: compilerEvent.Headers()})
+	assert.NoError(t, err)
+
+	// A version newer than the consumer knows is rejected
+	err = uc.ProcessEvent(context.Background(), model.Message{
+		Value: []byte(`{}`),
+		Headers: map[string]string{
+			model.HeaderEventType:     model.EventTypeReconciliationRequested,
+			model.HeaderSchemaVersion: "2",
+			model.HeaderTaskID:        "test-task",
+		},
+	})
+	assert.ErrorIs(t, err, model.ErrUnsupportedEventVersion)
 }
 
 // TestReconciliationUsecase_ReconcileTransactions_RepositoryError tests error handling for repository errors
@@ -171,7 +216,8 @@ func TestReconciliationUsecase_ReconcileTransactions_RepositoryError(t *testing.
 	bankRepo := mockrepository.NewMockBankStatementRepository(ctrl)
 	internalRepo := mockrepository.NewMockInternalTransactionRepository(ctrl)
 	reconRepo := mockrepository.NewMockReconResultRepository(ctrl)
-	uc := usecase.NewReconciliationUsecase(internalRepo, bankRepo, reconRepo)
+	fxRepo := mockrepository.NewMockFXRateRepository(ctrl)
+	uc := newReconciliationUsecase(t, &config.Config{}, internalRepo, bankRepo, reconRepo, fxRepo)
 
 	startTime := time.Now().Add(-24 * time.Hour)
 	endTime := time.Now()
@@ -183,33 +229,739 @@ func TestReconciliationUsecase_ReconcileTransactions_RepositoryError(t *testing.
 	}
 
 	// Setup expectations - internal repo fails
-	internalRepo.EXPECT().FetchAll(startTime, endTime).Return(model.TransactionList{}, assert.AnError)
+	internalRepo.EXPECT().FetchAll(gomock.Any(), gomock.Any(), startTime, endTime).Return(model.TransactionList{}, assert.AnError)
 
 	// Test
-	err := uc.ReconcileTransactions(reconEvent)
+	err := uc.ReconcileTransactions(context.Background(), reconEvent)
 	assert.Error(t, err)
 	assert.Equal(t, assert.AnError, err)
 
 	// Setup expectations - bank repo fails
-	internalRepo.EXPECT().FetchAll(startTime, endTime).Return(model.TransactionList{}, nil)
-	bankRepo.EXPECT().FetchAll(startTime, endTime).Return(model.BankStatementList{}, assert.AnError)
+	internalRepo.EXPECT().FetchAll(gomock.Any(), gomock.Any(), startTime, endTime).Return(model.TransactionList{}, nil)
+	bankRepo.EXPECT().FetchAll(gomock.Any(), gomock.Any(), startTime, endTime).Return(model.BankStatementList{}, assert.AnError)
 
 	// Test
-	err = uc.ReconcileTransactions(reconEvent)
+	err = uc.ReconcileTransactions(context.Background(), reconEvent)
 	assert.Error(t, err)
 	assert.Equal(t, assert.AnError, err)
 
 	// Setup expectations - reconciliation repo fails
-	internalRepo.EXPECT().FetchAll(startTime, endTime).Return(model.TransactionList{
+	internalRepo.EXPECT().FetchAll(gomock.Any(), gomock.Any(), startTime, endTime).Return(model.TransactionList{
 		Transactions: []model.Transaction{},
 	}, nil)
-	bankRepo.EXPECT().FetchAll(startTime, endTime).Return(model.BankStatementList{
+	bankRepo.EXPECT().FetchAll(gomock.Any(), gomock.Any(), startTime, endTime).Return(model.BankStatementList{
 		BankStatements: []model.BankStatement{},
 	}, nil)
 	reconRepo.EXPECT().StoreSummary(gomock.Any(), gomock.Any(), startTime, endTime).Return(assert.AnError)
 
 	// Test
-	err = uc.ReconcileTransactions(reconEvent)
+	err = uc.ReconcileTransactions(context.Background(), reconEvent)
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "failed to store summary")
 }
+
+// TestReconciliationUsecase_ReconcileTransactions_DateTolerance tests that amounts only match within the date window
+func TestReconciliationUsecase_ReconcileTransactions_DateTolerance(t *testing.T) {
+	// Setup
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	bankRepo := mockrepository.NewMockBankStatementRepository(ctrl)
+	internalRepo := mockrepository.NewMockInternalTransactionRepository(ctrl)
+	reconRepo := mockrepository.NewMockReconResultRepository(ctrl)
+	fxRepo := mockrepository.NewMockFXRateRepository(ctrl)
+	cfg := &config.Config{
+		App: config.AppConfig{
+			Reconciliation: config.ReconciliationConfig{DateToleranceDays: 3},
+		},
+	}
+	uc := newReconciliationUsecase(t, cfg, internalRepo, bankRepo, reconRepo, fxRepo)
+
+	startTime := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	endTime := time.Date(2023, 1, 31, 23, 59, 59, 0, time.UTC)
+	day := func(d int) time.Time {
+		return time.Date(2023, 1, d, 10, 0, 0, 0, time.UTC)
+	}
+
+	// Test data - repeating amounts where only the closest dates should pair up
+	internalTransactions := []model.Transaction{
+		{ID: "jan-03", Amount: money("100.00"), TransactionTime: day(3), Type: "CREDIT"},
+		{ID: "jan-05", Amount: money("100.00"), TransactionTime: day(5), Type: "CREDIT"},
+		{ID: "jan-20", Amount: money("100.00"), TransactionTime: day(20), Type: "CREDIT"},
+	}
+	bankStatements := []model.BankStatement{
+		{ID: "bs-jan-05", Amount: money("100.00"), Date: time.Date(2023, 1, 5, 0, 0, 0, 0, time.UTC)},
+		{ID: "bs-jan-04", Amount: money("100.00"), Date: time.Date(2023, 1, 4, 0, 0, 0, 0, time.UTC)},
+		{ID: "bs-jan-28", Amount: money("100.00"), Date: time.Date(2023, 1, 28, 0, 0, 0, 0, time.UTC)},
+	}
+
+	bankRepo.EXPECT().FetchAll(gomock.Any(), gomock.Any(), startTime, endTime).Return(model.BankStatementList{
+		BankStatements: bankStatements,
+	}, nil)
+	internalRepo.EXPECT().FetchAll(gomock.Any(), gomock.Any(), startTime, endTime).Return(model.TransactionList{
+		Transactions: internalTransactions,
+	}, nil)
+
+	var stored model.ReconciliationSummary
+	reconRepo.EXPECT().StoreSummary(gomock.Any(), gomock.Any(), startTime, endTime).
+		DoAndReturn(func(_ any, summary model.ReconciliationSummary, _, _ time.Time) error {
+			stored = summary
+			return nil
+		})
+
+	err := uc.ReconcileTransactions(context.Background(), model.ReconciliationEvent{
+		TaskID:    "test-task-tolerance",
+		StartDate: startTime,
+		EndDate:   endTime,
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, 2, stored.TotalMatched)
+	require.Len(t, stored.UnmatchedInternal, 1)
+	assert.Equal(t, "jan-20", stored.UnmatchedInternal[0].ID)
+	require.Len(t, stored.UnmatchedBank, 1)
+	assert.Equal(t, "bs-jan-28", stored.UnmatchedBank[0].ID)
+	assert.Equal(t, 4, stored.TotalTransaction)
+}
+
+// TestReconciliationUsecase_ReconcileTransactions_ReferenceFirst tests that references are matched before amounts
+func TestReconciliationUsecase_ReconcileTransactions_ReferenceFirst(t *testing.T) {
+	// Setup
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	bankRepo := mockrepository.NewMockBankStatementRepository(ctrl)
+	internalRepo := mockrepository.NewMockInternalTransactionRepository(ctrl)
+	reconRepo := mockrepository.NewMockReconResultRepository(ctrl)
+	fxRepo := mockrepository.NewMockFXRateRepository(ctrl)
+	cfg := &config.Config{
+		App: config.AppConfig{
+			Reconciliation: config.ReconciliationConfig{
+				ReferenceNormalization: usecase.ReferenceNormalizationAlphanumeric,
+			},
+		},
+	}
+	uc := newReconciliationUsecase(t, cfg, internalRepo, bankRepo, reconRepo, fxRepo)
+
+	startTime := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	endTime := time.Date(2023, 1, 31, 23, 59, 59, 0, time.UTC)
+	cTime := time.Date(2023, 1, 15, 12, 0, 0, 0, time.UTC)
+
+	// Test data - the reference pass pairs records even when the amounts differ,
+	// so the leftover 100.00 records are the only ones left for amount matching.
+	// The difference of a pair is recorded on its match.
+	internalTransactions := []model.Transaction{
+		{ID: "PAY-0012", Amount: money("250.00"), TransactionTime: cTime, Type: "CREDIT"},
+		{ID: "PAY-0001", Amount: money("100.00"), TransactionTime: cTime, Type: "CREDIT"},
+		{ID: "PAY-0002", Amount: money("100.00"), TransactionTime: cTime, Type: "CREDIT"},
+		{ID: "PAY-0004", Amount: money("95.00"), TransactionTime: cTime, Type: "CREDIT"},
+	}
+	bankStatements := []model.BankStatement{
+		{ID: "bs-1", Amount: money("100.00"), Date: cTime, Reference: "transfer pay0001"},
+		{ID: "bs-2", Amount: money("249.50"), Date: cTime, Reference: "Settlement PAY 0012 fee"},
+		{ID: "bs-3", Amount: money("100.00"), Date: cTime, Reference: "unknown"},
+		{ID: "bs-4", Amount: money("90.00"), Date: cTime, Reference: "PAY-0004 partial"},
+	}
+
+	bankRepo.EXPECT().FetchAll(gomock.Any(), gomock.Any(), startTime, endTime).Return(model.BankStatementList{
+		BankStatements: bankStatements,
+	}, nil)
+	internalRepo.EXPECT().FetchAll(gomock.Any(), gomock.Any(), startTime, endTime).Return(model.TransactionList{
+		Transactions: internalTransactions,
+	}, nil)
+
+	var stored model.ReconciliationSummary
+	reconRepo.EXPECT().StoreSummary(gomock.Any(), gomock.Any(), startTime, endTime).
+		DoAndReturn(func(_ any, summary model.ReconciliationSummary, _, _ time.Time) error {
+			stored = summary
+			return nil
+		})
+
+	err := uc.ReconcileTransactions(context.Background(), model.ReconciliationEvent{
+		TaskID:    "test-task-reference",
+		StartDate: startTime,
+		EndDate:   endTime,
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, 4, stored.TotalMatched)
+	assert.Empty(t, stored.UnmatchedInternal)
+	assert.Empty(t, stored.UnmatchedBank)
+
+	// The rule that produced each match is recorded, with the difference of
+	// the amounts
+	require.Len(t, stored.Matches, 4)
+	assert.Equal(t, usecase.MatcherExactReference, stored.Matches[0].Rule)
+	assert.Equal(t, "PAY-0001", stored.Matches[0].Transactions[0].ID)
+	assert.Equal(t, moneyRef("0.00"), stored.Matches[0].Difference)
+	assert.Equal(t, usecase.MatcherExactReference, stored.Matches[1].Rule)
+	assert.Equal(t, "PAY-0012", stored.Matches[1].Transactions[0].ID)
+	assert.Equal(t, moneyRef("-0.50"), stored.Matches[1].Difference)
+	assert.Equal(t, usecase.MatcherExactReference, stored.Matches[2].Rule)
+	assert.Equal(t, "PAY-0004", stored.Matches[2].Transactions[0].ID)
+	assert.Equal(t, moneyRef("-5.00"), stored.Matches[2].Difference)
+	assert.Equal(t, usecase.MatcherAmountDate, stored.Matches[3].Rule)
+	assert.Equal(t, "bs-3", stored.Matches[3].BankStatements[0].ID)
+}
+
+// TestReconciliationUsecase_ReconcileTransactions_Aggregate tests many-to-one and one-to-many grouping
+func TestReconciliationUsecase_ReconcileTransactions_Aggregate(t *testing.T) {
+	// Setup
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	bankRepo := mockrepository.NewMockBankStatementRepository(ctrl)
+	internalRepo := mockrepository.NewMockInternalTransactionRepository(ctrl)
+	reconRepo := mockrepository.NewMockReconResultRepository(ctrl)
+	fxRepo := mockrepository.NewMockFXRateRepository(ctrl)
+	cfg := &config.Config{
+		App: config.AppConfig{
+			Reconciliation: config.ReconciliationConfig{
+				DateToleranceDays: 2,
+				MatcherChain:      []string{usecase.MatcherExactReference, usecase.MatcherAmountDate, usecase.MatcherAggregate},
+				Aggregate: config.AggregateConfig{
+					MaxGroupSize:  3,
+					SearchTimeout: time.Second,
+				},
+			},
+		},
+	}
+	uc := newReconciliationUsecase(t, cfg, internalRepo, bankRepo, reconRepo, fxRepo)
+
+	startTime := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	endTime := time.Date(2023, 1, 31, 23, 59, 59, 0, time.UTC)
+	day := func(d int) time.Time {
+		return time.Date(2023, 1, d, 0, 0, 0, 0, time.UTC)
+	}
+
+	// Test data
+	internalTransactions := []model.Transaction{
+		// Settled together as bs-lump
+		{ID: "tx-a", Amount: money("10.10"), TransactionTime: day(10), Type: "CREDIT"},
+		{ID: "tx-b", Amount: money("20.20"), TransactionTime: day(11), Type: "CREDIT"},
+		{ID: "tx-c", Amount: money("30.30"), TransactionTime: day(11), Type: "CREDIT"},
+		// Too far from bs-lump to be part of the group
+		{ID: "tx-late", Amount: money("30.30"), TransactionTime: day(25), Type: "CREDIT"},
+		// Split across bs-split-1 and bs-split-2
+		{ID: "tx-split", Amount: money("75.00"), TransactionTime: day(15), Type: "DEBIT"},
+	}
+	bankStatements := []model.BankStatement{
+		{ID: "bs-lump", Amount: money("60.60"), Date: day(12)},
+		{ID: "bs-split-1", Amount: money("-25.00"), Date: day(15)},
+		{ID: "bs-split-2", Amount: money("-50.00"), Date: day(16)},
+	}
+
+	bankRepo.EXPECT().FetchAll(gomock.Any(), gomock.Any(), startTime, endTime).Return(model.BankStatementList{
+		BankStatements: bankStatements,
+	}, nil)
+	internalRepo.EXPECT().FetchAll(gomock.Any(), gomock.Any(), startTime, endTime).Return(model.TransactionList{
+		Transactions: internalTransactions,
+	}, nil)
+
+	var stored model.ReconciliationSummary
+	reconRepo.EXPECT().StoreSummary(gomock.Any(), gomock.Any(), startTime, endTime).
+		DoAndReturn(func(_ any, summary model.ReconciliationSummary, _, _ time.Time) error {
+			stored = summary
+			return nil
+		})
+
+	err := uc.ReconcileTransactions(context.Background(), model.ReconciliationEvent{
+		TaskID:    "test-task-aggregate",
+		StartDate: startTime,
+		EndDate:   endTime,
+	})
+	require.NoError(t, err)
+
+	require.Len(t, stored.Matches, 2)
+	// The three transactions of bs-lump and the two bank rows of tx-split
+	assert.Equal(t, 5, stored.TotalMatched)
+	assert.Equal(t, 6, stored.TotalTransaction)
+
+	manyToOne := stored.Matches[0]
+	assert.Equal(t, usecase.MatcherAggregate, manyToOne.Rule)
+	assert.Equal(t, model.MatchManyToOne, manyToOne.Type)
+	assert.ElementsMatch(t, []string{"tx-a", "tx-b", "tx-c"}, transactionIDs(manyToOne.Transactions))
+	require.Len(t, manyToOne.BankStatements, 1)
+	assert.Equal(t, "bs-lump", manyToOne.BankStatements[0].ID)
+
+	oneToMany := stored.Matches[1]
+	assert.Equal(t, usecase.MatcherAggregate, oneToMany.Rule)
+	assert.Equal(t, model.MatchOneToMany, oneToMany.Type)
+	require.Len(t, oneToMany.Transactions, 1)
+	assert.Equal(t, "tx-split", oneToMany.Transactions[0].ID)
+	assert.Len(t, oneToMany.BankStatements, 2)
+
+	require.Len(t, stored.UnmatchedInternal, 1)
+	assert.Equal(t, "tx-late", stored.UnmatchedInternal[0].ID)
+	assert.Empty(t, stored.UnmatchedBank)
+}
+
+// TestReconciliationUsecase_ExactDiscrepancy tests that the discrepancy total
+// does not drift when many small unmatched amounts are summed
+func TestReconciliationUsecase_ExactDiscrepancy(t *testing.T) {
+	// Setup
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	bankRepo := mockrepository.NewMockBankStatementRepository(ctrl)
+	internalRepo := mockrepository.NewMockInternalTransactionRepository(ctrl)
+	reconRepo := mockrepository.NewMockReconResultRepository(ctrl)
+	fxRepo := mockrepository.NewMockFXRateRepository(ctrl)
+	uc := newReconciliationUsecase(t, &config.Config{}, internalRepo, bankRepo, reconRepo, fxRepo)
+
+	startTime := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	endTime := time.Date(2023, 1, 31, 23, 59, 59, 0, time.UTC)
+	cTime := time.Date(2023, 1, 15, 12, 0, 0, 0, time.UTC)
+
+	// Test data - summing 0.10 as float64 ten thousand times gives 1000.0000000001588
+	internalTransactions := make([]model.Transaction, 10000)
+	for i := range internalTransactions {
+		internalTransactions[i] = model.Transaction{
+			ID:              fmt.Sprintf("tx-%d", i),
+			Amount:          money("0.10"),
+			TransactionTime: cTime,
+			Type:            "CREDIT",
+		}
+	}
+
+	bankRepo.EXPECT().FetchAll(gomock.Any(), gomock.Any(), startTime, endTime).Return(model.BankStatementList{}, nil)
+	internalRepo.EXPECT().FetchAll(gomock.Any(), gomock.Any(), startTime, endTime).Return(model.TransactionList{
+		Transactions: internalTransactions,
+	}, nil)
+
+	var stored model.ReconciliationSummary
+	reconRepo.EXPECT().StoreSummary(gomock.Any(), gomock.Any(), startTime, endTime).
+		DoAndReturn(func(_ any, summary model.ReconciliationSummary, _, _ time.Time) error {
+			stored = summary
+			return nil
+		})
+
+	// Execute
+	err := uc.ReconcileTransactions(context.Background(), model.ReconciliationEvent{
+		TaskID:    "test-task-exact",
+		StartDate: startTime,
+		EndDate:   endTime,
+	})
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, money("1000.00"), stored.TotalDiscrepancy)
+	assert.Equal(t, "1000.00", stored.TotalDiscrepancy.String())
+}
+
+// TestReconciliationUsecase_ReconcileTransactions_MultiCurrency tests that
+// records in different currencies are paired through the FX rate table and
+// that the discrepancy is reported in the base currency
+func TestReconciliationUsecase_ReconcileTransactions_MultiCurrency(t *testing.T) {
+	// Setup
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	bankRepo := mockrepository.NewMockBankStatementRepository(ctrl)
+	internalRepo := mockrepository.NewMockInternalTransactionRepository(ctrl)
+	reconRepo := mockrepository.NewMockReconResultRepository(ctrl)
+	fxRepo := mockrepository.NewMockFXRateRepository(ctrl)
+	cfg := &config.Config{
+		App: config.AppConfig{
+			Reconciliation: config.ReconciliationConfig{
+				DateToleranceDays: 2,
+				FX: config.FXConfig{
+					BaseCurrency:         "USD",
+					ToleranceBasisPoints: 50,
+					RateLookbackDays:     3,
+				},
+			},
+		},
+	}
+	uc := newReconciliationUsecase(t, cfg, internalRepo, bankRepo, reconRepo, fxRepo)
+
+	startTime := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	endTime := time.Date(2023, 1, 31, 23, 59, 59, 0, time.UTC)
+	day := func(d int) time.Time {
+		return time.Date(2023, 1, d, 0, 0, 0, 0, time.UTC)
+	}
+
+	// Test data - transactions without a currency are in the base currency
+	internalTransactions := []model.Transaction{
+		{ID: "tx-usd", Amount: money("100.00"), TransactionTime: day(10), Type: "CREDIT"},
+		{ID: "tx-jpy", Amount: model.MustParseMoney("15000", "JPY"), TransactionTime: day(12), Type: "CREDIT"},
+		{ID: "tx-unmatched", Amount: model.MustParseMoney("50.00", "EUR"), TransactionTime: day(20), Type: "CREDIT"},
+	}
+	bankStatements := []model.BankStatement{
+		// 100.00 USD at 0.92 is 92.00 EUR, 92.30 is within 50 basis points
+		{ID: "bs-eur", Amount: model.MustParseMoney("92.30", "EUR"), Date: day(11)},
+		// Only USD/JPY is published, the rate is inverted
+		{ID: "bs-usd", Amount: money("100.00"), Date: day(13)},
+		// 93.00 EUR is too far from 92.00 EUR
+		{ID: "bs-off", Amount: model.MustParseMoney("93.00", "EUR"), Date: day(10)},
+		{ID: "bs-fee", Amount: money("-5.00"), Date: day(25)},
+	}
+	rates := []model.FXRate{
+		{BaseCurrency: "USD", QuoteCurrency: "EUR", Date: day(9), Rate: big.NewRat(92, 100)},
+		{BaseCurrency: "USD", QuoteCurrency: "EUR", Date: day(19), Rate: big.NewRat(80, 100)},
+		{BaseCurrency: "USD", QuoteCurrency: "JPY", Date: day(12), Rate: big.NewRat(150, 1)},
+	}
+
+	bankRepo.EXPECT().FetchAll(gomock.Any(), gomock.Any(), startTime, endTime).Return(model.BankStatementList{
+		BankStatements: bankStatements,
+	}, nil)
+	internalRepo.EXPECT().FetchAll(gomock.Any(), gomock.Any(), startTime, endTime).Return(model.TransactionList{
+		Transactions: internalTransactions,
+	}, nil)
+	fxRepo.EXPECT().FetchRates(gomock.Any(), startTime.AddDate(0, 0, -5), endTime.AddDate(0, 0, 2)).Return(rates, nil)
+
+	var stored model.ReconciliationSummary
+	reconRepo.EXPECT().StoreSummary(gomock.Any(), gomock.Any(), startTime, endTime).
+		DoAndReturn(func(_ any, summary model.ReconciliationSummary, _, _ time.Time) error {
+			stored = summary
+			return nil
+		})
+
+	// Execute
+	err := uc.ReconcileTransactions(context.Background(), model.ReconciliationEvent{
+		TaskID:    "test-task-fx",
+		StartDate: startTime,
+		EndDate:   endTime,
+	})
+
+	// Assert
+	require.NoError(t, err)
+	require.Len(t, stored.Matches, 2)
+	matched := make(map[string]string)
+	for _, match := range stored.Matches {
+		assert.Equal(t, usecase.MatcherFXAmountDate, match.Rule)
+		matched[match.Transactions[0].ID] = match.BankStatements[0].ID
+	}
+	assert.Equal(t, map[string]string{"tx-usd": "bs-eur", "tx-jpy": "bs-usd"}, matched)
+
+	require.Len(t, stored.UnmatchedInternal, 1)
+	assert.Equal(t, "tx-unmatched", stored.UnmatchedInternal[0].ID)
+	require.Len(t, stored.UnmatchedBank, 2)
+	assert.ElementsMatch(t, []string{"bs-off", "bs-fee"}, []string{stored.UnmatchedBank[0].ID, stored.UnmatchedBank[1].ID})
+
+	// 93.00 EUR at 0.92 is 101.09 USD, 50.00 EUR at 0.80 is 62.50 USD, less the
+	// 5.00 USD fee
+	assert.Equal(t, model.MustParseMoney("158.59", "USD"), stored.TotalDiscrepancy)
+}
+
+// TestReconciliationUsecase_ReconcileTransactions_MissingRate tests that a
+// currency without an FX rate leaves the discrepancy incomplete rather than
+// failing the reconciliation
+func TestReconciliationUsecase_ReconcileTransactions_MissingRate(t *testing.T) {
+	// Setup
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	bankRepo := mockrepository.NewMockBankStatementRepository(ctrl)
+	internalRepo := mockrepository.NewMockInternalTransactionRepository(ctrl)
+	reconRepo := mockrepository.NewMockReconResultRepository(ctrl)
+	fxRepo := mockrepository.NewMockFXRateRepository(ctrl)
+	cfg := &config.Config{
+		App: config.AppConfig{
+			Reconciliation: config.ReconciliationConfig{
+				FX: config.FXConfig{
+					BaseCurrency:     "USD",
+					RateLookbackDays: 3,
+				},
+			},
+		},
+	}
+	uc := newReconciliationUsecase(t, cfg, internalRepo, bankRepo, reconRepo, fxRepo)
+
+	startTime := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	endTime := time.Date(2023, 1, 31, 23, 59, 59, 0, time.UTC)
+	day := func(d int) time.Time {
+		return time.Date(2023, 1, d, 0, 0, 0, 0, time.UTC)
+	}
+
+	// Test data - only USD/EUR is published
+	internalTransactions := []model.Transaction{
+		{ID: "tx-usd", Amount: money("10.00"), TransactionTime: day(10), Type: "CREDIT"},
+		{ID: "tx-eur", Amount: model.MustParseMoney("20.00", "EUR"), TransactionTime: day(10), Type: "CREDIT"},
+		{ID: "tx-gbp", Amount: model.MustParseMoney("50.00", "GBP"), TransactionTime: day(10), Type: "CREDIT"},
+	}
+	bankStatements := []model.BankStatement{
+		{ID: "bs-usd", Amount: money("10.00"), Date: day(10)},
+		{ID: "bs-fee", Amount: money("-5.00"), Date: day(10)},
+	}
+	rates := []model.FXRate{
+		{BaseCurrency: "USD", QuoteCurrency: "EUR", Date: day(9), Rate: big.NewRat(80, 100)},
+	}
+
+	bankRepo.EXPECT().FetchAll(gomock.Any(), gomock.Any(), startTime, endTime).Return(model.BankStatementList{
+		BankStatements: bankStatements,
+	}, nil)
+	internalRepo.EXPECT().FetchAll(gomock.Any(), gomock.Any(), startTime, endTime).Return(model.TransactionList{
+		Transactions: internalTransactions,
+	}, nil)
+	fxRepo.EXPECT().FetchRates(gomock.Any(), gomock.Any(), gomock.Any()).Return(rates, nil)
+
+	var stored model.ReconciliationSummary
+	reconRepo.EXPECT().StoreSummary(gomock.Any(), gomock.Any(), startTime, endTime).
+		DoAndReturn(func(_ any, summary model.ReconciliationSummary, _, _ time.Time) error {
+			stored = summary
+			return nil
+		})
+
+	// Execute
+	err := uc.ReconcileTransactions(context.Background(), model.ReconciliationEvent{
+		TaskID:    "test-task-missing-rate",
+		StartDate: startTime,
+		EndDate:   endTime,
+	})
+
+	// Assert
+	require.NoError(t, err)
+	require.Len(t, stored.Matches, 1)
+	assert.Equal(t, "tx-usd", stored.Matches[0].Transactions[0].ID)
+	assert.ElementsMatch(t, []string{"tx-eur", "tx-gbp"}, transactionIDs(stored.UnmatchedInternal))
+	assert.Equal(t, 4, stored.TotalTransaction)
+
+	// 20.00 EUR at 0.80 is 25.00 USD, less the 5.00 USD fee, the GBP amount
+	// being left out
+	assert.Equal(t, model.MustParseMoney("20.00", "USD"), stored.TotalDiscrepancy)
+	assert.Equal(t, []string{"GBP"}, stored.MissingRates)
+}
+
+// TestReconciliationUsecase_TaskStatus tests that the task moves to RECONCILING
+// and then COMPLETED, or FAILED with the error
+func TestReconciliationUsecase_TaskStatus(t *testing.T) {
+	startTime := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	endTime := time.Date(2023, 1, 31, 23, 59, 59, 0, time.UTC)
+	event := model.ReconciliationEvent{
+		TaskID:    "test-task-status",
+		BankName:  "TestBank",
+		StartDate: startTime,
+		EndDate:   endTime,
+	}
+
+	newUsecase := func(ctrl *gomock.Controller) (*usecase.ReconciliationUsecase, *mockrepository.MockInternalTransactionRepository, *mockrepository.MockBankStatementRepository, *mockrepository.MockReconResultRepository, *mockrepository.MockReconTaskRepository, *mockrepository.MockOutboxRepository) {
+		internalRepo := mockrepository.NewMockInternalTransactionRepository(ctrl)
+		bankRepo := mockrepository.NewMockBankStatementRepository(ctrl)
+		reconRepo := mockrepository.NewMockReconResultRepository(ctrl)
+		taskRepo := mockrepository.NewMockReconTaskRepository(ctrl)
+		outboxRepo := mockrepository.NewMockOutboxRepository(ctrl)
+		matchers, err := usecase.BuildMatcherChain(config.ReconciliationConfig{})
+		require.NoError(t, err)
+		cfg := &config.Config{Kafka: config.KafkaConfig{Topic: config.TopicConfig{ResultTopic: "result-topic"}}}
+		uc := usecase.NewReconciliationUsecase(cfg, matchers, internalRepo, bankRepo, reconRepo, mockrepository.NewMockFXRateRepository(ctrl), taskRepo, newTransactor(ctrl), outboxRepo)
+		return uc, internalRepo, bankRepo, reconRepo, taskRepo, outboxRepo
+	}
+
+	t.Run("Completed", func(t *testing.T) {
+		// Setup
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		uc, internalRepo, bankRepo, reconRepo, taskRepo, outboxRepo := newUsecase(ctrl)
+
+		gomock.InOrder(
+			taskRepo.EXPECT().Get(gomock.Any(), event.TaskID).Return(model.ReconTask{TaskID: event.TaskID, Status: model.TaskCompiled}, nil),
+			taskRepo.EXPECT().UpdateStatus(gomock.Any(), event.TaskID, model.TaskReconciling, "").Return(nil),
+			internalRepo.EXPECT().FetchAll(event.TaskID, event.BankName, startTime, endTime).Return(model.TransactionList{
+				Transactions: []model.Transaction{{ID: "tx1", Amount: money("10.00"), TransactionTime: startTime, Type: "CREDIT"}},
+			}, nil),
+			bankRepo.EXPECT().FetchAll(event.TaskID, event.BankName, startTime, endTime).Return(model.BankStatementList{}, nil),
+			reconRepo.EXPECT().StoreSummary(gomock.Any(), gomock.Any(), startTime, endTime).Return(nil),
+			outboxRepo.EXPECT().
+				Add(gomock.Any(), gomock.Any()).
+				DoAndReturn(func(_ context.Context, outboxEvent model.OutboxEvent) error {
+					assert.Equal(t, "result-topic", outboxEvent.Topic)
+					assert.Equal(t, model.EventTypeReconciliationCompleted, outboxEvent.Envelope.EventType)
+					assert.Equal(t, event.TaskID, outboxEvent.Envelope.TaskID)
+
+					var completed model.ReconciliationCompletedEvent
+					require.NoError(t, json.Unmarshal(outboxEvent.Payload, &completed))
+					assert.Equal(t, event.TaskID, completed.TaskID)
+					assert.Equal(t, event.BankName, completed.BankName)
+					assert.Equal(t, 1, completed.TotalTransaction)
+					assert.Equal(t, 0, completed.TotalMatched)
+					assert.Equal(t, 1, completed.TotalUnmatchedInternal)
+					assert.Equal(t, 0, completed.TotalUnmatchedBank)
+					assert.Equal(t, money("10.00").String(), completed.TotalDiscrepancy.String())
+					return nil
+				}),
+			taskRepo.EXPECT().UpdateStatus(gomock.Any(), event.TaskID, model.TaskCompleted, "").Return(nil),
+		)
+
+		// Execute
+		err := uc.ReconcileTransactions(context.Background(), event)
+
+		// Assert
+		require.NoError(t, err)
+	})
+
+	t.Run("Failed", func(t *testing.T) {
+		// Setup
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		uc, internalRepo, bankRepo, reconRepo, taskRepo, _ := newUsecase(ctrl)
+
+		gomock.InOrder(
+			taskRepo.EXPECT().Get(gomock.Any(), event.TaskID).Return(model.ReconTask{TaskID: event.TaskID, Status: model.TaskCompiled}, nil),
+			taskRepo.EXPECT().UpdateStatus(gomock.Any(), event.TaskID, model.TaskReconciling, "").Return(nil),
+			internalRepo.EXPECT().FetchAll(event.TaskID, event.BankName, startTime, endTime).Return(model.TransactionList{}, nil),
+			bankRepo.EXPECT().FetchAll(event.TaskID, event.BankName, startTime, endTime).Return(model.BankStatementList{}, nil),
+			reconRepo.EXPECT().StoreSummary(gomock.Any(), gomock.Any(), startTime, endTime).Return(errors.New("database error")),
+			taskRepo.EXPECT().
+				UpdateStatus(gomock.Any(), event.TaskID, model.TaskFailed, gomock.Any()).
+				DoAndReturn(func(_ context.Context, _ string, _ model.TaskStatus, message string) error {
+					assert.Contains(t, message, "database error")
+					return nil
+				}),
+		)
+
+		// Execute
+		err := uc.ReconcileTransactions(context.Background(), event)
+
+		// Assert
+		assert.Error(t, err)
+	})
+
+	t.Run("Error storing completed event", func(t *testing.T) {
+		// Setup
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		uc, internalRepo, bankRepo, reconRepo, taskRepo, outboxRepo := newUsecase(ctrl)
+
+		gomock.InOrder(
+			taskRepo.EXPECT().Get(gomock.Any(), event.TaskID).Return(model.ReconTask{TaskID: event.TaskID, Status: model.TaskCompiled}, nil),
+			taskRepo.EXPECT().UpdateStatus(gomock.Any(), event.TaskID, model.TaskReconciling, "").Return(nil),
+			internalRepo.EXPECT().FetchAll(event.TaskID, event.BankName, startTime, endTime).Return(model.TransactionList{}, nil),
+			bankRepo.EXPECT().FetchAll(event.TaskID, event.BankName, startTime, endTime).Return(model.BankStatementList{}, nil),
+			reconRepo.EXPECT().StoreSummary(gomock.Any(), gomock.Any(), startTime, endTime).Return(nil),
+			outboxRepo.EXPECT().Add(gomock.Any(), gomock.Any()).Return(errors.New("database error")),
+			taskRepo.EXPECT().UpdateStatus(gomock.Any(), event.TaskID, model.TaskFailed, gomock.Any()).Return(nil),
+		)
+
+		// Execute
+		err := uc.ReconcileTransactions(context.Background(), event)
+
+		// Assert
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to store completed event")
+	})
+
+	t.Run("Error marking task as completed", func(t *testing.T) {
+		// Setup
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		uc, internalRepo, bankRepo, reconRepo, taskRepo, outboxRepo := newUsecase(ctrl)
+
+		gomock.InOrder(
+			taskRepo.EXPECT().Get(gomock.Any(), event.TaskID).Return(model.ReconTask{TaskID: event.TaskID, Status: model.TaskCompiled}, nil),
+			taskRepo.EXPECT().UpdateStatus(gomock.Any(), event.TaskID, model.TaskReconciling, "").Return(nil),
+			internalRepo.EXPECT().FetchAll(event.TaskID, event.BankName, startTime, endTime).Return(model.TransactionList{}, nil),
+			bankRepo.EXPECT().FetchAll(event.TaskID, event.BankName, startTime, endTime).Return(model.BankStatementList{}, nil),
+			reconRepo.EXPECT().StoreSummary(gomock.Any(), gomock.Any(), startTime, endTime).Return(nil),
+			outboxRepo.EXPECT().Add(gomock.Any(), gomock.Any()).Return(nil),
+			taskRepo.EXPECT().UpdateStatus(gomock.Any(), event.TaskID, model.TaskCompleted, "").Return(errors.New("database error")),
+			taskRepo.EXPECT().UpdateStatus(gomock.Any(), event.TaskID, model.TaskFailed, gomock.Any()).Return(nil),
+		)
+
+		// Execute
+		err := uc.ReconcileTransactions(context.Background(), event)
+
+		// Assert
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to mark task as completed")
+	})
+
+	t.Run("Task in another state", func(t *testing.T) {
+		// Setup
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		uc, _, _, _, taskRepo, _ := newUsecase(ctrl)
+
+		taskRepo.EXPECT().Get(gomock.Any(), event.TaskID).Return(model.ReconTask{TaskID: event.TaskID, Status: model.TaskUploaded}, nil)
+		taskRepo.EXPECT().
+			UpdateStatus(gomock.Any(), event.TaskID, model.TaskReconciling, "").
+			Return(model.ErrInvalidTaskTransition)
+
+		// Execute
+		err := uc.ReconcileTransactions(context.Background(), event)
+
+		// Assert
+		assert.True(t, errors.Is(err, model.ErrInvalidTaskTransition))
+	})
+
+	t.Run("Redelivered after completion", func(t *testing.T) {
+		// Setup
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		uc, _, _, _, taskRepo, _ := newUsecase(ctrl)
+
+		// Nothing is fetched or stored again
+		taskRepo.EXPECT().Get(gomock.Any(), event.TaskID).Return(model.ReconTask{TaskID: event.TaskID, Status: model.TaskCompleted}, nil)
+
+		// Execute
+		err := uc.ReconcileTransactions(context.Background(), event)
+
+		// Assert
+		assert.NoError(t, err)
+	})
+
+	t.Run("Error getting task", func(t *testing.T) {
+		// Setup
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		uc, _, _, _, taskRepo, _ := newUsecase(ctrl)
+
+		taskRepo.EXPECT().Get(gomock.Any(), event.TaskID).Return(model.ReconTask{}, model.ErrTaskNotFound)
+
+		// Execute
+		err := uc.ReconcileTransactions(context.Background(), event)
+
+		// Assert
+		assert.True(t, errors.Is(err, model.ErrTaskNotFound))
+	})
+	t.Run("Undecodable payload", func(t *testing.T) {
+		// Setup
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		uc, _, _, _, taskRepo, _ := newUsecase(ctrl)
+
+		// The task is known from the envelope
+		envelope := model.NewEventEnvelope(context.Background(), model.EventTypeReconciliationRequested, event.TaskID)
+		taskRepo.EXPECT().UpdateStatus(gomock.Any(), event.TaskID, model.TaskFailed, gomock.Any()).Return(nil)
+
+		// Execute
+		err := uc.ProcessEvent(context.Background(), model.Message{Value: []byte(`{"invalid": json`), Headers: envelope.Headers()})
+
+		// Assert
+		assert.ErrorContains(t, err, "failed to unmarshal event")
+	})
+}
+
+// money parses a test amount without a currency
+func money(amount string) model.Money {
+	return model.MustParseMoney(amount, "")
+}
+
+// moneyRef parses a test amount without a currency and returns its address
+func moneyRef(amount string) *model.Money {
+	parsed := money(amount)
+	return &parsed
+}
+
+func transactionIDs(transactions []model.Transaction) []string {
+	ids := make([]string, len(transactions))
+	for i, transaction := range transactions {
+		ids[i] = transaction.ID
+	}
+	return ids
+}
+
+// newReconciliationUsecase builds the usecase with the matcher chain from cfg
+func newReconciliationUsecase(t *testing.T, cfg *config.Config, internalRepo *mockrepository.MockInternalTransactionRepository, bankRepo *mockrepository.MockBankStatementRepository, reconRepo *mockrepository.MockReconResultRepository, fxRepo *mockrepository.MockFXRateRepository) *usecase.ReconciliationUsecase {
+	matchers, err := usecase.BuildMatcherChain(cfg.App.Reconciliation)
+	require.NoError(t, err)
+
+	// The task lifecycle is covered by TestReconciliationUsecase_TaskStatus
+	taskRepo := mockrepository.NewMockReconTaskRepository(gomock.NewController(t))
+	taskRepo.EXPECT().Get(gomock.Any(), gomock.Any()).Return(model.ReconTask{Status: model.TaskCompiled}, nil).AnyTimes()
+	taskRepo.EXPECT().UpdateStatus(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	outboxRepo := mockrepository.NewMockOutboxRepository(gomock.NewController(t))
+	outboxRepo.EXPECT().Add(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+
+	return usecase.NewReconciliationUsecase(cfg, matchers, internalRepo, bankRepo, reconRepo, fxRepo, taskRepo, newTransactor(gomock.NewController(t)), outboxRepo)
+}
@@ -0,0 +1,221 @@
+package usecase
+
+import (
+	"strings"
+	"time"
+
+	"github.com/aferryc/yars/model"
+	"github.com/pkg/errors"
+)
+
+// RecordParser parses the records of a CSV file laid out as a model.CSVFormat
+type RecordParser struct {
+	format  model.CSVFormat
+	columns map[string]int
+	debits  map[string]bool
+}
+
+// NewRecordParser resolves the columns of format. Columns located by name are
+// looked up in header, ignoring case and surrounding spaces.
+func NewRecordParser(format model.CSVFormat, header []string) (*RecordParser, error) {
+	names := make(map[string]int, len(header))
+	for i, name := range header {
+		if i == 0 {
+			// Spreadsheet exports often start with a byte order mark
+			name = strings.TrimPrefix(name, "\ufeff")
+		}
+		name = strings.ToLower(strings.TrimSpace(name))
+		if _, ok := names[name]; !ok {
+			names[name] = i
+		}
+	}
+
+	columns := make(map[string]int, len(format.Columns))
+	for field, column := range format.Columns {
+		switch {
+		case column.Index != nil:
+			columns[field] = *column.Index
+		case column.Name != "":
+			index, ok := names[strings.ToLower(strings.TrimSpace(column.Name))]
+			if !ok {
+				return nil, errors.Wrapf(model.ErrInvalidIngestionProfile, "[NewRecordParser] column %q of %s not found in header", column.Name, field)
+			}
+			columns[field] = index
+		}
+	}
+
+	debits := make(map[string]bool, len(format.DebitIndicators))
+	for _, indicator := range format.DebitIndicators {
+		debits[strings.ToLower(strings.TrimSpace(indicator))] = true
+	}
+
+	return &RecordParser{
+		format:  format,
+		columns: columns,
+		debits:  debits,
+	}, nil
+}
+
+// Transaction parses a record of an internal transaction file
+func (p *RecordParser) Transaction(record []string) (model.Transaction, error) {
+	id := p.field(record, model.FieldID)
+	if id == "" {
+		return model.Transaction{}, errors.Wrap(errors.New("invalid record format"), "[parseTransactionRecord] error parsing transaction record")
+	}
+
+	currency, err := parseCurrency(p.field(record, model.FieldCurrency))
+	if err != nil {
+		return model.Transaction{}, errors.Wrap(err, "[parseTransactionRecord] error parsing currency")
+	}
+
+	amount, err := p.amount(record, currency)
+	if err != nil {
+		return model.Transaction{}, errors.Wrap(err, "[parseTransactionRecord] error parsing amount")
+	}
+
+	txTime, err := p.parseTime(p.field(record, model.FieldTransactionTime))
+	if err != nil {
+		return model.Transaction{}, errors.Wrap(err, "[parseTransactionRecord] error parsing transaction time")
+	}
+
+	// Transaction amounts are absolute, SignedAmount taking the sign from the
+	// type. Formats giving the sign through an indicator, separate columns or
+	// a signed amount without a type column have the type derived from it.
+	txType := p.field(record, model.FieldType)
+	_, hasType := p.columns[model.FieldType]
+	if p.format.SignConvention == model.SignConventionIndicator ||
+		p.format.SignConvention == model.SignConventionSplit || !hasType {
+		txType = "CREDIT"
+		if amount.Sign() < 0 {
+			txType = "DEBIT"
+		}
+		amount = amount.Abs()
+	}
+
+	return model.Transaction{
+		ID:              id,
+		Amount:          amount,
+		TransactionTime: txTime,
+		Type:            txType,
+	}, nil
+}
+
+// BankStatement parses a record of a bank statement file
+func (p *RecordParser) BankStatement(record []string) (model.BankStatement, error) {
+	id := p.field(record, model.FieldID)
+	if id == "" {
+		return model.BankStatement{}, errors.Wrap(errors.New("invalid record format"), "[parseBankStatement] error parsing bank statement record")
+	}
+
+	currency, err := parseCurrency(p.field(record, model.FieldCurrency))
+	if err != nil {
+		return model.BankStatement{}, errors.Wrap(err, "[parseBankStatement] error parsing currency")
+	}
+
+	amount, err := p.amount(record, currency)
+	if err != nil {
+		return model.BankStatement{}, errors.Wrap(err, "[parseBankStatement] error parsing amount")
+	}
+
+	date, err := p.parseTime(p.field(record, model.FieldDate))
+	if err != nil {
+		return model.BankStatement{}, errors.Wrap(err, "[parseBankStatement] error parsing date")
+	}
+
+	return model.BankStatement{
+		ID:        id,
+		Amount:    amount,
+		Date:      date,
+		Reference: p.field(record, model.FieldReference),
+	}, nil
+}
+
+// field returns the trimmed value of the field, empty when the format has no
+// column for it or the record is too short
+func (p *RecordParser) field(record []string, field string) string {
+	index, ok := p.columns[field]
+	if !ok || index < 0 || index >= len(record) {
+		return ""
+	}
+	return strings.TrimSpace(record[index])
+}
+
+// amount reads the signed amount of the record following the sign convention
+// of the format
+func (p *RecordParser) amount(record []string, currency string) (model.Money, error) {
+	switch p.format.SignConvention {
+	case model.SignConventionIndicator:
+		amount, err := p.parseAmount(p.field(record, model.FieldAmount), currency)
+		if err != nil {
+			return model.Money{}, err
+		}
+		amount = amount.Abs()
+		if p.debits[strings.ToLower(p.field(record, model.FieldIndicator))] {
+			amount = amount.Neg()
+		}
+		return amount, nil
+
+	case model.SignConventionSplit:
+		debit, credit := p.field(record, model.FieldDebit), p.field(record, model.FieldCredit)
+		if debit == "" && credit == "" {
+			return model.Money{}, errors.New("[amount] debit and credit are empty")
+		}
+		amount := model.NewMoney(0, currency)
+		if credit != "" {
+			value, err := p.parseAmount(credit, currency)
+			if err != nil {
+				return model.Money{}, err
+			}
+			amount = value.Abs()
+		}
+		if debit != "" {
+			value, err := p.parseAmount(debit, currency)
+			if err != nil {
+				return model.Money{}, err
+			}
+			if amount, err = amount.Add(value.Abs().Neg()); err != nil {
+				return model.Money{}, err
+			}
+		}
+		return amount, nil
+
+	default:
+		return p.parseAmount(p.field(record, model.FieldAmount), currency)
+	}
+}
+
+// parseAmount normalises the separators of value before parsing it. Negative
+// amounts may also be written with a trailing minus or in parentheses.
+func (p *RecordParser) parseAmount(value, currency string) (model.Money, error) {
+	normalised := strings.Join(strings.Fields(value), "")
+	if p.format.ThousandsSeparator != "" {
+		normalised = strings.ReplaceAll(normalised, p.format.ThousandsSeparator, "")
+	}
+	if p.format.DecimalSeparator != "" && p.format.DecimalSeparator != "." {
+		normalised = strings.ReplaceAll(normalised, p.format.DecimalSeparator, ".")
+	}
+
+	switch {
+	case strings.HasPrefix(normalised, "(") && strings.HasSuffix(normalised, ")"):
+		normalised = "-" + normalised[1:len(normalised)-1]
+	case len(normalised) > 1 && strings.HasSuffix(normalised, "-"):
+		normalised = "-" + normalised[:len(normalised)-1]
+	}
+
+	return model.ParseMoney(normalised, currency)
+}
+
+// parseTime parses value with the first date layout of the format it matches
+func (p *RecordParser) parseTime(value string) (time.Time, error) {
+	var err error
+	for _, layout := range p.format.DateLayouts {
+		var parsed time.Time
+		if parsed, err = time.Parse(layout, value); err == nil {
+			return parsed, nil
+		}
+	}
+	if err == nil {
+		err = errors.New("no date layout")
+	}
+	return time.Time{}, errors.Wrapf(err, "[parseTime] %q matches none of the date layouts", value)
+}
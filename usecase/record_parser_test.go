@@ -0,0 +1,315 @@
+package usecase_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aferryc/yars/model"
+	"github.com/aferryc/yars/usecase"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordParser_BankStatement(t *testing.T) {
+	tests := []struct {
+		name          string
+		format        model.CSVFormat
+		header        []string
+		record        []string
+		expected      model.BankStatement
+		expectedError bool
+	}{
+		{
+			name: "Columns by header name",
+			format: model.CSVFormat{
+				Columns: map[string]model.Column{
+					model.FieldID:        {Name: "Transaction ID"},
+					model.FieldAmount:    {Name: "amount"},
+					model.FieldDate:      {Name: "Value Date"},
+					model.FieldReference: {Name: "Description"},
+				},
+				DateLayouts: []string{"02/01/2006"},
+			},
+			header: []string{"\ufeffValue Date", " AMOUNT ", "Description", "Transaction ID"},
+			record: []string{"15/01/2023", "-42.10", "PAY tx123", "bs-1"},
+			expected: model.BankStatement{
+				ID:        "bs-1",
+				Amount:    money("-42.10"),
+				Date:      time.Date(2023, 1, 15, 0, 0, 0, 0, time.UTC),
+				Reference: "PAY tx123",
+			},
+		},
+		{
+			name: "Decimal comma and thousands separator",
+			format: model.CSVFormat{
+				Columns: map[string]model.Column{
+					model.FieldID:     model.ColumnIndex(0),
+					model.FieldAmount: model.ColumnIndex(1),
+					model.FieldDate:   model.ColumnIndex(2),
+				},
+				DateLayouts:        []string{"02.01.2006"},
+				DecimalSeparator:   ",",
+				ThousandsSeparator: ".",
+			},
+			record: []string{"bs-2", "1.234.567,89-", "15.01.2023"},
+			expected: model.BankStatement{
+				ID:     "bs-2",
+				Amount: money("-1234567.89"),
+				Date:   time.Date(2023, 1, 15, 0, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			name: "Negative amount in parentheses",
+			format: model.CSVFormat{
+				Columns: map[string]model.Column{
+					model.FieldID:     model.ColumnIndex(0),
+					model.FieldAmount: model.ColumnIndex(1),
+					model.FieldDate:   model.ColumnIndex(2),
+				},
+				DateLayouts:        []string{"2006-01-02"},
+				ThousandsSeparator: ",",
+			},
+			record: []string{"bs-3", "(1,000.00)", "2023-01-15"},
+			expected: model.BankStatement{
+				ID:     "bs-3",
+				Amount: money("-1000.00"),
+				Date:   time.Date(2023, 1, 15, 0, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			name: "Debit indicator",
+			format: model.CSVFormat{
+				Columns: map[string]model.Column{
+					model.FieldID:        model.ColumnIndex(0),
+					model.FieldAmount:    model.ColumnIndex(1),
+					model.FieldIndicator: model.ColumnIndex(2),
+					model.FieldDate:      model.ColumnIndex(3),
+				},
+				DateLayouts:     []string{"2006-01-02"},
+				SignConvention:  model.SignConventionIndicator,
+				DebitIndicators: []string{"D", "Debit"},
+			},
+			record: []string{"bs-4", "250.00", " debit ", "2023-01-15"},
+			expected: model.BankStatement{
+				ID:     "bs-4",
+				Amount: money("-250.00"),
+				Date:   time.Date(2023, 1, 15, 0, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			name: "Separate debit and credit columns",
+			format: model.CSVFormat{
+				Columns: map[string]model.Column{
+					model.FieldID:     model.ColumnIndex(0),
+					model.FieldDebit:  model.ColumnIndex(1),
+					model.FieldCredit: model.ColumnIndex(2),
+					model.FieldDate:   model.ColumnIndex(3),
+				},
+				DateLayouts:    []string{"2006-01-02"},
+				SignConvention: model.SignConventionSplit,
+			},
+			record: []string{"bs-5", "", "80.00", "2023-01-15"},
+			expected: model.BankStatement{
+				ID:     "bs-5",
+				Amount: money("80.00"),
+				Date:   time.Date(2023, 1, 15, 0, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			name: "Empty debit and credit",
+			format: model.CSVFormat{
+				Columns: map[string]model.Column{
+					model.FieldID:     model.ColumnIndex(0),
+					model.FieldDebit:  model.ColumnIndex(1),
+					model.FieldCredit: model.ColumnIndex(2),
+					model.FieldDate:   model.ColumnIndex(3),
+				},
+				DateLayouts:    []string{"2006-01-02"},
+				SignConvention: model.SignConventionSplit,
+			},
+			record:        []string{"bs-6", "", "", "2023-01-15"},
+			expectedError: true,
+		},
+		{
+			name: "Date matching none of the layouts",
+			format: model.CSVFormat{
+				Columns: map[string]model.Column{
+					model.FieldID:     model.ColumnIndex(0),
+					model.FieldAmount: model.ColumnIndex(1),
+					model.FieldDate:   model.ColumnIndex(2),
+				},
+				DateLayouts: []string{"2006-01-02", "02/01/2006"},
+			},
+			record:        []string{"bs-7", "10.00", "Jan 15 2023"},
+			expectedError: true,
+		},
+		{
+			name: "Thousands separator without a profile",
+			format: model.CSVFormat{
+				Columns: map[string]model.Column{
+					model.FieldID:     model.ColumnIndex(0),
+					model.FieldAmount: model.ColumnIndex(1),
+					model.FieldDate:   model.ColumnIndex(2),
+				},
+				DateLayouts: []string{"2006-01-02"},
+			},
+			record:        []string{"bs-8", "1,000.00", "2023-01-15"},
+			expectedError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Setup
+			parser, err := usecase.NewRecordParser(tt.format, tt.header)
+			require.NoError(t, err)
+
+			// Execute
+			stmt, err := parser.BankStatement(tt.record)
+
+			// Assert
+			if tt.expectedError {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, stmt)
+		})
+	}
+}
+
+func TestRecordParser_Transaction(t *testing.T) {
+	txTime := time.Date(2023, 1, 15, 10, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		format   model.CSVFormat
+		record   []string
+		expected model.Transaction
+	}{
+		{
+			name:   "Type read from its column",
+			format: model.DefaultTransactionFormat(),
+			record: []string{"tx-1", "100.00", "DEBIT", "2023-01-15T10:00:00Z"},
+			expected: model.Transaction{
+				ID: "tx-1", Amount: money("100.00"), Type: "DEBIT", TransactionTime: txTime,
+			},
+		},
+		{
+			name: "Debit indicator",
+			format: model.CSVFormat{
+				Columns: map[string]model.Column{
+					model.FieldID:              model.ColumnIndex(0),
+					model.FieldAmount:          model.ColumnIndex(1),
+					model.FieldIndicator:       model.ColumnIndex(2),
+					model.FieldTransactionTime: model.ColumnIndex(3),
+				},
+				DateLayouts:     []string{time.RFC3339},
+				SignConvention:  model.SignConventionIndicator,
+				DebitIndicators: []string{"D"},
+			},
+			record: []string{"tx-2", "100.00", "D", "2023-01-15T10:00:00Z"},
+			expected: model.Transaction{
+				ID: "tx-2", Amount: money("100.00"), Type: "DEBIT", TransactionTime: txTime,
+			},
+		},
+		{
+			name: "Separate debit and credit columns",
+			format: model.CSVFormat{
+				Columns: map[string]model.Column{
+					model.FieldID:              model.ColumnIndex(0),
+					model.FieldDebit:           model.ColumnIndex(1),
+					model.FieldCredit:          model.ColumnIndex(2),
+					model.FieldType:            model.ColumnIndex(3),
+					model.FieldTransactionTime: model.ColumnIndex(4),
+				},
+				DateLayouts:    []string{time.RFC3339},
+				SignConvention: model.SignConventionSplit,
+			},
+			// The sign of the columns wins over a type column
+			record: []string{"tx-3", "", "40.00", "DEBIT", "2023-01-15T10:00:00Z"},
+			expected: model.Transaction{
+				ID: "tx-3", Amount: money("40.00"), Type: "CREDIT", TransactionTime: txTime,
+			},
+		},
+		{
+			name: "Signed amount without a type column",
+			format: model.CSVFormat{
+				Columns: map[string]model.Column{
+					model.FieldID:              model.ColumnIndex(0),
+					model.FieldAmount:          model.ColumnIndex(1),
+					model.FieldTransactionTime: model.ColumnIndex(2),
+				},
+				DateLayouts: []string{time.RFC3339},
+			},
+			record: []string{"tx-4", "-75.00", "2023-01-15T10:00:00Z"},
+			expected: model.Transaction{
+				ID: "tx-4", Amount: money("75.00"), Type: "DEBIT", TransactionTime: txTime,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Setup
+			parser, err := usecase.NewRecordParser(tt.format, nil)
+			require.NoError(t, err)
+
+			// Execute
+			tx, err := parser.Transaction(tt.record)
+
+			// Assert
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, tx)
+		})
+	}
+
+	t.Run("Debit read through a profile matches the bank debit", func(t *testing.T) {
+		// Setup
+		format := model.CSVFormat{
+			Columns: map[string]model.Column{
+				model.FieldID:              {Name: "Ref"},
+				model.FieldDebit:           {Name: "Debit"},
+				model.FieldCredit:          {Name: "Credit"},
+				model.FieldTransactionTime: {Name: "Booked"},
+			},
+			DateLayouts:    []string{time.RFC3339},
+			SignConvention: model.SignConventionSplit,
+		}
+		parser, err := usecase.NewRecordParser(format, []string{"Ref", "Debit", "Credit", "Booked"})
+		require.NoError(t, err)
+		tx, err := parser.Transaction([]string{"tx-5", "250.00", "", "2023-01-15T10:00:00Z"})
+		require.NoError(t, err)
+
+		bankParser, err := usecase.NewRecordParser(model.DefaultBankStatementFormat(), nil)
+		require.NoError(t, err)
+		stmt, err := bankParser.BankStatement([]string{"bs-5", "-250.00", "2023-01-15"})
+		require.NoError(t, err)
+
+		// Execute
+		result := usecase.NewAmountDateMatcher(1).Match([]model.Transaction{tx}, []model.BankStatement{stmt})
+
+		// Assert
+		assert.Equal(t, money("-250.00"), tx.SignedAmount())
+		assert.Len(t, result.Matches, 1)
+		assert.Empty(t, result.UnmatchedInternal)
+		assert.Empty(t, result.UnmatchedBank)
+	})
+}
+
+func TestNewRecordParser(t *testing.T) {
+	t.Run("Column missing from the header", func(t *testing.T) {
+		// Setup
+		format := model.CSVFormat{
+			Columns: map[string]model.Column{
+				model.FieldID: {Name: "Reference"},
+			},
+		}
+
+		// Execute
+		parser, err := usecase.NewRecordParser(format, []string{"id", "amount"})
+
+		// Assert
+		assert.ErrorIs(t, err, model.ErrInvalidIngestionProfile)
+		assert.Nil(t, parser)
+	})
+}
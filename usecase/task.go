@@ -0,0 +1,20 @@
+package usecase
+
+import (
+	"context"
+	"log"
+
+	"github.com/aferryc/yars/model"
+	"github.com/aferryc/yars/repository"
+)
+
+// markTaskFailed records why a task failed. It runs on paths that already
+// return an error, so a failure to record it is only logged.
+func markTaskFailed(ctx context.Context, taskRepo repository.ReconTaskRepository, taskID string, cause error) {
+	if taskID == "" {
+		return
+	}
+	if err := taskRepo.UpdateStatus(ctx, taskID, model.TaskFailed, cause.Error()); err != nil {
+		log.Printf("Error marking task %s as failed: %v", taskID, err)
+	}
+}